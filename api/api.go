@@ -5,12 +5,16 @@ import (
 	"blocker/crypto"
 	"blocker/pool"
 	"blocker/types"
+	"bytes"
 	"crypto/sha256"
 	"encoding/gob"
 	"encoding/hex"
 	"fmt"
 	"net/http"
+	"sort"
 	"strconv"
+	"strings"
+	"time"
 
 	"github.com/labstack/echo/v4"
 )
@@ -24,6 +28,40 @@ func (s *Server) GetHeightHandler(c echo.Context) error {
 	return c.JSON(http.StatusOK, echo.Map{"height": int(height)})
 }
 
+func (s *Server) GetChainInfoHandler(c echo.Context) error {
+	finalized := s.chain.Finalized()
+	return c.JSON(http.StatusOK, echo.Map{
+		"chain_id":         s.chain.ChainID(),
+		"genesis_hash":     s.chain.GenesisHash().String(),
+		"height":           int(s.chain.Height()),
+		"finalized_height": int(finalized.Height),
+		"finalized_hash":   finalized.Hash.String(),
+		"confirm_depth":    int(s.chain.Params().ConfirmDepth),
+	})
+}
+
+// GetBaseFeeHandler returns the base fee of the next block, max fee of new transactions must cover it
+func (s *Server) GetBaseFeeHandler(c echo.Context) error {
+	return c.JSON(http.StatusOK, echo.Map{
+		"base_fee": s.chain.NextBaseFee(),
+		"height":   int(s.chain.Height()) + 1,
+	})
+}
+
+// GetSupplyHandler returns the issued, burned and circulating coins, and the reward of the next block
+func (s *Server) GetSupplyHandler(c echo.Context) error {
+	supply := s.chain.Supply()
+	nextHeight := s.chain.Height() + 1
+	return c.JSON(http.StatusOK, echo.Map{
+		"issued":           supply.Issued,
+		"burned":           supply.Burned,
+		"circulating":      supply.Circulating(),
+		"coinbase_reserve": s.chain.CoinbaseReserve(),
+		"block_reward":     min(core.BlockReward(nextHeight, s.chain.Params()), s.chain.CoinbaseReserve()),
+		"height":           int(nextHeight),
+	})
+}
+
 type JSONBlock struct {
 	Hash          string
 	DataHash      string
@@ -33,6 +71,7 @@ type JSONBlock struct {
 	Txx           []string
 	Height        uint32
 	Version       uint32
+	BaseFee       uint64
 }
 
 func (s *Server) GetBlockWithHeightHandler(c echo.Context) error {
@@ -84,6 +123,7 @@ func toJSONBlock(block *core.Block) (JSONBlock, error) {
 		Txx:           txx,
 		Height:        block.Height,
 		Version:       block.Version,
+		BaseFee:       block.BaseFee,
 	}, nil
 }
 
@@ -102,6 +142,61 @@ type TransactionJSON struct {
 	BlockID string         `json:"block"`
 	Status  string         `json:"status"`
 	Type    string         `json:"tx_type"`
+	Receipt *ReceiptJSON   `json:"receipt,omitempty"`
+}
+
+type LogJSON struct {
+	Key   string `json:"key"`
+	Value string `json:"value"`
+}
+
+type StateChangeJSON struct {
+	Kind  string `json:"kind"`
+	Addr  string `json:"addr"`
+	Delta int64  `json:"delta,omitempty"`
+	Hash  string `json:"hash,omitempty"`
+}
+
+type ReceiptJSON struct {
+	Status       string            `json:"status"`
+	Error        string            `json:"error,omitempty"`
+	FeeCharged   uint64            `json:"fee_charged"`
+	FeeBurned    uint64            `json:"fee_burned"`
+	GasUsed      uint64            `json:"gas_used"`
+	Logs         []LogJSON         `json:"logs"`
+	StateChanges []StateChangeJSON `json:"state_changes"`
+	Height       uint32            `json:"height"`
+	Index        uint32            `json:"index"`
+}
+
+func toReceiptJSON(receipt *core.Receipt) *ReceiptJSON {
+	logs := []LogJSON{}
+	for _, l := range receipt.Logs {
+		logs = append(logs, LogJSON{Key: l.Key, Value: hex.EncodeToString(l.Value)})
+	}
+	changes := []StateChangeJSON{}
+	for _, change := range receipt.StateChanges {
+		changeJSON := StateChangeJSON{
+			Kind:  string(change.Kind),
+			Addr:  change.Addr.String(),
+			Delta: change.Delta,
+		}
+		if !change.Hash.IsZero() {
+			changeJSON.Hash = change.Hash.String()
+		}
+		changes = append(changes, changeJSON)
+	}
+	return &ReceiptJSON{
+		Status:       string(receipt.Status),
+		Error:        receipt.Err,
+		FeeCharged:   receipt.FeeCharged,
+		FeeBurned:    receipt.FeeBurned,
+		GasUsed:      receipt.GasUsed,
+		Logs:         logs,
+		StateChanges: changes,
+		Height:       receipt.Height,
+		Index:        receipt.Index,
+	}
 }
 
 // TransactionData returns transaction type and relevant data of that transaction type
@@ -113,20 +208,213 @@ func TransactionData(tx *core.Transaction) (string, map[string]any) {
 		switch nft := ttx.NFT.(type) {
 		case core.NFTAsset:
 			data = map[string]any{
-				"hash":     ttx.Hash(core.TxMintHasher{}).String(),
-				"metadata": ttx.Metadata,
-				"nft_data": nft.Data,
-				"nft_type": nft.Type,
+				"hash":              ttx.Hash(core.TxMintHasher{}).String(),
+				"metadata":          ttx.Metadata,
+				"nft_data":          nft.Data,
+				"nft_type":          nft.Type,
+				"collection":        nft.Collection.String(),
+				"royalty_bps":       nft.Royalty.Bps,
+				"royalty_recipient": nft.Royalty.Recipient.String(),
 			}
 			txType = string(core.TxTypeMint)
 		case core.NFTCollection:
+			minters := []string{}
+			for _, minter := range nft.Minters {
+				minters = append(minters, minter.String())
+			}
 			data = map[string]any{
-				"hash":            ttx.Hash(core.TxMintHasher{}).String(),
-				"metadata":        ttx.Metadata,
-				"collection_type": nft.Type,
+				"hash":              ttx.Hash(core.TxMintHasher{}).String(),
+				"metadata":          ttx.Metadata,
+				"collection_type":   nft.Type,
+				"max_supply":        nft.MaxSupply,
+				"minters":           minters,
+				"mutable":           nft.Mutable,
+				"royalty_bps":       nft.Royalty.Bps,
+				"royalty_recipient": nft.Royalty.Recipient.String(),
 			}
 			txType = string(core.TxTypeMint)
 		}
+	case core.MultisigCreateTx:
+		signers := []string{}
+		for _, signer := range ttx.Account.Signers {
+			signers = append(signers, signer.Address().String())
+		}
+		data = map[string]any{
+			"addr":      ttx.Account.Address().String(),
+			"threshold": ttx.Account.Threshold,
+			"signers":   signers,
+		}
+		txType = string(core.TxTypeMultisig)
+	case core.NFTTransferTx:
+		data = map[string]any{
+			"nft":  ttx.NFT.String(),
+			"from": tx.Sender().String(),
+			"to":   ttx.To.String(),
+		}
+		txType = string(core.TxTypeNFTTransfer)
+	case core.NFTBurnTx:
+		data = map[string]any{
+			"nft":  ttx.NFT.String(),
+			"from": tx.Sender().String(),
+		}
+		txType = string(core.TxTypeNFTBurn)
+	case core.NFTMetadataUpdateTx:
+		data = map[string]any{
+			"nft":      ttx.NFT.String(),
+			"metadata": ttx.Metadata,
+		}
+		txType = string(core.TxTypeNFTUpdate)
+	case core.CollectionFreezeTx:
+		data = map[string]any{
+			"collection": ttx.Collection.String(),
+		}
+		txType = string(core.TxTypeNFTFreeze)
+	case core.NFTSaleTx:
+		data = map[string]any{
+			"nft":       ttx.NFT.String(),
+			"buyer":     ttx.Buyer.String(),
+			"price":     ttx.Price,
+			"transfers": ttx.Transfers,
+		}
+		if ttx.Seller != nil {
+			data["seller"] = ttx.Seller.Address().String()
+		}
+		txType = string(core.TxTypeNFTSale)
+	case core.NFTListTx:
+		data = map[string]any{
+			"nft":    ttx.NFT.String(),
+			"seller": tx.Sender().String(),
+			"price":  ttx.Price,
+		}
+		txType = string(core.TxTypeNFTList)
+	case core.NFTCancelListingTx:
+		data = map[string]any{
+			"nft":    ttx.NFT.String(),
+			"seller": tx.Sender().String(),
+		}
+		txType = string(core.TxTypeNFTUnlist)
+	case core.NFTBuyTx:
+		data = map[string]any{
+			"nft":   ttx.NFT.String(),
+			"buyer": tx.Sender().String(),
+			"price": ttx.Price,
+		}
+		txType = string(core.TxTypeNFTBuy)
+	case core.NFTOfferTx:
+		data = map[string]any{
+			"nft":   ttx.NFT.String(),
+			"buyer": tx.Sender().String(),
+			"price": ttx.Price,
+		}
+		txType = string(core.TxTypeNFTOffer)
+	case core.NFTAcceptOfferTx:
+		data = map[string]any{
+			"nft":    ttx.NFT.String(),
+			"seller": tx.Sender().String(),
+			"buyer":  ttx.Buyer.String(),
+			"price":  ttx.Price,
+		}
+		txType = string(core.TxTypeNFTAccept)
+	case core.AuctionCreateTx:
+		data = map[string]any{
+			"hash":          tx.Hash(core.TxHasher{}).String(),
+			"nft":           ttx.NFT.String(),
+			"seller":        tx.Sender().String(),
+			"reserve_price": ttx.ReservePrice,
+			"end_height":    ttx.EndHeight,
+		}
+		txType = string(core.TxTypeAuctionCreate)
+	case core.AuctionBidTx:
+		data = map[string]any{
+			"auction": ttx.Auction.String(),
+			"bidder":  tx.Sender().String(),
+			"amount":  ttx.Amount,
+		}
+		txType = string(core.TxTypeAuctionBid)
+	case core.AuctionSettleTx:
+		data = map[string]any{
+			"auction": ttx.Auction.String(),
+		}
+		txType = string(core.TxTypeAuctionSettle)
+	case core.TokenCreateTx:
+		data = map[string]any{
+			"name":           ttx.Name,
+			"symbol":         ttx.Symbol,
+			"decimals":       ttx.Decimals,
+			"supply":         ttx.Supply,
+			"mint_authority": ttx.MintAuthority.String(),
+		}
+		txType = string(core.TxTypeTokenCreate)
+	case core.TokenTransferTx:
+		data = map[string]any{
+			"token":  ttx.Token.String(),
+			"to":     ttx.To.String(),
+			"amount": ttx.Amount,
+		}
+		txType = string(core.TxTypeTokenTransfer)
+	case core.TokenMintTx:
+		data = map[string]any{
+			"token":  ttx.Token.String(),
+			"to":     ttx.To.String(),
+			"amount": ttx.Amount,
+		}
+		txType = string(core.TxTypeTokenMint)
+	case core.TokenBurnTx:
+		data = map[string]any{
+			"token":  ttx.Token.String(),
+			"amount": ttx.Amount,
+		}
+		txType = string(core.TxTypeTokenBurn)
+	case core.BatchTransferTx:
+		outputs := []map[string]any{}
+		for _, output := range ttx.Outputs {
+			outputs = append(outputs, map[string]any{"to": output.To.String(), "value": output.Value})
+		}
+		data = map[string]any{
+			"from":    tx.Sender().String(),
+			"outputs": outputs,
+			"total":   ttx.Total(),
+		}
+		txType = string(core.TxTypeBatch)
+	case core.VestingTransferTx:
+		data = map[string]any{
+			"to":             ttx.To.String(),
+			"value":          ttx.Value,
+			"unlock_height":  ttx.UnlockHeight,
+			"unlock_time":    ttx.UnlockTime,
+			"vesting_blocks": ttx.VestingBlocks,
+		}
+		txType = string(core.TxTypeVesting)
+	case core.VestingClaimTx:
+		vestings := []string{}
+		for _, hash := range ttx.Vestings {
+			vestings = append(vestings, hash.String())
+		}
+		data = map[string]any{
+			"vestings": vestings,
+		}
+		txType = string(core.TxTypeClaim)
+	case core.HTLCLockTx:
+		data = map[string]any{
+			"hash":           tx.Hash(core.TxHasher{}).String(),
+			"from":           tx.Sender().String(),
+			"to":             ttx.To.String(),
+			"value":          ttx.Value,
+			"hashlock":       ttx.Hashlock.String(),
+			"timeout_height": ttx.TimeoutHeight,
+		}
+		txType = string(core.TxTypeHTLCLock)
+	case core.HTLCClaimTx:
+		data = map[string]any{
+			"htlc":     ttx.HTLC.String(),
+			"preimage": hex.EncodeToString(ttx.Preimage),
+		}
+		txType = string(core.TxTypeHTLCClaim)
+	case core.HTLCRefundTx:
+		data = map[string]any{
+			"htlc": ttx.HTLC.String(),
+		}
+		txType = string(core.TxTypeHTLCRefund)
 	default:
 		dataHash := sha256.Sum256(tx.Data)
 		data = map[string]any{
@@ -170,29 +458,34 @@ func (s *Server) GetTransactionWithHashHandler(c echo.Context) error {
 	if err != nil {
 		return c.JSON(http.StatusBadRequest, echo.Map{"errors": fmt.Sprintf("cannot decode hash given hahs, (%s)", err.Error())})
 	}
-	poolStatus, tx, err := s.TxPool.Get(types.HashFromBytes(hashBytes))
-	if err != nil {
-		return c.String(http.StatusNotFound, fmt.Sprintf("cannot get transaction informatin: %s", err.Error()))
-	}
-	switch poolStatus {
-	case pool.TxPoolReceived:
-		status, b, tx, err := s.chain.GetTransaction(types.HashFromBytes(hashBytes))
-		if err != nil {
-			return c.String(http.StatusNotFound, fmt.Sprintf("cannot get transaction information: (%s)", err.Error()))
-		}
+	hash := types.HashFromBytes(hashBytes)
 
+	// transactions in the chain are included, confirmed or finalized
+	if status, b, tx, err := s.chain.GetTransaction(hash); err == nil {
 		jsonTx, err := TransactionJSONWithStatus(status, tx, b)
 		if err != nil {
 			return c.String(http.StatusInternalServerError, fmt.Sprintf("cannot get transaction information: (%s)", err.Error()))
 		}
-		return c.JSON(http.StatusOK, jsonTx)
-	default:
-		jsonTx, err := TransactionJSONFromPoolWithStatus(poolStatus, tx)
-		if err != nil {
-			return c.String(http.StatusInternalServerError, fmt.Sprintf("cannot get transaction information: %s", err.Error()))
+		if receipt, err := s.chain.GetReceipt(hash); err == nil {
+			jsonTx.Receipt = toReceiptJSON(receipt)
 		}
 		return c.JSON(http.StatusOK, jsonTx)
 	}
+
+	poolStatus, tx, err := s.TxPool.Get(hash)
+	if err != nil || poolStatus == pool.TxPoolUnknown {
+		return c.String(http.StatusNotFound, "cannot get transaction information: transaction not found")
+	}
+	var jsonTx *TransactionJSON
+	if poolStatus == pool.TxPoolReceived {
+		jsonTx, err = TransactionJSONFromPoolWithStatus(pool.TxPoolStatus(core.StatusPending), tx)
+	} else {
+		jsonTx, err = TransactionJSONFromPoolWithStatus(poolStatus, tx)
+	}
+	if err != nil {
+		return c.String(http.StatusInternalServerError, fmt.Sprintf("cannot get transaction information: %s", err.Error()))
+	}
+	return c.JSON(http.StatusOK, jsonTx)
 }
 
 func (s *Server) CheckTransactionStatus(c echo.Context) error {
@@ -265,6 +558,7 @@ func (s *Server) GetAccountStateSummaryHandler(c echo.Context) error {
 			"state": echo.Map{
 				"addr":    state.Addr.String(),
 				"balance": state.Balance,
+				"locked":  state.Locked,
 				"nonce":   state.Nonce,
 			},
 			"outcomeTransactions": fromTXXString,
@@ -272,6 +566,34 @@ func (s *Server) GetAccountStateSummaryHandler(c echo.Context) error {
 		})
 }
 
+// GetAccountNonceHandler returns the nonce the next transaction of the address must use,
+// pending transactions in the mempool are counted.
+func (s *Server) GetAccountNonceHandler(c echo.Context) error {
+	addrString := c.Param("hash")
+	if addrString == "" {
+		return c.JSON(http.StatusBadRequest, echo.Map{"errors": "invalid hash"})
+	}
+	addrBytes, err := hex.DecodeString(addrString)
+	if err != nil || len(addrBytes) != len(types.Address{}) {
+		return c.JSON(http.StatusBadRequest, echo.Map{"errors": "cannot decode given address"})
+	}
+	addr := types.AddressFromBytes(addrBytes)
+	state, err := s.chain.GetAccountState(addr)
+	if err != nil {
+		return c.String(http.StatusNotFound, fmt.Sprintf("cannot get account state from blockchain: %s", err.Error()))
+	}
+
+	nonce := state.Nonce
+	if s.TxPool != nil {
+		nonce = s.TxPool.PendingNonce(addr, nonce)
+	}
+	return c.JSON(http.StatusOK, echo.Map{
+		"addr":       addr.String(),
+		"nonce":      state.Nonce,
+		"next_nonce": nonce + 1,
+	})
+}
+
 func (s *Server) GetAccountStateHandler(c echo.Context) error {
 	addrString := c.Param("hash")
 
@@ -292,6 +614,728 @@ func (s *Server) GetAccountStateHandler(c echo.Context) error {
 		http.StatusOK, echo.Map{
 			"addr":    state.Addr.String(),
 			"balance": state.Balance,
+			"locked":  state.Locked,
 			"nonce":   state.Nonce,
 		})
 }
+
+type VestingJSON struct {
+	Hash          string `json:"hash"`
+	Total         uint64 `json:"total"`
+	Claimed       uint64 `json:"claimed"`
+	Vested        uint64 `json:"vested"`
+	UnlockHeight  uint32 `json:"unlock_height"`
+	UnlockTime    int64  `json:"unlock_time"`
+	VestingBlocks uint32 `json:"vesting_blocks"`
+	StartHeight   uint32 `json:"start_height"`
+}
+
+// GetAccountVestingsHandler returns the vestings of coins locked for the address, vested is counted at the next block
+func (s *Server) GetAccountVestingsHandler(c echo.Context) error {
+	addrBytes, err := hex.DecodeString(c.Param("hash"))
+	if err != nil || len(addrBytes) != len(types.Address{}) {
+		return c.JSON(http.StatusBadRequest, echo.Map{"errors": "cannot decode given address"})
+	}
+	vestings, err := s.chain.GetVestingsOfAccount(types.AddressFromBytes(addrBytes))
+	if err != nil {
+		return c.JSON(http.StatusNotFound, echo.Map{"error": err.Error()})
+	}
+	height := s.chain.Height() + 1
+	now := time.Now().UnixNano()
+	vestingsJSON := []VestingJSON{}
+	for _, vesting := range vestings {
+		vestingsJSON = append(vestingsJSON, VestingJSON{
+			Hash:          vesting.Hash.String(),
+			Total:         vesting.Total,
+			Claimed:       vesting.Claimed,
+			Vested:        vesting.Vested(height, now),
+			UnlockHeight:  vesting.UnlockHeight,
+			UnlockTime:    vesting.UnlockTime,
+			VestingBlocks: vesting.VestingBlocks,
+			StartHeight:   vesting.StartHeight,
+		})
+	}
+	return c.JSON(http.StatusOK, echo.Map{"vestings": vestingsJSON})
+}
+
+type HTLCJSON struct {
+	Hash          string `json:"hash"`
+	Sender        string `json:"sender"`
+	Recipient     string `json:"recipient"`
+	Value         uint64 `json:"value"`
+	Hashlock      string `json:"hashlock"`
+	TimeoutHeight uint32 `json:"timeout_height"`
+	Preimage      string `json:"preimage,omitempty"`
+	Expired       bool   `json:"expired"`
+	Claimed       bool   `json:"claimed"`
+	Refunded      bool   `json:"refunded"`
+}
+
+func (s *Server) toHTLCJSON(htlc *core.HTLC) HTLCJSON {
+	return HTLCJSON{
+		Hash:          htlc.Hash.String(),
+		Sender:        htlc.Sender.String(),
+		Recipient:     htlc.Recipient.String(),
+		Value:         htlc.Value,
+		Hashlock:      htlc.Hashlock.String(),
+		TimeoutHeight: htlc.TimeoutHeight,
+		Preimage:      hex.EncodeToString(htlc.Preimage),
+		Expired:       htlc.Expired(s.chain.Height() + 1),
+		Claimed:       htlc.Claimed,
+		Refunded:      htlc.Refunded,
+	}
+}
+
+// GetHTLCHandler returns the htlc with the preimage revealed by its claim, htlc is the hash of its lock transaction
+func (s *Server) GetHTLCHandler(c echo.Context) error {
+	hashBytes, err := hex.DecodeString(c.Param("hash"))
+	if err != nil || len(hashBytes) != len(types.Hash{}) {
+		return c.JSON(http.StatusBadRequest, echo.Map{"errors": "cannot decode given hash"})
+	}
+	htlc, err := s.chain.GetHTLC(types.HashFromBytes(hashBytes))
+	if err != nil {
+		return c.JSON(http.StatusNotFound, echo.Map{"error": err.Error()})
+	}
+	return c.JSON(http.StatusOK, s.toHTLCJSON(htlc))
+}
+
+// GetAccountHTLCsHandler returns the htlcs sent or received by the address, expired is counted at the next block
+func (s *Server) GetAccountHTLCsHandler(c echo.Context) error {
+	addrBytes, err := hex.DecodeString(c.Param("hash"))
+	if err != nil || len(addrBytes) != len(types.Address{}) {
+		return c.JSON(http.StatusBadRequest, echo.Map{"errors": "cannot decode given address"})
+	}
+	htlcs, err := s.chain.GetHTLCsOfAccount(types.AddressFromBytes(addrBytes))
+	if err != nil {
+		return c.JSON(http.StatusNotFound, echo.Map{"error": err.Error()})
+	}
+	htlcsJSON := []HTLCJSON{}
+	for _, htlc := range htlcs {
+		htlcsJSON = append(htlcsJSON, s.toHTLCJSON(htlc))
+	}
+	return c.JSON(http.StatusOK, echo.Map{"htlcs": htlcsJSON})
+}
+
+// GetMultisigHandler returns the threshold and signers of the multisig account registered at the address
+func (s *Server) GetMultisigHandler(c echo.Context) error {
+	addrBytes, err := hex.DecodeString(c.Param("hash"))
+	if err != nil || len(addrBytes) != len(types.Address{}) {
+		return c.JSON(http.StatusBadRequest, echo.Map{"errors": "cannot decode given address"})
+	}
+	account, err := s.chain.GetMultisig(types.AddressFromBytes(addrBytes))
+	if err != nil {
+		return c.JSON(http.StatusNotFound, echo.Map{"error": err.Error()})
+	}
+	signers := []string{}
+	for _, signer := range account.Signers {
+		signers = append(signers, hex.EncodeToString(signer.Bytes()))
+	}
+	return c.JSON(http.StatusOK, echo.Map{
+		"addr":      account.Address().String(),
+		"threshold": account.Threshold,
+		"signers":   signers,
+	})
+}
+
+type NFTEventJSON struct {
+	Kind     string `json:"kind"`
+	TxHash   string `json:"tx_hash"`
+	From     string `json:"from,omitempty"`
+	To       string `json:"to,omitempty"`
+	Height   uint32 `json:"height"`
+	Metadata []byte `json:"metadata,omitempty"`
+	Price    uint64 `json:"price,omitempty"`
+}
+
+func toNFTEventJSON(event *core.NFTEvent) NFTEventJSON {
+	eventJSON := NFTEventJSON{
+		Kind:     string(event.Kind),
+		TxHash:   event.TxHash.String(),
+		Height:   event.Height,
+		Metadata: event.Metadata,
+		Price:    event.Price,
+	}
+	if !event.From.IsZero() {
+		eventJSON.From = event.From.String()
+	}
+	if !event.To.IsZero() {
+		eventJSON.To = event.To.String()
+	}
+	return eventJSON
+}
+
+// GetNFTOwnerHandler returns the current owner of the nft, nft is the hash of its mint transaction
+func (s *Server) GetNFTOwnerHandler(c echo.Context) error {
+	hashBytes, err := hex.DecodeString(c.Param("hash"))
+	if err != nil || len(hashBytes) != len(types.Hash{}) {
+		return c.JSON(http.StatusBadRequest, echo.Map{"errors": "cannot decode given hash"})
+	}
+	hash := types.HashFromBytes(hashBytes)
+	owner, err := s.chain.GetNFTOwner(hash)
+	if err != nil {
+		return c.JSON(http.StatusNotFound, echo.Map{"error": err.Error()})
+	}
+	return c.JSON(http.StatusOK, echo.Map{
+		"nft":   hash.String(),
+		"owner": owner.String(),
+	})
+}
+
+// GetNFTHistoryHandler returns the mint, every transfer and metadata update and the burn of the nft, in chain order
+func (s *Server) GetNFTHistoryHandler(c echo.Context) error {
+	hashBytes, err := hex.DecodeString(c.Param("hash"))
+	if err != nil || len(hashBytes) != len(types.Hash{}) {
+		return c.JSON(http.StatusBadRequest, echo.Map{"errors": "cannot decode given hash"})
+	}
+	history, err := s.chain.GetNFTHistory(types.HashFromBytes(hashBytes))
+	if err != nil {
+		return c.JSON(http.StatusNotFound, echo.Map{"error": err.Error()})
+	}
+	events := []NFTEventJSON{}
+	for _, event := range history {
+		events = append(events, toNFTEventJSON(event))
+	}
+	return c.JSON(http.StatusOK, echo.Map{"history": events})
+}
+
+// decodeMetadata decodes the gob encoded metadata the wallet mints with, metadata in any other
+// encoding is returned as is
+func decodeMetadata(metadata []byte) any {
+	if len(metadata) == 0 {
+		return nil
+	}
+	decoded := map[string]any{}
+	if err := gob.NewDecoder(bytes.NewReader(metadata)).Decode(&decoded); err != nil {
+		return metadata
+	}
+	return decoded
+}
+
+func parseNFTQuery(c echo.Context) (core.NFTQuery, error) {
+	query := core.NFTQuery{Cursor: c.QueryParam("cursor")}
+	if limitParam := c.QueryParam("limit"); limitParam != "" {
+		limit, err := strconv.Atoi(limitParam)
+		if err != nil {
+			return query, err
+		}
+		query.Limit = limit
+	}
+	return query, nil
+}
+
+type RoyaltyJSON struct {
+	Bps       uint32 `json:"bps"`
+	Recipient string `json:"recipient"`
+}
+
+func toRoyaltyJSON(royalty core.Royalty) *RoyaltyJSON {
+	if royalty.Bps == 0 {
+		return nil
+	}
+	return &RoyaltyJSON{Bps: royalty.Bps, Recipient: royalty.Recipient.String()}
+}
+
+type NFTJSON struct {
+	Hash       string       `json:"hash"`
+	Collection string       `json:"collection,omitempty"`
+	TokenID    uint64       `json:"token_id"`
+	Owner      string       `json:"owner,omitempty"`
+	Burned     bool         `json:"burned"`
+	Type       string       `json:"nft_type"`
+	Data       []byte       `json:"nft_data"`
+	Metadata   any          `json:"metadata"`
+	Royalty    *RoyaltyJSON `json:"royalty,omitempty"`
+	Transfers  uint64       `json:"transfers"`
+	Auction    string       `json:"auction,omitempty"`
+	Height     uint32       `json:"height"`
+}
+
+func (s *Server) toNFTJSON(state *core.NFTState) (NFTJSON, error) {
+	nftJSON := NFTJSON{
+		Hash:      state.Hash.String(),
+		TokenID:   state.TokenID,
+		Burned:    state.Burned,
+		Metadata:  decodeMetadata(state.Metadata),
+		Royalty:   toRoyaltyJSON(state.Royalty),
+		Transfers: state.Transfers,
+		Height:    state.Position.Height,
+	}
+	if !state.Collection.IsZero() {
+		nftJSON.Collection = state.Collection.String()
+	}
+	if !state.Owner.IsZero() {
+		nftJSON.Owner = state.Owner.String()
+	}
+	if !state.Auction.IsZero() {
+		nftJSON.Auction = state.Auction.String()
+	}
+	tx, err := s.chain.GetNFT(state.Hash)
+	if err != nil {
+		return nftJSON, err
+	}
+	if asset, ok := tx.TxInner.(core.MintTx).NFT.(core.NFTAsset); ok {
+		nftJSON.Type = string(asset.Type)
+		nftJSON.Data = asset.Data
+	}
+	return nftJSON, nil
+}
+
+func (s *Server) nftPageJSON(c echo.Context, page *core.NFTPage) error {
+	nfts := []NFTJSON{}
+	for _, state := range page.NFTs {
+		nftJSON, err := s.toNFTJSON(state)
+		if err != nil {
+			return c.JSON(http.StatusInternalServerError, echo.Map{"error": err.Error()})
+		}
+		nfts = append(nfts, nftJSON)
+	}
+	return c.JSON(http.StatusOK, echo.Map{
+		"nfts": nfts,
+		"next": page.Next,
+	})
+}
+
+// GetNFTHandler returns the nft with its owner and decoded metadata, nft is the hash of its mint transaction
+func (s *Server) GetNFTHandler(c echo.Context) error {
+	hashBytes, err := hex.DecodeString(c.Param("hash"))
+	if err != nil || len(hashBytes) != len(types.Hash{}) {
+		return c.JSON(http.StatusBadRequest, echo.Map{"errors": "cannot decode given hash"})
+	}
+	state, err := s.chain.GetNFTState(types.HashFromBytes(hashBytes))
+	if err != nil {
+		return c.JSON(http.StatusNotFound, echo.Map{"error": err.Error()})
+	}
+	nftJSON, err := s.toNFTJSON(state)
+	if err != nil {
+		return c.JSON(http.StatusInternalServerError, echo.Map{"error": err.Error()})
+	}
+	return c.JSON(http.StatusOK, nftJSON)
+}
+
+// GetAccountNFTsHandler returns the nft owned by the address, in the order they are minted.
+// Query params: cursor (from previous page), limit
+func (s *Server) GetAccountNFTsHandler(c echo.Context) error {
+	addrBytes, err := hex.DecodeString(c.Param("hash"))
+	if err != nil || len(addrBytes) != len(types.Address{}) {
+		return c.JSON(http.StatusBadRequest, echo.Map{"errors": "cannot decode given address"})
+	}
+	query, err := parseNFTQuery(c)
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, echo.Map{"error": err.Error()})
+	}
+	page, err := s.chain.GetNFTsOfOwner(types.AddressFromBytes(addrBytes), query)
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, echo.Map{"error": err.Error()})
+	}
+	return s.nftPageJSON(c, page)
+}
+
+type ListingJSON struct {
+	NFT    NFTJSON `json:"nft"`
+	Seller string  `json:"seller"`
+	Price  uint64  `json:"price"`
+	Height uint32  `json:"height"`
+}
+
+func (s *Server) toListingJSON(listing *core.Listing) (ListingJSON, error) {
+	state, err := s.chain.GetNFTState(listing.NFT)
+	if err != nil {
+		return ListingJSON{}, err
+	}
+	nftJSON, err := s.toNFTJSON(state)
+	if err != nil {
+		return ListingJSON{}, err
+	}
+	return ListingJSON{
+		NFT:    nftJSON,
+		Seller: listing.Seller.String(),
+		Price:  listing.Price,
+		Height: listing.Position.Height,
+	}, nil
+}
+
+// GetListingsHandler returns the active listings of the marketplace, in the order they are listed.
+// Query params: cursor (from previous page), limit
+func (s *Server) GetListingsHandler(c echo.Context) error {
+	query, err := parseNFTQuery(c)
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, echo.Map{"error": err.Error()})
+	}
+	page, err := s.chain.GetListings(query)
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, echo.Map{"error": err.Error()})
+	}
+	listings := []ListingJSON{}
+	for _, listing := range page.Listings {
+		listingJSON, err := s.toListingJSON(listing)
+		if err != nil {
+			return c.JSON(http.StatusInternalServerError, echo.Map{"error": err.Error()})
+		}
+		listings = append(listings, listingJSON)
+	}
+	return c.JSON(http.StatusOK, echo.Map{
+		"listings": listings,
+		"next":     page.Next,
+	})
+}
+
+// GetNFTListingHandler returns the active listing of the nft
+func (s *Server) GetNFTListingHandler(c echo.Context) error {
+	hashBytes, err := hex.DecodeString(c.Param("hash"))
+	if err != nil || len(hashBytes) != len(types.Hash{}) {
+		return c.JSON(http.StatusBadRequest, echo.Map{"errors": "cannot decode given hash"})
+	}
+	listing, err := s.chain.GetListing(types.HashFromBytes(hashBytes))
+	if err != nil {
+		return c.JSON(http.StatusNotFound, echo.Map{"error": err.Error()})
+	}
+	listingJSON, err := s.toListingJSON(listing)
+	if err != nil {
+		return c.JSON(http.StatusInternalServerError, echo.Map{"error": err.Error()})
+	}
+	return c.JSON(http.StatusOK, listingJSON)
+}
+
+type OfferJSON struct {
+	Buyer  string `json:"buyer"`
+	Price  uint64 `json:"price"`
+	Height uint32 `json:"height"`
+}
+
+// GetNFTOffersHandler returns the offers for the nft, in the order they are made
+func (s *Server) GetNFTOffersHandler(c echo.Context) error {
+	hashBytes, err := hex.DecodeString(c.Param("hash"))
+	if err != nil || len(hashBytes) != len(types.Hash{}) {
+		return c.JSON(http.StatusBadRequest, echo.Map{"errors": "cannot decode given hash"})
+	}
+	offers, err := s.chain.GetOffersOfNFT(types.HashFromBytes(hashBytes))
+	if err != nil {
+		return c.JSON(http.StatusNotFound, echo.Map{"error": err.Error()})
+	}
+	offersJSON := []OfferJSON{}
+	for _, offer := range offers {
+		offersJSON = append(offersJSON, OfferJSON{
+			Buyer:  offer.Buyer.String(),
+			Price:  offer.Price,
+			Height: offer.Position.Height,
+		})
+	}
+	return c.JSON(http.StatusOK, echo.Map{"offers": offersJSON})
+}
+
+type AuctionJSON struct {
+	Hash         string `json:"hash"`
+	NFT          string `json:"nft"`
+	Seller       string `json:"seller"`
+	ReservePrice uint64 `json:"reserve_price"`
+	EndHeight    uint32 `json:"end_height"`
+	Bidder       string `json:"bidder,omitempty"`
+	Bid          uint64 `json:"bid"`
+	MinBid       uint64 `json:"min_bid"`
+	Ended        bool   `json:"ended"`
+	Settled      bool   `json:"settled"`
+}
+
+func (s *Server) toAuctionJSON(auction *core.Auction) AuctionJSON {
+	auctionJSON := AuctionJSON{
+		Hash:         auction.Hash.String(),
+		NFT:          auction.NFT.String(),
+		Seller:       auction.Seller.String(),
+		ReservePrice: auction.ReservePrice,
+		EndHeight:    auction.EndHeight,
+		Bid:          auction.Bid,
+		MinBid:       auction.MinBid(),
+		Ended:        auction.Ended(s.chain.Height() + 1),
+		Settled:      auction.Settled,
+	}
+	if !auction.Bidder.IsZero() {
+		auctionJSON.Bidder = auction.Bidder.String()
+	}
+	return auctionJSON
+}
+
+// GetAuctionHandler returns the auction with its highest bid, auction is the hash of its create transaction
+func (s *Server) GetAuctionHandler(c echo.Context) error {
+	hashBytes, err := hex.DecodeString(c.Param("hash"))
+	if err != nil || len(hashBytes) != len(types.Hash{}) {
+		return c.JSON(http.StatusBadRequest, echo.Map{"errors": "cannot decode given hash"})
+	}
+	auction, err := s.chain.GetAuction(types.HashFromBytes(hashBytes))
+	if err != nil {
+		return c.JSON(http.StatusNotFound, echo.Map{"error": err.Error()})
+	}
+	return c.JSON(http.StatusOK, s.toAuctionJSON(auction))
+}
+
+// GetAuctionsHandler returns the auctions not settled yet, in the order they are created.
+// Query params: cursor (from previous page), limit
+func (s *Server) GetAuctionsHandler(c echo.Context) error {
+	query, err := parseNFTQuery(c)
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, echo.Map{"error": err.Error()})
+	}
+	page, err := s.chain.GetAuctions(query)
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, echo.Map{"error": err.Error()})
+	}
+	auctions := []AuctionJSON{}
+	for _, auction := range page.Auctions {
+		auctions = append(auctions, s.toAuctionJSON(auction))
+	}
+	return c.JSON(http.StatusOK, echo.Map{
+		"auctions": auctions,
+		"next":     page.Next,
+	})
+}
+
+type CollectionJSON struct {
+	Hash      string       `json:"hash"`
+	Owner     string       `json:"owner"`
+	Type      string       `json:"collection_type"`
+	Minters   []string     `json:"minters"`
+	MaxSupply uint64       `json:"max_supply"`
+	Minted    uint64       `json:"minted"`
+	Burned    uint64       `json:"burned"`
+	Mutable   bool         `json:"mutable"`
+	Metadata  any          `json:"metadata"`
+	Royalty   *RoyaltyJSON `json:"royalty,omitempty"`
+	Height    uint32       `json:"height"`
+}
+
+func (s *Server) toCollectionJSON(state *core.CollectionState) (CollectionJSON, error) {
+	minters := []string{}
+	for _, minter := range state.Minters {
+		minters = append(minters, minter.String())
+	}
+	collectionJSON := CollectionJSON{
+		Hash:      state.Hash.String(),
+		Owner:     state.Owner.String(),
+		Minters:   minters,
+		MaxSupply: state.MaxSupply,
+		Minted:    state.Minted,
+		Burned:    state.Burned,
+		Mutable:   state.Mutable,
+		Royalty:   toRoyaltyJSON(state.Royalty),
+		Height:    state.Position.Height,
+	}
+	tx, err := s.chain.GetCollection(state.Hash)
+	if err != nil {
+		return collectionJSON, err
+	}
+	mintTx := tx.TxInner.(core.MintTx)
+	if collection, ok := mintTx.NFT.(core.NFTCollection); ok {
+		collectionJSON.Type = string(collection.Type)
+	}
+	collectionJSON.Metadata = decodeMetadata(mintTx.Metadata)
+	return collectionJSON, nil
+}
+
+// GetCollectionHandler returns the collection with its supply and decoded metadata
+func (s *Server) GetCollectionHandler(c echo.Context) error {
+	hashBytes, err := hex.DecodeString(c.Param("hash"))
+	if err != nil || len(hashBytes) != len(types.Hash{}) {
+		return c.JSON(http.StatusBadRequest, echo.Map{"errors": "cannot decode given hash"})
+	}
+	state, err := s.chain.GetCollectionState(types.HashFromBytes(hashBytes))
+	if err != nil {
+		return c.JSON(http.StatusNotFound, echo.Map{"error": err.Error()})
+	}
+	collectionJSON, err := s.toCollectionJSON(state)
+	if err != nil {
+		return c.JSON(http.StatusInternalServerError, echo.Map{"error": err.Error()})
+	}
+	return c.JSON(http.StatusOK, collectionJSON)
+}
+
+// GetCollectionNFTsHandler returns the nft of the collection, in the order of their token ids.
+// Query params: cursor (from previous page), limit
+func (s *Server) GetCollectionNFTsHandler(c echo.Context) error {
+	hashBytes, err := hex.DecodeString(c.Param("hash"))
+	if err != nil || len(hashBytes) != len(types.Hash{}) {
+		return c.JSON(http.StatusBadRequest, echo.Map{"errors": "cannot decode given hash"})
+	}
+	query, err := parseNFTQuery(c)
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, echo.Map{"error": err.Error()})
+	}
+	page, err := s.chain.GetNFTsOfCollection(types.HashFromBytes(hashBytes), query)
+	if err != nil {
+		return c.JSON(http.StatusNotFound, echo.Map{"error": err.Error()})
+	}
+	return s.nftPageJSON(c, page)
+}
+
+// GetCollectionsHandler returns the collections, in the order they are created.
+// Query params: cursor (from previous page), limit
+func (s *Server) GetCollectionsHandler(c echo.Context) error {
+	query, err := parseNFTQuery(c)
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, echo.Map{"error": err.Error()})
+	}
+	page, err := s.chain.GetCollections(query)
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, echo.Map{"error": err.Error()})
+	}
+	collections := []CollectionJSON{}
+	for _, state := range page.Collections {
+		collectionJSON, err := s.toCollectionJSON(state)
+		if err != nil {
+			return c.JSON(http.StatusInternalServerError, echo.Map{"error": err.Error()})
+		}
+		collections = append(collections, collectionJSON)
+	}
+	return c.JSON(http.StatusOK, echo.Map{
+		"collections": collections,
+		"next":        page.Next,
+	})
+}
+
+type TokenJSON struct {
+	Hash          string `json:"hash"`
+	Name          string `json:"name"`
+	Symbol        string `json:"symbol"`
+	Decimals      uint8  `json:"decimals"`
+	Supply        uint64 `json:"supply"`
+	MintAuthority string `json:"mint_authority,omitempty"`
+	Creator       string `json:"creator"`
+}
+
+func toTokenJSON(token *core.Token) TokenJSON {
+	tokenJSON := TokenJSON{
+		Hash:     token.Hash.String(),
+		Name:     token.Name,
+		Symbol:   token.Symbol,
+		Decimals: token.Decimals,
+		Supply:   token.Supply,
+		Creator:  token.Creator.String(),
+	}
+	if !token.Authority.IsZero() {
+		tokenJSON.MintAuthority = token.Authority.String()
+	}
+	return tokenJSON
+}
+
+func (s *Server) GetTokenHandler(c echo.Context) error {
+	hashBytes, err := hex.DecodeString(c.Param("hash"))
+	if err != nil || len(hashBytes) != len(types.Hash{}) {
+		return c.JSON(http.StatusBadRequest, echo.Map{"errors": "cannot decode given hash"})
+	}
+	token, err := s.chain.GetToken(types.HashFromBytes(hashBytes))
+	if err != nil {
+		return c.JSON(http.StatusNotFound, echo.Map{"error": err.Error()})
+	}
+	return c.JSON(http.StatusOK, toTokenJSON(token))
+}
+
+// GetTokenBalanceHandler returns the balance of the token held by the address
+func (s *Server) GetTokenBalanceHandler(c echo.Context) error {
+	hashBytes, err := hex.DecodeString(c.Param("hash"))
+	if err != nil || len(hashBytes) != len(types.Hash{}) {
+		return c.JSON(http.StatusBadRequest, echo.Map{"errors": "cannot decode given hash"})
+	}
+	addrBytes, err := hex.DecodeString(c.Param("addr"))
+	if err != nil || len(addrBytes) != len(types.Address{}) {
+		return c.JSON(http.StatusBadRequest, echo.Map{"errors": "cannot decode given address"})
+	}
+	token, err := s.chain.GetToken(types.HashFromBytes(hashBytes))
+	if err != nil {
+		return c.JSON(http.StatusNotFound, echo.Map{"error": err.Error()})
+	}
+	addr := types.AddressFromBytes(addrBytes)
+	return c.JSON(http.StatusOK, echo.Map{
+		"token":   token.Hash.String(),
+		"symbol":  token.Symbol,
+		"addr":    addr.String(),
+		"balance": s.chain.GetTokenBalance(addr, token.Hash),
+	})
+}
+
+// GetAccountTokensHandler returns every token held by the address with its balance
+func (s *Server) GetAccountTokensHandler(c echo.Context) error {
+	addrBytes, err := hex.DecodeString(c.Param("hash"))
+	if err != nil || len(addrBytes) != len(types.Address{}) {
+		return c.JSON(http.StatusBadRequest, echo.Map{"errors": "cannot decode given address"})
+	}
+	balances, err := s.chain.GetTokenBalancesOfAccount(types.AddressFromBytes(addrBytes))
+	if err != nil {
+		return c.JSON(http.StatusNotFound, echo.Map{"error": err.Error()})
+	}
+	tokens := []echo.Map{}
+	for hash, balance := range balances {
+		token, err := s.chain.GetToken(hash)
+		if err != nil {
+			return c.JSON(http.StatusInternalServerError, echo.Map{"error": err.Error()})
+		}
+		tokens = append(tokens, echo.Map{
+			"token":    hash.String(),
+			"symbol":   token.Symbol,
+			"decimals": token.Decimals,
+			"balance":  balance,
+		})
+	}
+	sort.Slice(tokens, func(i, j int) bool {
+		return tokens[i]["token"].(string) < tokens[j]["token"].(string)
+	})
+	return c.JSON(http.StatusOK, echo.Map{"tokens": tokens})
+}
+
+type AccountTxJSON struct {
+	Hash   string   `json:"hash"`
+	Type   string   `json:"tx_type"`
+	Roles  []string `json:"roles"`
+	Height uint32   `json:"height"`
+	Index  uint32   `json:"index"`
+}
+
+// GetAccountTransactionsHandler returns every transaction the address took part in, ordered by height.
+// Query params: cursor (from previous page), limit, type (comma separated tx types), order (asc|desc)
+func (s *Server) GetAccountTransactionsHandler(c echo.Context) error {
+	addrString := c.Param("hash")
+	if addrString == "" {
+		return c.JSON(http.StatusBadRequest, echo.Map{"errors": "invalid hash"})
+	}
+	addrBytes, err := hex.DecodeString(addrString)
+	if err != nil || len(addrBytes) != len(types.Address{}) {
+		return c.JSON(http.StatusBadRequest, echo.Map{"errors": "cannot decode given address"})
+	}
+
+	query := core.AccountTxQuery{
+		Cursor: c.QueryParam("cursor"),
+		Desc:   c.QueryParam("order") == "desc",
+	}
+	if limitParam := c.QueryParam("limit"); limitParam != "" {
+		limit, err := strconv.Atoi(limitParam)
+		if err != nil {
+			return c.JSON(http.StatusBadRequest, echo.Map{"error": err.Error()})
+		}
+		query.Limit = limit
+	}
+	if typeParam := c.QueryParam("type"); typeParam != "" {
+		for _, typ := range strings.Split(typeParam, ",") {
+			query.Types = append(query.Types, core.TxType(strings.TrimSpace(typ)))
+		}
+	}
+
+	page, err := s.chain.GetAccountTransactions(types.AddressFromBytes(addrBytes), query)
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, echo.Map{"error": err.Error()})
+	}
+	txx := []AccountTxJSON{}
+	for _, entry := range page.Txx {
+		roles := []string{}
+		for _, role := range entry.Roles {
+			roles = append(roles, string(role))
+		}
+		txx = append(txx, AccountTxJSON{
+			Hash:   entry.Hash.String(),
+			Type:   string(entry.Type),
+			Roles:  roles,
+			Height: entry.Height,
+			Index:  entry.Index,
+		})
+	}
+	return c.JSON(http.StatusOK, echo.Map{
+		"transactions": txx,
+		"next":         page.Next,
+	})
+}
@@ -32,12 +32,36 @@ func (s *Server) initRoute() *echo.Echo {
 	app := echo.New()
 	app.GET("/health", s.HealthHandler)
 	app.GET("/api/height", s.GetHeightHandler)
+	app.GET("/api/chain", s.GetChainInfoHandler)
 	app.GET("/api/block", s.GetBlockWithHeightHandler)
+	app.GET("/api/fee", s.GetBaseFeeHandler)
+	app.GET("/api/supply", s.GetSupplyHandler)
 	app.POST("/api/tx", s.SendTransactionHandler)
 	app.GET("/api/tx/:hash", s.GetTransactionWithHashHandler)
 	app.POST("/api/account/register", s.RegisterNewAccountStateHandler)
 	app.GET("/api/account/summary/:hash", s.GetAccountStateSummaryHandler)
 	app.GET("/api/account/state/:hash", s.GetAccountStateHandler)
+	app.GET("/api/account/nonce/:hash", s.GetAccountNonceHandler)
+	app.GET("/api/account/txs/:hash", s.GetAccountTransactionsHandler)
+	app.GET("/api/account/vestings/:hash", s.GetAccountVestingsHandler)
+	app.GET("/api/account/htlcs/:hash", s.GetAccountHTLCsHandler)
+	app.GET("/api/account/tokens/:hash", s.GetAccountTokensHandler)
+	app.GET("/api/account/nfts/:hash", s.GetAccountNFTsHandler)
+	app.GET("/api/multisig/:hash", s.GetMultisigHandler)
+	app.GET("/api/htlc/:hash", s.GetHTLCHandler)
+	app.GET("/api/nft/:hash", s.GetNFTHandler)
+	app.GET("/api/nft/:hash/owner", s.GetNFTOwnerHandler)
+	app.GET("/api/nft/:hash/history", s.GetNFTHistoryHandler)
+	app.GET("/api/nft/:hash/listing", s.GetNFTListingHandler)
+	app.GET("/api/nft/:hash/offers", s.GetNFTOffersHandler)
+	app.GET("/api/listings", s.GetListingsHandler)
+	app.GET("/api/auctions", s.GetAuctionsHandler)
+	app.GET("/api/auction/:hash", s.GetAuctionHandler)
+	app.GET("/api/collections", s.GetCollectionsHandler)
+	app.GET("/api/collection/:hash", s.GetCollectionHandler)
+	app.GET("/api/collection/:hash/nfts", s.GetCollectionNFTsHandler)
+	app.GET("/api/token/:hash", s.GetTokenHandler)
+	app.GET("/api/token/:hash/balance/:addr", s.GetTokenBalanceHandler)
 	return app
 }
 
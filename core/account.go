@@ -12,11 +12,12 @@ type AccountState struct {
 	Addr    types.Address
 	Nonce   uint64
 	Balance uint64
+	Locked  uint64 // coins locked by vestings, auction bids and htlcs, they are not part of the balance
 }
 
 func (s AccountState) String() string {
 	str := &strings.Builder{}
-	fmt.Fprintf(str, "[addr = %s, balance = %d, nonce = %d]", s.Addr.String(), s.Balance, s.Nonce)
+	fmt.Fprintf(str, "[addr = %s, balance = %d, locked = %d, nonce = %d]", s.Addr.String(), s.Balance, s.Locked, s.Nonce)
 	return str.String()
 }
 
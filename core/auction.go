@@ -0,0 +1,138 @@
+package core
+
+import (
+	"blocker/serialize"
+	"blocker/types"
+	"encoding/gob"
+	"errors"
+	"fmt"
+)
+
+var (
+	ErrAuctionInvalid    = errors.New("auction transaction is invalid")
+	ErrAuctionNotExisted = errors.New("auction not existed")
+	ErrAuctionEnded      = errors.New("auction ended")
+	ErrAuctionNotEnded   = errors.New("auction not ended yet")
+	ErrAuctionSettled    = errors.New("auction already settled")
+	ErrAuctionBidTooLow  = errors.New("bid is below the reserve price or the highest bid")
+	ErrNFTInAuction      = errors.New("nft is held by an auction")
+)
+
+// maxAuctionBlocks bounds how long the bids of an auction stay locked
+const maxAuctionBlocks = 1_000_000
+
+// Auction is an english auction of an nft, it is identified by the hash of the transaction that created it.
+// The nft is held by the auction until it is settled, the highest bid is locked in the account of its bidder.
+type Auction struct {
+	Hash         types.Hash
+	NFT          types.Hash
+	Seller       types.Address
+	ReservePrice uint64
+	EndHeight    uint32 // bids are accepted in blocks below EndHeight, the auction is settled from EndHeight
+	Bidder       types.Address
+	Bid          uint64
+	Settled      bool
+	Position     TxCursor // position of the create transaction in the chain
+}
+
+// AuctionCreateTx puts the nft owned by the sender on auction until EndHeight
+type AuctionCreateTx struct {
+	NFT          types.Hash
+	ReservePrice uint64
+	EndHeight    uint32
+}
+
+// AuctionBidTx bids Amount on the auction, the amount is locked until the sender is outbid or the auction is settled
+type AuctionBidTx struct {
+	Auction types.Hash
+	Amount  uint64
+}
+
+// AuctionSettleTx gives the nft to the highest bidder and the bid to the seller, or the nft back to the seller
+// when there is no bid. Anyone could settle an ended auction.
+type AuctionSettleTx struct {
+	Auction types.Hash
+}
+
+func NewAuctionTransaction(inner any, nonce uint64) *Transaction {
+	return &Transaction{
+		TxInner: inner,
+		Nonce:   nonce,
+	}
+}
+
+func NewAuction(hash types.Hash, seller types.Address, tx AuctionCreateTx) *Auction {
+	return &Auction{
+		Hash:         hash,
+		NFT:          tx.NFT,
+		Seller:       seller,
+		ReservePrice: tx.ReservePrice,
+		EndHeight:    tx.EndHeight,
+	}
+}
+
+// MinBid returns the lowest amount a new bid must reach
+func (a *Auction) MinBid() uint64 {
+	if a.Bidder.IsZero() {
+		return max(a.ReservePrice, 1)
+	}
+	return a.Bid + 1
+}
+
+// Ended checks whether the auction is over in the block at height
+func (a *Auction) Ended(height uint32) bool {
+	return height >= a.EndHeight
+}
+
+func (tx *AuctionCreateTx) Bytes() []byte {
+	w := serialize.NewWriter()
+	w.WriteFixed(tx.NFT.Bytes())
+	w.WriteUint64(tx.ReservePrice)
+	w.WriteUint32(tx.EndHeight)
+	return w.Bytes()
+}
+
+func (tx *AuctionCreateTx) Validate() error {
+	if tx.NFT.IsZero() || tx.EndHeight == 0 {
+		return fmt.Errorf("%w: nft and end height must be set", ErrAuctionInvalid)
+	}
+	return nil
+}
+
+func (tx *AuctionBidTx) Bytes() []byte {
+	w := serialize.NewWriter()
+	w.WriteFixed(tx.Auction.Bytes())
+	w.WriteUint64(tx.Amount)
+	return w.Bytes()
+}
+
+func (tx *AuctionBidTx) Validate() error {
+	if tx.Auction.IsZero() || tx.Amount == 0 {
+		return fmt.Errorf("%w: auction and amount must be set", ErrAuctionInvalid)
+	}
+	return nil
+}
+
+func (tx *AuctionSettleTx) Bytes() []byte {
+	w := serialize.NewWriter()
+	w.WriteFixed(tx.Auction.Bytes())
+	return w.Bytes()
+}
+
+func (tx *AuctionSettleTx) Validate() error {
+	if tx.Auction.IsZero() {
+		return fmt.Errorf("%w: auction must be set", ErrAuctionInvalid)
+	}
+	return nil
+}
+
+type AuctionPage struct {
+	Auctions []*Auction
+	Next     string // empty mean no more auctions
+}
+
+func init() {
+	gob.Register(AuctionCreateTx{})
+	gob.Register(AuctionBidTx{})
+	gob.Register(AuctionSettleTx{})
+}
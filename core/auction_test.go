@@ -0,0 +1,110 @@
+package core
+
+import (
+	"blocker/crypto"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestAuction(t *testing.T) {
+	privCreator := crypto.GeneratePrivateKey()
+	privAlice := crypto.GeneratePrivateKey()
+	privBob := crypto.GeneratePrivateKey()
+	privCarol := crypto.GeneratePrivateKey()
+	creator := privCreator.Public().Address()
+	alice := privAlice.Public().Address()
+	bob := privBob.Public().Address()
+	carol := privCarol.Public().Address()
+	cfg := newTestGenesisConfig()
+	for _, priv := range []*crypto.PrivateKey{privCreator, privAlice, privBob, privCarol} {
+		cfg.Alloc[priv.Public().Address().String()] = 10000
+	}
+	bc := newTestChain(t, cfg)
+
+	mint := bc.newTx(privAlice, signMint(t, privAlice, MintTx{NFT: NFTAsset{Type: NFTAssetTypeImageURL, Data: []byte("art"), Royalty: Royalty{Bps: 1000, Recipient: creator}}}))
+	bc.addBlock(mint)
+	nft := mint.Hash(TxHasher{})
+
+	// the auction must end after the block it is created in
+	endHeight := bc.Height() + 5
+	tooEarly := bc.newTx(privAlice, AuctionCreateTx{NFT: nft, ReservePrice: 100, EndHeight: bc.Height() + 1})
+	create := bc.newTx(privAlice, AuctionCreateTx{NFT: nft, ReservePrice: 100, EndHeight: endHeight})
+	assert.Equal(t, 1, len(bc.SoftcheckTransactions([]*Transaction{tooEarly, create})))
+	receipts := bc.addBlock(tooEarly, create)
+	assert.False(t, receipts[0].Succeeded())
+	assert.True(t, receipts[1].Succeeded())
+	auctionHash := create.Hash(TxHasher{})
+
+	// the nft is held by the auction
+	transfer := bc.newTx(privAlice, NFTTransferTx{NFT: nft, To: bob})
+	list := bc.newTx(privAlice, NFTListTx{NFT: nft, Price: 10})
+	receipts = bc.addBlock(transfer, list)
+	assert.Equal(t, ErrNFTInAuction.Error(), receipts[0].Err)
+	assert.Equal(t, ErrNFTInAuction.Error(), receipts[1].Err)
+
+	belowReserve := bc.newTx(privBob, AuctionBidTx{Auction: auctionHash, Amount: 99})
+	bySeller := bc.newTx(privAlice, AuctionBidTx{Auction: auctionHash, Amount: 500})
+	bid := bc.newTx(privBob, AuctionBidTx{Auction: auctionHash, Amount: 100})
+	assert.Equal(t, 2, len(bc.SoftcheckTransactions([]*Transaction{belowReserve, bySeller, bid})))
+	bobBefore := bc.account(bob)
+	receipts = bc.addBlock(belowReserve, bySeller, bid)
+	assert.Equal(t, ErrAuctionBidTooLow.Error(), receipts[0].Err)
+	assert.True(t, receipts[2].Succeeded())
+	assert.Equal(t, uint64(100), bc.account(bob).Locked)
+	assert.Equal(t, bobBefore.Balance-100-receipts[0].FeeCharged-receipts[2].FeeCharged, bc.account(bob).Balance)
+
+	// the outbid amount is refunded at once
+	tie := bc.newTx(privCarol, AuctionBidTx{Auction: auctionHash, Amount: 100})
+	outbid := bc.newTx(privCarol, AuctionBidTx{Auction: auctionHash, Amount: 150})
+	bobBefore = bc.account(bob)
+	receipts = bc.addBlock(tie, outbid)
+	assert.Equal(t, ErrAuctionBidTooLow.Error(), receipts[0].Err)
+	assert.True(t, receipts[1].Succeeded())
+	assert.Equal(t, uint64(0), bc.account(bob).Locked)
+	assert.Equal(t, bobBefore.Balance+100, bc.account(bob).Balance)
+	assert.Equal(t, uint64(150), bc.account(carol).Locked)
+	auction, err := bc.GetAuction(auctionHash)
+	assert.Nil(t, err)
+	assert.Equal(t, carol, auction.Bidder)
+	assert.Equal(t, uint64(151), auction.MinBid())
+
+	// bids stop and settling starts at the end height
+	assert.Equal(t, endHeight, bc.Height()+1)
+	late := bc.newTx(privBob, AuctionBidTx{Auction: auctionHash, Amount: 1000})
+	assert.Equal(t, 1, len(bc.SoftcheckTransactions([]*Transaction{late})))
+	receipts = bc.addBlock(late)
+	assert.Equal(t, ErrAuctionEnded.Error(), receipts[0].Err)
+
+	creatorBefore, aliceBefore, carolBefore := bc.account(creator), bc.account(alice), bc.account(carol)
+	settle := bc.newTx(privBob, AuctionSettleTx{Auction: auctionHash})
+	again := bc.newTx(privBob, AuctionSettleTx{Auction: auctionHash})
+	receipts = bc.addBlock(settle, again)
+	assert.True(t, receipts[0].Succeeded(), receipts[0].Err)
+	assert.Equal(t, ErrAuctionSettled.Error(), receipts[1].Err)
+	owner, err := bc.GetNFTOwner(nft)
+	assert.Nil(t, err)
+	assert.Equal(t, carol, owner)
+	assert.Equal(t, creatorBefore.Balance+15, bc.account(creator).Balance)
+	assert.Equal(t, aliceBefore.Balance+135, bc.account(alice).Balance)
+	assert.Equal(t, carolBefore.Balance, bc.account(carol).Balance)
+	assert.Equal(t, uint64(0), bc.account(carol).Locked)
+	page, err := bc.GetAuctions(NFTQuery{})
+	assert.Nil(t, err)
+	assert.Empty(t, page.Auctions)
+
+	// without bids the nft goes back to the seller
+	create = bc.newTx(privCarol, AuctionCreateTx{NFT: nft, ReservePrice: 1000, EndHeight: bc.Height() + 3})
+	bc.addBlock(create)
+	page, err = bc.GetAuctions(NFTQuery{})
+	assert.Nil(t, err)
+	assert.Equal(t, 1, len(page.Auctions))
+	early := bc.newTx(privCarol, AuctionSettleTx{Auction: create.Hash(TxHasher{})})
+	receipts = bc.addBlock(early)
+	assert.Equal(t, ErrAuctionNotEnded.Error(), receipts[0].Err)
+	receipts = bc.addBlock(bc.newTx(privCarol, AuctionSettleTx{Auction: create.Hash(TxHasher{})}))
+	assert.True(t, receipts[0].Succeeded())
+	receipts = bc.addBlock(bc.newTx(privCarol, NFTTransferTx{NFT: nft, To: bob}))
+	assert.True(t, receipts[0].Succeeded())
+	assertSupplyInvariant(t, bc.BlockChain)
+}
@@ -0,0 +1,73 @@
+package core
+
+import (
+	"blocker/serialize"
+	"blocker/types"
+	"encoding/gob"
+	"errors"
+	"fmt"
+	"math"
+)
+
+var ErrBatchInvalid = errors.New("batch transfer is invalid")
+
+// maxBatchOutputs bounds the outputs of a batch transfer, the whole batch is paid by a single fee
+const maxBatchOutputs = 256
+
+type TransferOutput struct {
+	To    types.Address
+	Value uint64
+}
+
+// BatchTransferTx transfers from the sender to every output, either every output is applied or none
+type BatchTransferTx struct {
+	Outputs []TransferOutput
+}
+
+func NewBatchTransferTransaction(outputs []TransferOutput, nonce uint64) *Transaction {
+	return &Transaction{
+		TxInner: BatchTransferTx{Outputs: outputs},
+		Nonce:   nonce,
+	}
+}
+
+// Bytes return the canonical encoding of the batch transfer
+func (tx *BatchTransferTx) Bytes() []byte {
+	w := serialize.NewWriter()
+	w.WriteUint32(uint32(len(tx.Outputs)))
+	for _, output := range tx.Outputs {
+		w.WriteFixed(output.To.Bytes())
+		w.WriteUint64(output.Value)
+	}
+	return w.Bytes()
+}
+
+func (tx *BatchTransferTx) Validate() error {
+	if len(tx.Outputs) == 0 || len(tx.Outputs) > maxBatchOutputs {
+		return fmt.Errorf("%w: outputs must be between 1 and %d", ErrBatchInvalid, maxBatchOutputs)
+	}
+	var total uint64
+	for i, output := range tx.Outputs {
+		if output.To.IsZero() || output.Value == 0 {
+			return fmt.Errorf("%w: output (%d) must have recipient and value", ErrBatchInvalid, i)
+		}
+		if total > math.MaxUint64-output.Value {
+			return fmt.Errorf("%w: total value overflows", ErrBatchInvalid)
+		}
+		total += output.Value
+	}
+	return nil
+}
+
+// Total returns the sum of the outputs, the batch must be valid
+func (tx *BatchTransferTx) Total() uint64 {
+	var total uint64
+	for _, output := range tx.Outputs {
+		total += output.Value
+	}
+	return total
+}
+
+func init() {
+	gob.Register(BatchTransferTx{})
+}
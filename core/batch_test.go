@@ -0,0 +1,67 @@
+package core
+
+import (
+	"blocker/crypto"
+	"blocker/types"
+	"math"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestBatchTransferValidate(t *testing.T) {
+	to := crypto.GeneratePrivateKey().Public().Address()
+	assert.Nil(t, (&BatchTransferTx{Outputs: []TransferOutput{{To: to, Value: 1}}}).Validate())
+	assert.ErrorIs(t, (&BatchTransferTx{}).Validate(), ErrBatchInvalid)
+	assert.ErrorIs(t, (&BatchTransferTx{Outputs: []TransferOutput{{To: to}}}).Validate(), ErrBatchInvalid)
+	assert.ErrorIs(t, (&BatchTransferTx{Outputs: []TransferOutput{{To: types.Address{}, Value: 1}}}).Validate(), ErrBatchInvalid)
+	overflow := &BatchTransferTx{Outputs: []TransferOutput{{To: to, Value: math.MaxUint64}, {To: to, Value: 1}}}
+	assert.ErrorIs(t, overflow.Validate(), ErrBatchInvalid)
+}
+
+func TestBatchTransfer(t *testing.T) {
+	privBob := crypto.GeneratePrivateKey()
+	recipients := []types.Address{
+		crypto.GeneratePrivateKey().Public().Address(),
+		crypto.GeneratePrivateKey().Public().Address(),
+		crypto.GeneratePrivateKey().Public().Address(),
+	}
+	cfg := newTestGenesisConfig()
+	cfg.Alloc[privBob.Public().Address().String()] = 1000
+	bc := newTestChain(t, cfg)
+
+	newBatch := func(nonce uint64, values ...uint64) *Transaction {
+		outputs := []TransferOutput{}
+		for i, value := range values {
+			outputs = append(outputs, TransferOutput{To: recipients[i], Value: value})
+		}
+		tx := NewBatchTransferTransaction(outputs, nonce)
+		tx.ChainID = bc.ChainID()
+		tx.MaxFee = bc.NextBaseFee() * 2
+		assert.Nil(t, tx.Sign(privBob))
+		return tx
+	}
+
+	tx := newBatch(1, 100, 200, 300)
+	assert.Equal(t, 0, len(bc.SoftcheckTransactions([]*Transaction{tx})))
+	receipt := bc.addBlock(tx)[0]
+	assert.True(t, receipt.Succeeded())
+	assert.Equal(t, 1000-600-receipt.FeeCharged, bc.account(privBob.Public().Address()).Balance)
+	for i, value := range []uint64{100, 200, 300} {
+		assert.Equal(t, value, bc.account(recipients[i]).Balance)
+	}
+	from, to, err := bc.GetAccountTransferTransactions(recipients[1])
+	assert.Nil(t, err)
+	assert.Equal(t, 0, len(from))
+	assert.Equal(t, 1, len(to))
+
+	// no output is applied when the sender cannot cover all of them
+	before := bc.account(privBob.Public().Address()).Balance
+	tx = newBatch(2, 1, before)
+	assert.Equal(t, 1, len(bc.SoftcheckTransactions([]*Transaction{tx})))
+	receipt = bc.addBlock(tx)[0]
+	assert.False(t, receipt.Succeeded())
+	assert.Equal(t, ErrTxInsufficientBalance.Error(), receipt.Err)
+	assert.Equal(t, before-receipt.FeeCharged, bc.account(privBob.Public().Address()).Balance)
+	assert.Equal(t, uint64(100), bc.account(recipients[0]).Balance)
+}
@@ -2,12 +2,11 @@ package core
 
 import (
 	"blocker/crypto"
+	"blocker/serialize"
 	"blocker/types"
 	"bytes"
 	"crypto/sha256"
-	"encoding/gob"
 	"fmt"
-	"time"
 )
 
 type Header struct {
@@ -16,15 +15,14 @@ type Header struct {
 	DataHash      types.Hash
 	Height        uint32
 	Timestamp     int64
+	BaseFee       uint64 // burned part of the fee of every transaction in the block
 }
 
+// Bytes return the canonical encoding of the header, this is what get hashed and signed
 func (h *Header) Bytes() []byte {
-	buf := &bytes.Buffer{}
-	encoder := gob.NewEncoder(buf)
-	if err := encoder.Encode(h); err != nil {
-		panic(fmt.Sprintf("error: %v", err))
-	}
-	return buf.Bytes()
+	w := serialize.NewWriter()
+	writeHeader(w, h)
+	return w.Bytes()
 }
 
 type Block struct {
@@ -35,19 +33,6 @@ type Block struct {
 	hash         types.Hash // Cached version of the header hash
 }
 
-func NewGenesisBlock() *Block {
-	return &Block{
-		Header: &Header{
-			Version:       0,
-			PrevBlockHash: types.Hash{},
-			DataHash:      types.Hash{},
-			Height:        0,
-			Timestamp:     0000000,
-		},
-		Transactions: []*Transaction{},
-	}
-}
-
 func NewBlock(h *Header, txs []*Transaction) (*Block, error) {
 	return &Block{
 		Header:       h,
@@ -55,7 +40,7 @@ func NewBlock(h *Header, txs []*Transaction) (*Block, error) {
 	}, nil
 }
 
-func NewBlockFromPrevHeader(prevHeader *Header, txx []*Transaction) (*Block, error) {
+func NewBlockFromPrevHeader(prevHeader *Header, timestamp int64, baseFee uint64, txx []*Transaction) (*Block, error) {
 	dataHash, err := CalculateDataHash(txx)
 	if err != nil {
 		return nil, err
@@ -65,7 +50,8 @@ func NewBlockFromPrevHeader(prevHeader *Header, txx []*Transaction) (*Block, err
 		DataHash:      dataHash,
 		PrevBlockHash: BlockHasher{}.Hash(prevHeader),
 		Height:        prevHeader.Height + 1,
-		Timestamp:     time.Now().UnixNano(),
+		Timestamp:     timestamp,
+		BaseFee:       baseFee,
 	}
 
 	return NewBlock(header, txx)
@@ -123,19 +109,20 @@ func (b *Block) Hash(hasher Hasher[*Header]) types.Hash {
 
 // ReHash must be call after modified the block
 func (b *Block) ReHash(hasher Hasher[*Header]) error {
-	b.hash = hasher.Hash(b.Header)
 	dataHash, err := CalculateDataHash(b.Transactions)
 	if err != nil {
 		return err
 	}
 	b.DataHash = dataHash
+	b.hash = hasher.Hash(b.Header)
 	return nil
 }
 
+// CalculateDataHash hash the canonical encoding of the transactions
 func CalculateDataHash(txx []*Transaction) (types.Hash, error) {
 	buf := &bytes.Buffer{}
 	for _, tx := range txx {
-		if err := tx.Encode(NewGobTxEncoder(buf)); err != nil {
+		if err := tx.Encode(NewBinaryTxEncoder(buf)); err != nil {
 			return types.Hash{}, err
 		}
 	}
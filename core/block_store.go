@@ -0,0 +1,115 @@
+package core
+
+import (
+	"blocker/types"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync"
+)
+
+type InMemoryBlockStore struct {
+	blockState map[types.Hash]*Block
+	lock       sync.RWMutex
+}
+
+func NewInMemoryBlockStore() *InMemoryBlockStore {
+	store := &InMemoryBlockStore{
+		blockState: make(map[types.Hash]*Block, 10000),
+	}
+	var _ BlockStore = store
+	return store
+}
+
+func (s *InMemoryBlockStore) PutBlock(b *Block) error {
+	hash := b.Hash(BlockHasher{})
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	_, ok := s.blockState[hash]
+	if ok {
+		return ErrDocExisted
+	}
+	s.blockState[hash] = b
+	return nil
+}
+
+func (s *InMemoryBlockStore) GetBlock(hash types.Hash) (*Block, error) {
+	s.lock.RLock()
+	defer s.lock.RUnlock()
+	b, ok := s.blockState[hash]
+	if !ok {
+		return nil, ErrDocNotExisted
+	}
+	return b, nil
+}
+
+func (s *InMemoryBlockStore) HasBlock(hash types.Hash) bool {
+	s.lock.RLock()
+	defer s.lock.RUnlock()
+	_, ok := s.blockState[hash]
+	return ok
+}
+
+// FileBlockStore keeps every block in its own file inside dir, encoded with the canonical codec.
+type FileBlockStore struct {
+	dir  string
+	lock sync.RWMutex
+}
+
+func NewFileBlockStore(dir string) (*FileBlockStore, error) {
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return nil, fmt.Errorf("file block store: cannot create dir (%s), err: %w", dir, err)
+	}
+	store := &FileBlockStore{
+		dir: dir,
+	}
+	var _ BlockStore = store
+	return store, nil
+}
+
+func (s *FileBlockStore) path(hash types.Hash) string {
+	return filepath.Join(s.dir, hash.String()+".block")
+}
+
+func (s *FileBlockStore) PutBlock(b *Block) error {
+	path := s.path(b.Hash(BlockHasher{}))
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	f, err := os.OpenFile(path, os.O_CREATE|os.O_EXCL|os.O_WRONLY, 0o644)
+	if err != nil {
+		if errors.Is(err, os.ErrExist) {
+			return ErrDocExisted
+		}
+		return err
+	}
+	defer f.Close()
+	return b.Encode(NewBinaryBlockEncoder(f))
+}
+
+func (s *FileBlockStore) GetBlock(hash types.Hash) (*Block, error) {
+	s.lock.RLock()
+	defer s.lock.RUnlock()
+
+	f, err := os.Open(s.path(hash))
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return nil, ErrDocNotExisted
+		}
+		return nil, err
+	}
+	defer f.Close()
+	b := new(Block)
+	if err := b.Decode(NewBinaryBlockDecoder(f)); err != nil {
+		return nil, err
+	}
+	return b, nil
+}
+
+func (s *FileBlockStore) HasBlock(hash types.Hash) bool {
+	s.lock.RLock()
+	defer s.lock.RUnlock()
+	_, err := os.Stat(s.path(hash))
+	return err == nil
+}
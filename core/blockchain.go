@@ -3,9 +3,13 @@ package core
 import (
 	"blocker/crypto"
 	"blocker/types"
+	"bytes"
 	"errors"
 	"fmt"
+	"math"
+	"slices"
 	"sync"
+	"time"
 
 	"github.com/go-kit/log"
 )
@@ -15,237 +19,1574 @@ var (
 	ErrHeightTooHigh         = errors.New("given height is too high")
 	ErrTxInvalid             = errors.New("given transaction is invalid")
 	ErrTxInsufficientBalance = errors.New("given account balance insufficient")
+	ErrTxInsufficientFee     = errors.New("given account balance cannot pay the fee")
+	ErrTxBalanceOverflow     = errors.New("given account balance overflows")
+	ErrBlockFinalized        = errors.New("block conflicts with the finalized chain")
 )
 
 type BlockChain struct {
-	contractState *State
-	logger        log.Logger
-	store         Storage
-	validator     Validator
-	headers       []*Header
-	blocks        []*Block
-	mintPool      []*TransferTx
-	confirmsLevel uint32 // number of comfirminations required to consider tx are confirmed
-	lock          sync.RWMutex
+	chainID    string
+	params     ChainParams
+	validators []*crypto.PublicKey // allowed validators, empty mean every key could validate
+	logger     log.Logger
+	store      Storage
+	validator  Validator
+	headers    []*Header
+	blocks     []*Block
+	mintPool   []*TransferTx
+	finalized  Checkpoint // blocks at or below are never replaced
+	lock       sync.RWMutex
 }
 
 func NewBlockChain(genesis *Block, store Storage, logger log.Logger) (*BlockChain, error) {
+	bc := newBlockChain(store, logger)
+	err := bc.handleGenesisBlock(genesis)
+	return bc, err
+}
+
+// newBlockChain creates the chain without any block, the genesis block must be handled before it is used
+func newBlockChain(store Storage, logger log.Logger) *BlockChain {
 	bc := &BlockChain{
-		contractState: NewState(),
-		logger:        logger,
-		store:         store,
-		headers:       []*Header{},
-		blocks:        []*Block{},
-		mintPool:      make([]*TransferTx, 1000),
-		confirmsLevel: 15,
+		logger:   logger,
+		store:    store,
+		headers:  []*Header{},
+		blocks:   []*Block{},
+		mintPool: make([]*TransferTx, 1000),
 	}
 	bc.validator = NewBlockValidator(bc)
-	err := bc.handleGenesisBlock(genesis)
-	return bc, err
+	return bc
+}
+
+// NewBlockChainFromGenesis creates the chain with the genesis block, chain id, validators and params of cfg
+func NewBlockChainFromGenesis(cfg *GenesisConfig, store Storage, logger log.Logger) (*BlockChain, error) {
+	genesis, err := cfg.Block()
+	if err != nil {
+		return nil, err
+	}
+	validators, err := cfg.ValidatorKeys()
+	if err != nil {
+		return nil, err
+	}
+	// the genesis block is handled with the params of the chain, finality included
+	bc := newBlockChain(store, logger)
+	bc.chainID = cfg.ChainID
+	bc.params = cfg.Params
+	bc.validators = validators
+	if err := bc.handleGenesisBlock(genesis); err != nil {
+		return nil, err
+	}
+	return bc, nil
 }
 
 func (bc *BlockChain) SetValidator(v Validator) {
 	bc.validator = v
 }
 
+func (bc *BlockChain) ChainID() string {
+	return bc.chainID
+}
+
+func (bc *BlockChain) Params() ChainParams {
+	return bc.params
+}
+
+func (bc *BlockChain) GenesisHash() types.Hash {
+	bc.lock.RLock()
+	defer bc.lock.RUnlock()
+	return BlockHasher{}.Hash(bc.headers[0])
+}
+
+// IsValidator check if the given key is allowed to sign blocks
+func (bc *BlockChain) IsValidator(pubKey *crypto.PublicKey) bool {
+	if len(bc.validators) == 0 {
+		return true
+	}
+	for _, v := range bc.validators {
+		if bytes.Equal(v.Bytes(), pubKey.Bytes()) {
+			return true
+		}
+	}
+	return false
+}
+
 func (bc *BlockChain) handleGenesisBlock(genesis *Block) error {
 	for _, tx := range genesis.Transactions {
-		if tx.IsCoinbase() {
+		switch {
+		case tx.IsCoinbase():
+			if err := bc.handleCoinbaseTransaction(tx.TxInner.(TransferTx)); err != nil {
+				return err
+			}
+		case tx.isGenesisAllocation():
 			transferTx := tx.TxInner.(TransferTx)
-			err := bc.handleCoinbaseTransaction(transferTx)
-			if err != nil {
-				return nil
+			if err := bc.store.Credit(transferTx.To, transferTx.Value); err != nil {
+				return err
+			}
+			if err := bc.updateSupply(transferTx.Value, 0); err != nil {
+				return err
+			}
+		}
+	}
+	return bc.addBlockWithoutValidation(genesis, nil)
+}
+
+func (bc *BlockChain) AddBlock(b *Block) error {
+	if err := bc.validator.Validate(b); err != nil {
+		return err
+	}
+	// the block is applied as a whole, a transaction rejecting it reverts the ones executed before
+	snapshot := bc.store.Snapshot()
+	receipts, err := bc.executeBlock(b)
+	if err != nil {
+		bc.store.RevertToSnapshot(snapshot)
+		bc.store.Commit()
+		return err
+	}
+	bc.store.Commit()
+
+	if err := bc.addBlockWithoutValidation(b, receipts); err != nil {
+		return err
+	}
+	return bc.storeReceipts(b, receipts)
+}
+
+// executeBlock runs the transactions of b and pays its validator
+func (bc *BlockChain) executeBlock(b *Block) ([]*Receipt, error) {
+	// base fee is burned, only tips go to the validator
+	var tip, burned uint64 = 0, 0
+	receipts := make([]*Receipt, 0, len(b.Transactions))
+	for i, tx := range b.Transactions {
+		receipt, err := bc.executeTransaction(tx, b.Header, uint32(i))
+		if err != nil {
+			return nil, err
+		}
+		receipts = append(receipts, receipt)
+		tip += receipt.FeeCharged - receipt.FeeBurned
+		burned += receipt.FeeBurned
+	}
+
+	reward, err := bc.payBlockReward(b)
+	if err != nil {
+		return nil, err
+	}
+	if err := bc.store.Credit(b.Validator.Address(), tip+reward); err != nil {
+		return nil, err
+	}
+	if err := bc.updateSupply(reward, burned); err != nil {
+		return nil, err
+	}
+	return receipts, nil
+}
+
+// payBlockReward takes the reward of the block from the coinbase reserve, the reward is
+// capped by what is left in the reserve
+func (bc *BlockChain) payBlockReward(b *Block) (uint64, error) {
+	coinbase := bc.store.GetCoinbaseState()
+	if coinbase == nil {
+		return 0, nil
+	}
+	reward := min(BlockReward(b.Height, bc.params), coinbase.Balance)
+	if err := bc.store.DebitCoinbase(reward); err != nil {
+		return 0, err
+	}
+	return reward, nil
+}
+
+func (bc *BlockChain) updateSupply(issued, burned uint64) error {
+	supply := bc.store.GetSupply()
+	supply.Issued += issued
+	supply.Burned += burned
+	return bc.store.PutSupply(supply)
+}
+
+// Supply returns the coins issued and burned so far
+func (bc *BlockChain) Supply() Supply {
+	return bc.store.GetSupply()
+}
+
+// CoinbaseReserve returns the coins left in the coinbase for block rewards
+func (bc *BlockChain) CoinbaseReserve() uint64 {
+	coinbase := bc.store.GetCoinbaseState()
+	if coinbase == nil {
+		return 0
+	}
+	return coinbase.Balance
+}
+
+// executeTransaction runs tx, at index in the block with header h, against the state. Invalid nonce or max fee below the base fee rejects
+// the whole block, any other failure is recorded in the receipt and the transaction only pays its fee and uses its nonce.
+func (bc *BlockChain) executeTransaction(tx *Transaction, h *Header, index uint32) (*Receipt, error) {
+	baseFee := h.BaseFee
+	if err := bc.checkMultisigSender(tx); err != nil {
+		return nil, err
+	}
+	fromState, err := bc.store.GetAccount(tx.Sender())
+	if err != nil {
+		return nil, err
+	}
+	if fromState.Nonce+1 != tx.Nonce {
+		bc.logger.Log("error", fmt.Sprintf("expected %d, given %d", fromState.Nonce+1, tx.Nonce))
+		return nil, ErrNonceInvalid
+	}
+	if tx.MaxFee < baseFee {
+		return nil, ErrFeeTooLow
+	}
+
+	receipt := NewReceipt(tx)
+	receipt.Index = index
+	err = bc.chargeFee(tx, baseFee, receipt)
+	if err == nil {
+		// every change made after the fee is charged is reverted when the transaction fails
+		snapshot, changes := bc.store.Snapshot(), len(receipt.StateChanges)
+		// logic of vm put here
+		vm := NewVM(tx.Data, bc.store.ContractState())
+		err = vm.Run()
+		receipt.GasUsed = vm.GasUsed()
+		if err == nil {
+			receipt.Logs = vm.Logs()
+			// logic of mintTx put here
+			err = bc.handleNatveTransaction(tx, h, receipt)
+		}
+		if err != nil {
+			bc.store.RevertToSnapshot(snapshot)
+			receipt.StateChanges = receipt.StateChanges[:changes]
+			receipt.Logs = []Log{}
+		}
+	}
+	if err != nil {
+		bc.logger.Log("msg", "transaction failed", "hash", receipt.TxHash.Short(), "error", err)
+		receipt.fail(err)
+	}
+
+	if err := bc.store.IncreaseAccountNonce(tx.Sender()); err != nil {
+		return nil, err
+	}
+	receipt.addStateChange(StateChange{Kind: StateChangeNonce, Addr: tx.Sender(), Delta: 1})
+	return receipt, nil
+}
+
+// chargeFee debits the fee of tx from the sender before it is executed, the same way for every
+// transaction type. The fee is kept when the execution fails.
+func (bc *BlockChain) chargeFee(tx *Transaction, baseFee uint64, receipt *Receipt) error {
+	fee := tx.EffectiveFee(baseFee)
+	fromState, err := bc.store.GetAccount(tx.Sender())
+	if err != nil {
+		return err
+	}
+	if fromState.Balance < fee {
+		return ErrTxInsufficientFee
+	}
+	if err := bc.debit(receipt, fromState.Addr, fee); err != nil {
+		return err
+	}
+	receipt.FeeCharged = fee
+	receipt.FeeBurned = baseFee
+	return nil
+}
+
+func (bc *BlockChain) storeReceipts(b *Block, receipts []*Receipt) error {
+	blockHash := b.Hash(BlockHasher{})
+	for _, receipt := range receipts {
+		receipt.BlockHash = blockHash
+		receipt.Height = b.Height
+		if err := bc.store.PutReceipt(receipt); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// credit adds amount to the balance of addr and records the change in the receipt
+func (bc *BlockChain) credit(receipt *Receipt, addr types.Address, amount uint64) error {
+	if amount == 0 {
+		return nil
+	}
+	if err := bc.store.Credit(addr, amount); err != nil {
+		return err
+	}
+	receipt.addStateChange(StateChange{Kind: StateChangeBalance, Addr: addr, Delta: int64(amount)})
+	return nil
+}
+
+// debit takes amount from the balance of addr and records the change in the receipt
+func (bc *BlockChain) debit(receipt *Receipt, addr types.Address, amount uint64) error {
+	if amount == 0 {
+		return nil
+	}
+	if err := bc.store.Debit(addr, amount); err != nil {
+		return err
+	}
+	receipt.addStateChange(StateChange{Kind: StateChangeBalance, Addr: addr, Delta: -int64(amount)})
+	return nil
+}
+
+// creditLocked adds amount to the coins of addr locked by the vesting, auction or htlc with hash and records the change in the receipt
+func (bc *BlockChain) creditLocked(receipt *Receipt, addr types.Address, hash types.Hash, amount uint64) error {
+	if err := bc.store.CreditLocked(addr, amount); err != nil {
+		return err
+	}
+	receipt.addStateChange(StateChange{Kind: StateChangeLocked, Addr: addr, Delta: int64(amount), Hash: hash})
+	return nil
+}
+
+// debitLocked takes amount from the coins of addr locked by the vesting, auction or htlc with hash and records the change in the receipt
+func (bc *BlockChain) debitLocked(receipt *Receipt, addr types.Address, hash types.Hash, amount uint64) error {
+	if err := bc.store.DebitLocked(addr, amount); err != nil {
+		return err
+	}
+	receipt.addStateChange(StateChange{Kind: StateChangeLocked, Addr: addr, Delta: -int64(amount), Hash: hash})
+	return nil
+}
+
+// addBlockWithoutValidation appends b to the chain and indexes it with the receipts of its transactions
+func (bc *BlockChain) addBlockWithoutValidation(b *Block, receipts []*Receipt) error {
+	bc.lock.Lock()
+	bc.headers = append(bc.headers, b.Header)
+	bc.blocks = append(bc.blocks, b)
+	bc.updateFinalized()
+	bc.lock.Unlock()
+
+	bc.logger.Log(
+		"msg", "new block",
+		"height", b.Height,
+		"hash", fmt.Sprintf("%x", b.Hash(BlockHasher{}).Bytes()[:3]),
+		"transactions", len(b.Transactions),
+	)
+	// fmt.Println(bc.AccountState())
+	if err := bc.store.PutBlock(b); err != nil {
+		return err
+	}
+	return bc.indexBlock(b, receipts)
+}
+
+// updateFinalized moves the checkpoint to the block FinalityDepth below the tip, lock must be held
+func (bc *BlockChain) updateFinalized() {
+	tip := uint32(len(bc.headers) - 1)
+	depth := bc.params.FinalityDepth
+	if depth == 0 || tip < depth {
+		if tip == 0 {
+			bc.finalized = Checkpoint{Height: 0, Hash: BlockHasher{}.Hash(bc.headers[0])}
+		}
+		return
+	}
+	height := tip - depth
+	bc.finalized = Checkpoint{Height: height, Hash: BlockHasher{}.Hash(bc.headers[height])}
+}
+
+// Finalized returns the last finalized block
+func (bc *BlockChain) Finalized() Checkpoint {
+	bc.lock.RLock()
+	defer bc.lock.RUnlock()
+	return bc.finalized
+}
+
+// CheckFinalized rejects the header when it conflicts with the finalized checkpoint, that is when it is at or
+// below the checkpoint height and is not the block of the chain at that height
+func (bc *BlockChain) CheckFinalized(h *Header) error {
+	bc.lock.RLock()
+	defer bc.lock.RUnlock()
+	if h.Height > bc.finalized.Height {
+		return nil
+	}
+	hash := BlockHasher{}.Hash(h)
+	if int(h.Height) >= len(bc.headers) || hash != (BlockHasher{}).Hash(bc.headers[h.Height]) {
+		return fmt.Errorf("%w: block (%s) with height (%d) => finalized height (%d)", ErrBlockFinalized, hash, h.Height, bc.finalized.Height)
+	}
+	return nil
+}
+
+func (bc *BlockChain) indexBlock(b *Block, receipts []*Receipt) error {
+	for addr, entries := range accountTxsOfBlock(b, receipts) {
+		for _, entry := range entries {
+			if err := bc.store.PutAccountTx(addr, entry); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// NextBaseFee returns the base fee the next block must have
+func (bc *BlockChain) NextBaseFee() uint64 {
+	bc.lock.RLock()
+	defer bc.lock.RUnlock()
+	last := bc.blocks[len(bc.blocks)-1]
+	return NextBaseFee(last.BaseFee, len(last.Transactions), bc.params)
+}
+
+func (bc *BlockChain) HasBlock(height uint32) bool {
+	return bc.Height() >= height
+}
+
+func (bc *BlockChain) GetBlock(height uint32) (*Block, error) {
+	if !bc.HasBlock(height) {
+		return nil, fmt.Errorf("given height (%d) too high", height)
+	}
+	bc.lock.Lock()
+	block := bc.blocks[height]
+	bc.lock.Unlock()
+	return block, nil
+}
+
+func (bc *BlockChain) GetHeader(height uint32) (*Header, error) {
+	if !bc.HasBlock(height) {
+		return nil, fmt.Errorf("given height %v too high", height)
+	}
+	bc.lock.Lock()
+	defer bc.lock.Unlock()
+	return bc.headers[height], nil
+}
+
+func (bc *BlockChain) Height() uint32 {
+	bc.lock.Lock()
+	defer bc.lock.Unlock()
+	return uint32(len(bc.headers) - 1)
+}
+
+func (bc *BlockChain) GetTransaction(hash types.Hash) (Status, *Block, *Transaction, error) {
+	// executed transactions are found from their receipt, genesis transactions have none
+	if receipt, err := bc.store.GetReceipt(hash); err == nil {
+		b, err := bc.GetBlock(receipt.Height)
+		if err != nil {
+			return "", nil, nil, err
+		}
+		return bc.statusOfHeight(b.Height), b, b.Transactions[receipt.Index], nil
+	}
+
+	bc.lock.RLock()
+	blocks := bc.blocks
+	bc.lock.RUnlock()
+	for i := len(blocks) - 1; i >= 0; i-- {
+		b := blocks[i]
+		for _, tx := range b.Transactions {
+			if tx.Hash(TxHasher{}) == hash {
+				return bc.statusOfHeight(b.Height), b, tx, nil
 			}
-			break
 		}
 	}
-	return bc.addBlockWithoutValidation(genesis)
+	return "", nil, nil, ErrTxNotfound
+}
+
+func (bc *BlockChain) statusOfHeight(h uint32) Status {
+	height := bc.Height()
+	if h > height {
+		return StatusPending
+	}
+	if h <= bc.Finalized().Height {
+		return StatusFinalized
+	}
+	if height-h >= bc.params.ConfirmDepth {
+		return StatusConfirmed
+	}
+	return StatusIncluded
+}
+
+// handleNatveTransaction applies the inner transaction of tx executed in the block with header h
+func (bc *BlockChain) handleNatveTransaction(tx *Transaction, h *Header, receipt *Receipt) error {
+	switch tx.TxInner.(type) {
+	case MintTx:
+		if err := bc.handleNativeNFTTransaction(tx, h, receipt); err != nil {
+			return err
+		}
+	case TransferTx:
+		if err := bc.handleNativeTransferTransaction(tx, receipt); err != nil {
+			return err
+		}
+	case NFTTransferTx:
+		if err := bc.handleNFTTransferTransaction(tx, h, receipt); err != nil {
+			return err
+		}
+	case NFTBurnTx:
+		if err := bc.handleNFTBurnTransaction(tx, h, receipt); err != nil {
+			return err
+		}
+	case NFTMetadataUpdateTx:
+		if err := bc.handleNFTMetadataUpdateTransaction(tx, h, receipt); err != nil {
+			return err
+		}
+	case CollectionFreezeTx:
+		if err := bc.handleCollectionFreezeTransaction(tx, receipt); err != nil {
+			return err
+		}
+	case NFTSaleTx:
+		if err := bc.handleNFTSaleTransaction(tx, h, receipt); err != nil {
+			return err
+		}
+	case NFTListTx, NFTCancelListingTx, NFTBuyTx, NFTOfferTx, NFTAcceptOfferTx:
+		if err := bc.handleMarketTransaction(tx, h, receipt); err != nil {
+			return err
+		}
+	case AuctionCreateTx, AuctionBidTx, AuctionSettleTx:
+		if err := bc.handleAuctionTransaction(tx, h, receipt); err != nil {
+			return err
+		}
+	case HTLCLockTx, HTLCClaimTx, HTLCRefundTx:
+		if err := bc.handleHTLCTransaction(tx, h, receipt); err != nil {
+			return err
+		}
+	case MultisigCreateTx:
+		if err := bc.handleMultisigCreateTransaction(tx); err != nil {
+			return err
+		}
+	case BatchTransferTx:
+		if err := bc.handleBatchTransferTransaction(tx, receipt); err != nil {
+			return err
+		}
+	case TokenCreateTx, TokenTransferTx, TokenMintTx, TokenBurnTx:
+		if err := bc.handleTokenTransaction(tx, receipt); err != nil {
+			return err
+		}
+	case VestingTransferTx:
+		if err := bc.handleVestingTransferTransaction(tx, h, receipt); err != nil {
+			return err
+		}
+	case VestingClaimTx:
+		if err := bc.handleVestingClaimTransaction(tx, h, receipt); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (bc *BlockChain) handleNativeNFTTransaction(tx *Transaction, h *Header, receipt *Receipt) error {
+	if err := bc.checkNativeNFTTransaction(tx); err != nil {
+		return err
+	}
+	mintTx := tx.TxInner.(MintTx)
+	owner := mintTx.Owner.Address()
+	switch nft := mintTx.NFT.(type) {
+	case NFTAsset:
+		// logic for mint tx processing should put here
+		state := &NFTState{
+			Hash:       receipt.TxHash,
+			Collection: nft.Collection,
+			Owner:      owner,
+			Metadata:   mintTx.Metadata,
+			Position:   TxCursor{Height: h.Height, Index: receipt.Index},
+			Royalty:    nft.Royalty,
+		}
+		if !nft.Collection.IsZero() {
+			collection, err := bc.store.GetCollectionState(nft.Collection)
+			if err != nil {
+				return err
+			}
+			collection.Minted++
+			state.TokenID = collection.Minted
+			// royalty of the collection cannot be bypassed by its minters
+			if collection.Royalty.Bps != 0 {
+				state.Royalty = collection.Royalty
+			}
+			if err := bc.store.PutCollectionState(collection); err != nil {
+				return err
+			}
+		}
+		if err := bc.store.PutNFT(tx); err != nil {
+			return err
+		}
+		if err := bc.store.PutNFTState(state); err != nil {
+			return err
+		}
+		receipt.addStateChange(StateChange{Kind: StateChangeNFT, Addr: owner, Hash: receipt.TxHash})
+		event := &NFTEvent{Kind: NFTEventMint, TxHash: receipt.TxHash, To: owner, Height: h.Height}
+		if err := bc.store.PutNFTEvent(receipt.TxHash, event); err != nil {
+			return err
+		}
+
+	case NFTCollection:
+		// logic for collection tx processing should put here
+		if err := bc.store.PutCollection(tx); err != nil {
+			return ErrDocExisted
+		}
+		collection := NewCollectionState(receipt.TxHash, owner, nft)
+		collection.Position = TxCursor{Height: h.Height, Index: receipt.Index}
+		if err := bc.store.PutCollectionState(collection); err != nil {
+			return err
+		}
+		receipt.addStateChange(StateChange{Kind: StateChangeCollection, Addr: owner, Hash: receipt.TxHash})
+	default:
+		return errors.New("unknow nft inside")
+	}
+
+	return nil
+}
+
+// handleNFTTransferTransaction gives the nft to the recipient, the sender must own it
+func (bc *BlockChain) handleNFTTransferTransaction(tx *Transaction, h *Header, receipt *Receipt) error {
+	if err := bc.checkNFTTransferTransaction(tx); err != nil {
+		return err
+	}
+	transferTx := tx.TxInner.(NFTTransferTx)
+	state, err := bc.store.GetNFTState(transferTx.NFT)
+	if err != nil {
+		return err
+	}
+	return bc.moveNFT(receipt, state, &NFTEvent{
+		Kind:   NFTEventTransfer,
+		TxHash: receipt.TxHash,
+		From:   tx.Sender(),
+		To:     transferTx.To,
+		Height: h.Height,
+	})
+}
+
+// moveNFT gives the nft to event.To and records the event, the listing of the nft is removed
+func (bc *BlockChain) moveNFT(receipt *Receipt, state *NFTState, event *NFTEvent) error {
+	state.Owner = event.To
+	state.Transfers++
+	if err := bc.store.PutNFTState(state); err != nil {
+		return err
+	}
+	if err := bc.store.DeleteListing(state.Hash); err != nil {
+		return err
+	}
+	receipt.addStateChange(StateChange{Kind: StateChangeNFT, Addr: event.To, Hash: state.Hash})
+	return bc.store.PutNFTEvent(state.Hash, event)
+}
+
+// payNFTSale takes price from the buyer, the royalty of the nft goes to its recipient and the rest to the seller
+func (bc *BlockChain) payNFTSale(receipt *Receipt, state *NFTState, buyer, seller types.Address, price uint64) error {
+	royalty := state.Royalty.Amount(price)
+	if err := bc.debit(receipt, buyer, price); err != nil {
+		return err
+	}
+	if err := bc.credit(receipt, state.Royalty.Recipient, royalty); err != nil {
+		return err
+	}
+	return bc.credit(receipt, seller, price-royalty)
+}
+
+// checkCanPay checks that addr could pay amount on top of fee
+func (bc *BlockChain) checkCanPay(addr types.Address, fee, amount uint64) error {
+	state, err := bc.store.GetAccount(addr)
+	if err != nil {
+		return err
+	}
+	if state.Balance < fee || state.Balance-fee < amount {
+		return ErrTxInsufficientBalance
+	}
+	return nil
+}
+
+func (bc *BlockChain) checkNFTTransferTransaction(tx *Transaction) error {
+	transferTx := tx.TxInner.(NFTTransferTx)
+	return bc.checkNFTOwner(transferTx.NFT, tx.Sender())
+}
+
+// handleNFTBurnTransaction destroys the nft, the sender must own it
+func (bc *BlockChain) handleNFTBurnTransaction(tx *Transaction, h *Header, receipt *Receipt) error {
+	if err := bc.checkNFTBurnTransaction(tx); err != nil {
+		return err
+	}
+	burnTx := tx.TxInner.(NFTBurnTx)
+	state, err := bc.store.GetNFTState(burnTx.NFT)
+	if err != nil {
+		return err
+	}
+	if !state.Collection.IsZero() {
+		collection, err := bc.store.GetCollectionState(state.Collection)
+		if err != nil {
+			return err
+		}
+		collection.Burned++
+		if err := bc.store.PutCollectionState(collection); err != nil {
+			return err
+		}
+	}
+	state.Owner = types.Address{}
+	state.Burned = true
+	if err := bc.store.PutNFTState(state); err != nil {
+		return err
+	}
+	if err := bc.store.DeleteListing(burnTx.NFT); err != nil {
+		return err
+	}
+	receipt.addStateChange(StateChange{Kind: StateChangeNFT, Addr: tx.Sender(), Hash: burnTx.NFT})
+	return bc.store.PutNFTEvent(burnTx.NFT, &NFTEvent{
+		Kind:   NFTEventBurn,
+		TxHash: receipt.TxHash,
+		From:   tx.Sender(),
+		Height: h.Height,
+	})
+}
+
+func (bc *BlockChain) checkNFTBurnTransaction(tx *Transaction) error {
+	burnTx := tx.TxInner.(NFTBurnTx)
+	return bc.checkNFTOwner(burnTx.NFT, tx.Sender())
+}
+
+// handleNFTSaleTransaction moves the nft from the seller to the buyer, the price paid by the buyer is split
+// between the royalty recipient of the nft and the seller
+func (bc *BlockChain) handleNFTSaleTransaction(tx *Transaction, h *Header, receipt *Receipt) error {
+	// the fee is already charged
+	if err := bc.checkNFTSaleTransaction(tx, 0); err != nil {
+		return err
+	}
+	saleTx := tx.TxInner.(NFTSaleTx)
+	state, err := bc.store.GetNFTState(saleTx.NFT)
+	if err != nil {
+		return err
+	}
+	seller := saleTx.Seller.Address()
+	if err := bc.payNFTSale(receipt, state, saleTx.Buyer, seller, saleTx.Price); err != nil {
+		return err
+	}
+	return bc.moveNFT(receipt, state, &NFTEvent{
+		Kind:   NFTEventSale,
+		TxHash: receipt.TxHash,
+		From:   seller,
+		To:     saleTx.Buyer,
+		Height: h.Height,
+		Price:  saleTx.Price,
+	})
+}
+
+// checkNFTSaleTransaction checks that the seller owns the nft and the buyer could pay the price on top of fee
+func (bc *BlockChain) checkNFTSaleTransaction(tx *Transaction, fee uint64) error {
+	saleTx := tx.TxInner.(NFTSaleTx)
+	state, err := bc.store.GetNFTState(saleTx.NFT)
+	if err != nil {
+		return err
+	}
+	if err := bc.checkNFTOwner(saleTx.NFT, saleTx.Seller.Address()); err != nil {
+		return err
+	}
+	if state.Transfers != saleTx.Transfers {
+		return ErrNFTSaleReplayed
+	}
+	return bc.checkCanPay(saleTx.Buyer, fee, saleTx.Price)
+}
+
+// handleMarketTransaction applies the listing, buy and offer transactions of the marketplace, buying and accepting
+// an offer exchange the coins for the nft at once
+func (bc *BlockChain) handleMarketTransaction(tx *Transaction, h *Header, receipt *Receipt) error {
+	// the fee is already charged
+	if err := bc.checkMarketTransaction(tx, 0); err != nil {
+		return err
+	}
+	sender := tx.Sender()
+	position := TxCursor{Height: h.Height, Index: receipt.Index}
+	switch ttx := tx.TxInner.(type) {
+	case NFTListTx:
+		return bc.store.PutListing(&Listing{NFT: ttx.NFT, Seller: sender, Price: ttx.Price, Position: position})
+	case NFTCancelListingTx:
+		return bc.store.DeleteListing(ttx.NFT)
+	case NFTBuyTx:
+		listing, err := bc.store.GetListing(ttx.NFT)
+		if err != nil {
+			return err
+		}
+		return bc.exchangeNFT(receipt, h, ttx.NFT, sender, listing.Seller, ttx.Price)
+	case NFTOfferTx:
+		if ttx.Price == 0 {
+			return bc.store.DeleteOffer(ttx.NFT, sender)
+		}
+		return bc.store.PutOffer(&Offer{NFT: ttx.NFT, Buyer: sender, Price: ttx.Price, Position: position})
+	case NFTAcceptOfferTx:
+		return bc.exchangeNFT(receipt, h, ttx.NFT, ttx.Buyer, sender, ttx.Price)
+	}
+	return nil
+}
+
+// exchangeNFT pays the price from the buyer to the seller and gives the nft to the buyer, the offer of the buyer
+// for the nft is used up
+func (bc *BlockChain) exchangeNFT(receipt *Receipt, h *Header, nft types.Hash, buyer, seller types.Address, price uint64) error {
+	state, err := bc.store.GetNFTState(nft)
+	if err != nil {
+		return err
+	}
+	if err := bc.payNFTSale(receipt, state, buyer, seller, price); err != nil {
+		return err
+	}
+	if err := bc.store.DeleteOffer(nft, buyer); err != nil {
+		return err
+	}
+	return bc.moveNFT(receipt, state, &NFTEvent{
+		Kind:   NFTEventSale,
+		TxHash: receipt.TxHash,
+		From:   seller,
+		To:     buyer,
+		Height: h.Height,
+		Price:  price,
+	})
+}
+
+// checkMarketTransaction checks the marketplace transaction against the current owner of the nft, the listing or
+// offer it refers to, and that the buyer could pay the price on top of fee
+func (bc *BlockChain) checkMarketTransaction(tx *Transaction, fee uint64) error {
+	sender := tx.Sender()
+	switch ttx := tx.TxInner.(type) {
+	case NFTListTx:
+		return bc.checkNFTOwner(ttx.NFT, sender)
+	case NFTCancelListingTx:
+		listing, err := bc.store.GetListing(ttx.NFT)
+		if err != nil {
+			return err
+		}
+		if listing.Seller != sender {
+			return ErrNFTNotOwned
+		}
+	case NFTBuyTx:
+		listing, err := bc.store.GetListing(ttx.NFT)
+		if err != nil {
+			return err
+		}
+		if listing.Price != ttx.Price {
+			return ErrMarketPriceChanged
+		}
+		if listing.Seller == sender {
+			return fmt.Errorf("%w: seller cannot buy its own nft", ErrMarketInvalid)
+		}
+		return bc.checkCanPay(sender, fee, ttx.Price)
+	case NFTOfferTx:
+		if ttx.Price == 0 {
+			_, err := bc.store.GetOffer(ttx.NFT, sender)
+			return err
+		}
+		state, err := bc.store.GetNFTState(ttx.NFT)
+		if err != nil {
+			return err
+		}
+		if state.Burned {
+			return ErrNFTBurned
+		}
+		if state.Owner == sender {
+			return fmt.Errorf("%w: owner cannot make an offer for its own nft", ErrMarketInvalid)
+		}
+		return bc.checkCanPay(sender, fee, ttx.Price)
+	case NFTAcceptOfferTx:
+		if err := bc.checkNFTOwner(ttx.NFT, sender); err != nil {
+			return err
+		}
+		offer, err := bc.store.GetOffer(ttx.NFT, ttx.Buyer)
+		if err != nil {
+			return err
+		}
+		if offer.Price != ttx.Price {
+			return ErrMarketPriceChanged
+		}
+		if ttx.Buyer == sender {
+			return fmt.Errorf("%w: owner cannot accept its own offer", ErrMarketInvalid)
+		}
+		return bc.checkCanPay(ttx.Buyer, 0, ttx.Price)
+	}
+	return nil
+}
+
+// checkNFTOwner checks that the nft is owned by addr and could be moved, it is not burned nor held by an auction
+func (bc *BlockChain) checkNFTOwner(nft types.Hash, addr types.Address) error {
+	state, err := bc.store.GetNFTState(nft)
+	if err != nil {
+		return err
+	}
+	if state.Burned {
+		return ErrNFTBurned
+	}
+	if state.Owner != addr {
+		return ErrNFTNotOwned
+	}
+	if !state.Auction.IsZero() {
+		return ErrNFTInAuction
+	}
+	return nil
+}
+
+// handleAuctionTransaction applies the auction transaction executed in the block with header h. Creating holds
+// the nft in the auction, a bid locks its amount and unlocks the previous highest bid, settling exchanges the
+// highest bid for the nft.
+func (bc *BlockChain) handleAuctionTransaction(tx *Transaction, h *Header, receipt *Receipt) error {
+	// the fee is already charged
+	if err := bc.checkAuctionTransaction(tx, h.Height, 0); err != nil {
+		return err
+	}
+	sender := tx.Sender()
+	switch ttx := tx.TxInner.(type) {
+	case AuctionCreateTx:
+		auction := NewAuction(receipt.TxHash, sender, ttx)
+		auction.Position = TxCursor{Height: h.Height, Index: receipt.Index}
+		state, err := bc.store.GetNFTState(ttx.NFT)
+		if err != nil {
+			return err
+		}
+		state.Auction = auction.Hash
+		if err := bc.store.PutNFTState(state); err != nil {
+			return err
+		}
+		if err := bc.store.DeleteListing(ttx.NFT); err != nil {
+			return err
+		}
+		return bc.store.PutAuction(auction)
+	case AuctionBidTx:
+		auction, err := bc.store.GetAuction(ttx.Auction)
+		if err != nil {
+			return err
+		}
+		// refund the outbid amount first, the bidder could raise its own bid
+		if !auction.Bidder.IsZero() {
+			if err := bc.debitLocked(receipt, auction.Bidder, auction.Hash, auction.Bid); err != nil {
+				return err
+			}
+			if err := bc.credit(receipt, auction.Bidder, auction.Bid); err != nil {
+				return err
+			}
+		}
+		if err := bc.debit(receipt, sender, ttx.Amount); err != nil {
+			return err
+		}
+		if err := bc.creditLocked(receipt, sender, auction.Hash, ttx.Amount); err != nil {
+			return err
+		}
+		auction.Bidder = sender
+		auction.Bid = ttx.Amount
+		return bc.store.PutAuction(auction)
+	case AuctionSettleTx:
+		auction, err := bc.store.GetAuction(ttx.Auction)
+		if err != nil {
+			return err
+		}
+		state, err := bc.store.GetNFTState(auction.NFT)
+		if err != nil {
+			return err
+		}
+		auction.Settled = true
+		if err := bc.store.PutAuction(auction); err != nil {
+			return err
+		}
+		state.Auction = types.Hash{}
+		if auction.Bidder.IsZero() {
+			return bc.store.PutNFTState(state)
+		}
+		if err := bc.debitLocked(receipt, auction.Bidder, auction.Hash, auction.Bid); err != nil {
+			return err
+		}
+		if err := bc.credit(receipt, auction.Bidder, auction.Bid); err != nil {
+			return err
+		}
+		if err := bc.payNFTSale(receipt, state, auction.Bidder, auction.Seller, auction.Bid); err != nil {
+			return err
+		}
+		if err := bc.store.DeleteOffer(auction.NFT, auction.Bidder); err != nil {
+			return err
+		}
+		return bc.moveNFT(receipt, state, &NFTEvent{
+			Kind:   NFTEventSale,
+			TxHash: receipt.TxHash,
+			From:   auction.Seller,
+			To:     auction.Bidder,
+			Height: h.Height,
+			Price:  auction.Bid,
+		})
+	}
+	return nil
+}
+
+// checkAuctionTransaction checks the auction transaction executed at height against the auction and the nft,
+// and that the bidder could lock the bid on top of fee
+func (bc *BlockChain) checkAuctionTransaction(tx *Transaction, height uint32, fee uint64) error {
+	sender := tx.Sender()
+	switch ttx := tx.TxInner.(type) {
+	case AuctionCreateTx:
+		if ttx.EndHeight <= height {
+			return fmt.Errorf("%w: auction must end after the block it is created in", ErrAuctionInvalid)
+		}
+		if ttx.EndHeight-height > maxAuctionBlocks {
+			return fmt.Errorf("%w: auction must end within %d blocks", ErrAuctionInvalid, maxAuctionBlocks)
+		}
+		return bc.checkNFTOwner(ttx.NFT, sender)
+	case AuctionBidTx:
+		auction, err := bc.store.GetAuction(ttx.Auction)
+		if err != nil {
+			return err
+		}
+		if auction.Ended(height) {
+			return ErrAuctionEnded
+		}
+		if auction.Seller == sender {
+			return fmt.Errorf("%w: seller cannot bid on its own auction", ErrAuctionInvalid)
+		}
+		if ttx.Amount < auction.MinBid() {
+			return ErrAuctionBidTooLow
+		}
+		// the bid of the current highest bidder is unlocked before its new bid is locked
+		amount := ttx.Amount
+		if auction.Bidder == sender {
+			amount -= auction.Bid
+		}
+		return bc.checkCanPay(sender, fee, amount)
+	case AuctionSettleTx:
+		auction, err := bc.store.GetAuction(ttx.Auction)
+		if err != nil {
+			return err
+		}
+		if auction.Settled {
+			return ErrAuctionSettled
+		}
+		if !auction.Ended(height) {
+			return ErrAuctionNotEnded
+		}
+	}
+	return nil
+}
+
+// handleNFTMetadataUpdateTransaction replaces the metadata of the nft, the sender must own its mutable collection
+func (bc *BlockChain) handleNFTMetadataUpdateTransaction(tx *Transaction, h *Header, receipt *Receipt) error {
+	if err := bc.checkNFTMetadataUpdateTransaction(tx); err != nil {
+		return err
+	}
+	updateTx := tx.TxInner.(NFTMetadataUpdateTx)
+	state, err := bc.store.GetNFTState(updateTx.NFT)
+	if err != nil {
+		return err
+	}
+	state.Metadata = updateTx.Metadata
+	if err := bc.store.PutNFTState(state); err != nil {
+		return err
+	}
+	receipt.addStateChange(StateChange{Kind: StateChangeNFT, Addr: state.Owner, Hash: updateTx.NFT})
+	return bc.store.PutNFTEvent(updateTx.NFT, &NFTEvent{
+		Kind:     NFTEventMetadata,
+		TxHash:   receipt.TxHash,
+		From:     tx.Sender(),
+		To:       state.Owner,
+		Height:   h.Height,
+		Metadata: updateTx.Metadata,
+	})
+}
+
+// checkNFTMetadataUpdateTransaction checks that the nft is alive and its collection is mutable and owned by the sender,
+// nft without collection are immutable
+func (bc *BlockChain) checkNFTMetadataUpdateTransaction(tx *Transaction) error {
+	updateTx := tx.TxInner.(NFTMetadataUpdateTx)
+	state, err := bc.store.GetNFTState(updateTx.NFT)
+	if err != nil {
+		return err
+	}
+	if state.Burned {
+		return ErrNFTBurned
+	}
+	if state.Collection.IsZero() {
+		return ErrNFTImmutable
+	}
+	collection, err := bc.store.GetCollectionState(state.Collection)
+	if err != nil {
+		return err
+	}
+	if !collection.Mutable {
+		return ErrNFTImmutable
+	}
+	if collection.Owner != tx.Sender() {
+		return ErrNFTNotUpdatable
+	}
+	return nil
+}
+
+// handleCollectionFreezeTransaction makes the collection immutable, the metadata of its nft cannot be updated anymore
+func (bc *BlockChain) handleCollectionFreezeTransaction(tx *Transaction, receipt *Receipt) error {
+	if err := bc.checkCollectionFreezeTransaction(tx); err != nil {
+		return err
+	}
+	freezeTx := tx.TxInner.(CollectionFreezeTx)
+	collection, err := bc.store.GetCollectionState(freezeTx.Collection)
+	if err != nil {
+		return err
+	}
+	collection.Mutable = false
+	if err := bc.store.PutCollectionState(collection); err != nil {
+		return err
+	}
+	receipt.addStateChange(StateChange{Kind: StateChangeCollection, Addr: collection.Owner, Hash: freezeTx.Collection})
+	return nil
+}
+
+// checkCollectionFreezeTransaction checks that the collection is still mutable and owned by the sender
+func (bc *BlockChain) checkCollectionFreezeTransaction(tx *Transaction) error {
+	freezeTx := tx.TxInner.(CollectionFreezeTx)
+	collection, err := bc.store.GetCollectionState(freezeTx.Collection)
+	if err != nil {
+		return err
+	}
+	if collection.Owner != tx.Sender() {
+		return ErrNFTNotUpdatable
+	}
+	if !collection.Mutable {
+		return ErrCollectionFrozen
+	}
+	return nil
+}
+
+// GetNFTOwner returns the current owner of the nft minted by the transaction with hash
+func (bc *BlockChain) GetNFTOwner(hash types.Hash) (types.Address, error) {
+	state, err := bc.store.GetNFTState(hash)
+	if err != nil {
+		return types.Address{}, err
+	}
+	return state.Owner, nil
+}
+
+// GetNFTState returns the owner, collection and token id of the nft minted by the transaction with hash
+func (bc *BlockChain) GetNFTState(hash types.Hash) (*NFTState, error) {
+	return bc.store.GetNFTState(hash)
+}
+
+// GetCollectionState returns the owner, minters and supply of the collection created by the transaction with hash
+func (bc *BlockChain) GetCollectionState(hash types.Hash) (*CollectionState, error) {
+	return bc.store.GetCollectionState(hash)
+}
+
+// GetNFT returns the mint transaction of the nft with hash
+func (bc *BlockChain) GetNFT(hash types.Hash) (*Transaction, error) {
+	return bc.store.GetNFT(hash)
+}
+
+// GetCollection returns the mint transaction of the collection with hash
+func (bc *BlockChain) GetCollection(hash types.Hash) (*Transaction, error) {
+	return bc.store.GetCollection(hash)
+}
+
+// GetNFTsOfOwner returns the page of nft owned by the address, in the order they are minted
+func (bc *BlockChain) GetNFTsOfOwner(addr types.Address, query NFTQuery) (*NFTPage, error) {
+	states, err := bc.store.GetNFTStatesOfOwner(addr)
+	if err != nil {
+		return nil, err
+	}
+	return paginateNFTs(states, query)
+}
+
+// GetNFTsOfCollection returns the page of nft of the collection, in the order of their token ids
+func (bc *BlockChain) GetNFTsOfCollection(hash types.Hash, query NFTQuery) (*NFTPage, error) {
+	states, err := bc.store.GetNFTStatesOfCollection(hash)
+	if err != nil {
+		return nil, err
+	}
+	return paginateNFTs(states, query)
+}
+
+// GetCollections returns the page of collections, in the order they are created
+func (bc *BlockChain) GetCollections(query NFTQuery) (*CollectionPage, error) {
+	states, err := bc.store.GetCollectionStates()
+	if err != nil {
+		return nil, err
+	}
+	collections, next, err := paginateByPosition(states, func(s *CollectionState) TxCursor { return s.Position }, query)
+	if err != nil {
+		return nil, err
+	}
+	return &CollectionPage{Collections: collections, Next: next}, nil
+}
+
+// GetListing returns the active listing of the nft
+func (bc *BlockChain) GetListing(nft types.Hash) (*Listing, error) {
+	return bc.store.GetListing(nft)
+}
+
+// GetListings returns the page of active listings, in the order they are listed
+func (bc *BlockChain) GetListings(query NFTQuery) (*ListingPage, error) {
+	listings, err := bc.store.GetListings()
+	if err != nil {
+		return nil, err
+	}
+	page, next, err := paginateByPosition(listings, func(l *Listing) TxCursor { return l.Position }, query)
+	if err != nil {
+		return nil, err
+	}
+	return &ListingPage{Listings: page, Next: next}, nil
+}
+
+// GetOffersOfNFT returns the offers for the nft, in the order they are made
+func (bc *BlockChain) GetOffersOfNFT(nft types.Hash) ([]*Offer, error) {
+	return bc.store.GetOffersOfNFT(nft)
+}
+
+// GetAuction returns the auction created by the transaction with hash
+func (bc *BlockChain) GetAuction(hash types.Hash) (*Auction, error) {
+	return bc.store.GetAuction(hash)
+}
+
+// GetAuctions returns the page of auctions not settled yet, in the order they are created
+func (bc *BlockChain) GetAuctions(query NFTQuery) (*AuctionPage, error) {
+	auctions, err := bc.store.GetAuctions()
+	if err != nil {
+		return nil, err
+	}
+	active := []*Auction{}
+	for _, auction := range auctions {
+		if !auction.Settled {
+			active = append(active, auction)
+		}
+	}
+	page, next, err := paginateByPosition(active, func(a *Auction) TxCursor { return a.Position }, query)
+	if err != nil {
+		return nil, err
+	}
+	return &AuctionPage{Auctions: page, Next: next}, nil
+}
+
+// GetNFTHistory returns the mint, every transfer and metadata update and the burn of the nft, in chain order
+func (bc *BlockChain) GetNFTHistory(hash types.Hash) ([]*NFTEvent, error) {
+	return bc.store.GetNFTHistory(hash)
+}
+
+func (bc *BlockChain) handleCoinbaseTransaction(tx TransferTx) error {
+	coinbaseAccount := &AccountState{
+		Balance: tx.Value,
+	}
+	return bc.store.PutCoinbase(coinbaseAccount)
+}
+
+func (bc *BlockChain) handleNativeTransferTransaction(tx *Transaction, receipt *Receipt) error {
+	transferTx := tx.TxInner.(TransferTx)
+
+	fromState, err := bc.store.GetAccount(transferTx.From)
+	if err != nil {
+		return err
+	}
+
+	if fromState.Balance < transferTx.Value {
+		return ErrTxInsufficientBalance
+	}
+	if err := bc.store.PutTransfer(tx); err != nil {
+		return err
+	}
+
+	if err := bc.debit(receipt, fromState.Addr, transferTx.Value); err != nil {
+		return err
+	}
+	if err := bc.credit(receipt, transferTx.To, transferTx.Value); err != nil {
+		return err
+	}
+
+	return nil
+}
+
+func (bc *BlockChain) handleMultisigCreateTransaction(tx *Transaction) error {
+	createTx := tx.TxInner.(MultisigCreateTx)
+	if err := bc.store.PutMultisig(createTx.Account); err != nil {
+		return fmt.Errorf("multisig account (%s): %w", createTx.Account.Address(), err)
+	}
+	return nil
+}
+
+// handleBatchTransferTransaction checks the sender covers every output before any balance is changed,
+// so the batch is applied as a whole or not at all
+func (bc *BlockChain) handleBatchTransferTransaction(tx *Transaction, receipt *Receipt) error {
+	batchTx := tx.TxInner.(BatchTransferTx)
+	fromState, err := bc.store.GetAccount(tx.Sender())
+	if err != nil {
+		return err
+	}
+	if fromState.Balance < batchTx.Total() {
+		return ErrTxInsufficientBalance
+	}
+	if err := bc.store.PutTransfer(tx); err != nil {
+		return err
+	}
+
+	if err := bc.debit(receipt, fromState.Addr, batchTx.Total()); err != nil {
+		return err
+	}
+	for _, output := range batchTx.Outputs {
+		if err := bc.credit(receipt, output.To, output.Value); err != nil {
+			return err
+		}
+	}
+	return nil
 }
 
-func (bc *BlockChain) AddBlock(b *Block) error {
-	if err := bc.validator.Validate(b); err != nil {
+// handleTokenTransaction applies the token transaction after checking it against the state, nothing is
+// changed when the check fails
+func (bc *BlockChain) handleTokenTransaction(tx *Transaction, receipt *Receipt) error {
+	if err := bc.checkTokenTransaction(tx); err != nil {
 		return err
 	}
-	var fee uint64 = 0
-	for _, tx := range b.Transactions {
-		// logic of vm put here
-		vm := NewVM(tx.Data, bc.contractState)
-		if err := vm.Run(); err != nil {
+	sender := tx.Sender()
+	switch ttx := tx.TxInner.(type) {
+	case TokenCreateTx:
+		token := NewToken(receipt.TxHash, sender, ttx)
+		if err := bc.store.PutToken(token); err != nil {
 			return err
 		}
-
-		// logic of mintTx put here
-		if err := bc.handleNatveTransaction(tx); err != nil {
+		return bc.creditToken(receipt, sender, token.Hash, ttx.Supply)
+	case TokenTransferTx:
+		if err := bc.debitToken(receipt, sender, ttx.Token, ttx.Amount); err != nil {
 			return err
 		}
-
-		// logic of
-		fee += tx.Fee
+		return bc.creditToken(receipt, ttx.To, ttx.Token, ttx.Amount)
+	case TokenMintTx:
+		token, err := bc.store.GetToken(ttx.Token)
+		if err != nil {
+			return err
+		}
+		token.Supply += ttx.Amount
+		if err := bc.store.PutToken(token); err != nil {
+			return err
+		}
+		return bc.creditToken(receipt, ttx.To, ttx.Token, ttx.Amount)
+	case TokenBurnTx:
+		token, err := bc.store.GetToken(ttx.Token)
+		if err != nil {
+			return err
+		}
+		token.Supply -= ttx.Amount
+		if err := bc.store.PutToken(token); err != nil {
+			return err
+		}
+		return bc.debitToken(receipt, sender, ttx.Token, ttx.Amount)
 	}
+	return nil
+}
 
-	// TODO: should give fee to minter
-	if err := bc.store.UpdateAccountBalance(b.Validator.Address(), int(fee)); err != nil {
+// creditToken adds amount to the token balance of addr and records the change in the receipt
+func (bc *BlockChain) creditToken(receipt *Receipt, addr types.Address, token types.Hash, amount uint64) error {
+	if amount == 0 {
+		return nil
+	}
+	balance := bc.store.GetTokenBalance(addr, token)
+	if balance > math.MaxUint64-amount {
+		return ErrTxBalanceOverflow
+	}
+	if err := bc.store.PutTokenBalance(addr, token, balance+amount); err != nil {
 		return err
 	}
+	receipt.addStateChange(StateChange{Kind: StateChangeToken, Addr: addr, Delta: int64(amount), Hash: token})
+	return nil
+}
 
-	return bc.addBlockWithoutValidation(b)
+// debitToken takes amount from the token balance of addr and records the change in the receipt
+func (bc *BlockChain) debitToken(receipt *Receipt, addr types.Address, token types.Hash, amount uint64) error {
+	if amount == 0 {
+		return nil
+	}
+	balance := bc.store.GetTokenBalance(addr, token)
+	if balance < amount {
+		return ErrTokenInsufficientFunds
+	}
+	if err := bc.store.PutTokenBalance(addr, token, balance-amount); err != nil {
+		return err
+	}
+	receipt.addStateChange(StateChange{Kind: StateChangeToken, Addr: addr, Delta: -int64(amount), Hash: token})
+	return nil
 }
 
-func (bc *BlockChain) addBlockWithoutValidation(b *Block) error {
-	bc.lock.RLock()
-	bc.headers = append(bc.headers, b.Header)
-	bc.blocks = append(bc.blocks, b)
-	bc.lock.RUnlock()
+// GetToken returns the token created by the transaction with hash
+func (bc *BlockChain) GetToken(hash types.Hash) (*Token, error) {
+	return bc.store.GetToken(hash)
+}
 
-	bc.logger.Log(
-		"msg", "new block",
-		"height", b.Height,
-		"hash", fmt.Sprintf("%x", b.Hash(BlockHasher{}).Bytes()[:3]),
-		"transactions", len(b.Transactions),
-	)
-	// fmt.Println(bc.AccountState())
-	return bc.store.PutBlock(b)
+// GetTokenBalance returns the balance of the token held by addr
+func (bc *BlockChain) GetTokenBalance(addr types.Address, token types.Hash) uint64 {
+	return bc.store.GetTokenBalance(addr, token)
 }
 
-func (bc *BlockChain) HasBlock(height uint32) bool {
-	return bc.Height() >= height
+// GetTokenBalancesOfAccount returns every token held by addr with its balance
+func (bc *BlockChain) GetTokenBalancesOfAccount(addr types.Address) (map[types.Hash]uint64, error) {
+	return bc.store.GetTokenBalancesOfAccount(addr)
 }
 
-func (bc *BlockChain) GetBlock(height uint32) (*Block, error) {
-	if !bc.HasBlock(height) {
-		return nil, fmt.Errorf("given height (%d) too high", height)
+// handleVestingTransferTransaction moves the value from the balance of the sender into the locked coins of the recipient,
+// the vesting starts in the block with header h
+func (bc *BlockChain) handleVestingTransferTransaction(tx *Transaction, h *Header, receipt *Receipt) error {
+	// the fee is already charged
+	if err := bc.checkVestingTransferTransaction(tx, h.Height, h.Timestamp, 0); err != nil {
+		return err
 	}
-	bc.lock.Lock()
-	block := bc.blocks[height]
-	bc.lock.Unlock()
-	return block, nil
+	vestingTx := tx.TxInner.(VestingTransferTx)
+	vesting := NewVesting(receipt.TxHash, vestingTx, h.Height)
+	if err := bc.store.PutVesting(vesting); err != nil {
+		return err
+	}
+	if err := bc.debit(receipt, tx.Sender(), vestingTx.Value); err != nil {
+		return err
+	}
+	return bc.creditLocked(receipt, vesting.Beneficiary, vesting.Hash, vestingTx.Value)
 }
 
-func (bc *BlockChain) GetHeader(height uint32) (*Header, error) {
-	if !bc.HasBlock(height) {
-		return nil, fmt.Errorf("given height %v too high", height)
+// handleVestingClaimTransaction unlocks every coin vested until the block with header h
+func (bc *BlockChain) handleVestingClaimTransaction(tx *Transaction, h *Header, receipt *Receipt) error {
+	vestings, err := bc.claimableVestings(tx)
+	if err != nil {
+		return err
+	}
+	claimed := make([]uint64, len(vestings))
+	var total uint64
+	for i, vesting := range vestings {
+		claimed[i] = vesting.Claimable(h.Height, h.Timestamp)
+		total += claimed[i]
+	}
+	if total == 0 {
+		return ErrVestingNotClaimable
 	}
-	bc.lock.Lock()
-	defer bc.lock.Unlock()
-	return bc.headers[height], nil
-}
 
-func (bc *BlockChain) Height() uint32 {
-	bc.lock.Lock()
-	defer bc.lock.Unlock()
-	return uint32(len(bc.headers) - 1)
+	for i, vesting := range vestings {
+		if claimed[i] == 0 {
+			continue
+		}
+		vesting.Claimed += claimed[i]
+		if err := bc.store.PutVesting(vesting); err != nil {
+			return err
+		}
+		if err := bc.debitLocked(receipt, vesting.Beneficiary, vesting.Hash, claimed[i]); err != nil {
+			return err
+		}
+	}
+	return bc.credit(receipt, tx.Sender(), total)
 }
 
-func (bc *BlockChain) GetTransaction(hash types.Hash) (Status, *Block, *Transaction, error) {
-	for i := len(bc.blocks) - 1; i >= 0; i-- {
-		b := bc.blocks[i]
-		for _, tx := range b.Transactions {
-			if tx.Hash(TxHasher{}) == hash {
-				return bc.statusOfHeight(b.Height), b, tx, nil
-			}
+// claimableVestings returns the vestings claimed by tx, they must belong to its sender
+func (bc *BlockChain) claimableVestings(tx *Transaction) ([]*Vesting, error) {
+	claimTx := tx.TxInner.(VestingClaimTx)
+	if len(claimTx.Vestings) == 0 {
+		return bc.store.GetVestingsOfAccount(tx.Sender())
+	}
+	vestings := []*Vesting{}
+	for i, hash := range claimTx.Vestings {
+		if slices.Contains(claimTx.Vestings[:i], hash) {
+			return nil, fmt.Errorf("%w: duplicated vesting (%s)", ErrVestingInvalid, hash)
 		}
+		vesting, err := bc.store.GetVesting(hash)
+		if err != nil {
+			return nil, err
+		}
+		if vesting.Beneficiary != tx.Sender() {
+			return nil, fmt.Errorf("%w: (%s) of sender", ErrVestingNotExisted, hash)
+		}
+		vestings = append(vestings, vesting)
 	}
-	return "", nil, nil, ErrTxNotfound
+	return vestings, nil
 }
 
-func (bc *BlockChain) statusOfHeight(h uint32) Status {
-	if bc.Height()-h > bc.confirmsLevel {
-		return StatusConfirmed
-	}
-	return StatusPending
+// GetVestingsOfAccount returns the vestings of coins locked for addr
+func (bc *BlockChain) GetVestingsOfAccount(addr types.Address) ([]*Vesting, error) {
+	return bc.store.GetVestingsOfAccount(addr)
 }
 
-func (bc *BlockChain) handleNatveTransaction(tx *Transaction) error {
-	fromState, err := bc.store.GetAccount(tx.From.Address())
-	if err != nil {
+// handleHTLCTransaction applies the htlc transaction executed in the block with header h. Locking moves the value
+// from the balance of the sender into its locked coins, claiming pays them to the recipient and refunding gives
+// them back to the sender.
+func (bc *BlockChain) handleHTLCTransaction(tx *Transaction, h *Header, receipt *Receipt) error {
+	// the fee is already charged
+	if err := bc.checkHTLCTransaction(tx, h.Height, 0); err != nil {
 		return err
 	}
-	if fromState.Nonce+1 != tx.Nonce {
-		bc.logger.Log("error", fmt.Sprintf("expected %d, given %d", fromState.Nonce+1, tx.Nonce))
-		return ErrNonceInvalid
-	}
-
-	switch tx.TxInner.(type) {
-	case MintTx:
-		if err := bc.handleNativeNFTTransaction(tx); err != nil {
+	switch ttx := tx.TxInner.(type) {
+	case HTLCLockTx:
+		htlc := NewHTLC(receipt.TxHash, tx.Sender(), ttx)
+		htlc.Position = TxCursor{Height: h.Height, Index: receipt.Index}
+		if err := bc.store.PutHTLC(htlc); err != nil {
 			return err
 		}
-	case TransferTx:
-		if err := bc.handleNativeTransferTransaction(tx); err != nil {
+		if err := bc.debit(receipt, htlc.Sender, htlc.Value); err != nil {
 			return err
 		}
-	default:
-		return nil
+		return bc.creditLocked(receipt, htlc.Sender, htlc.Hash, htlc.Value)
+	case HTLCClaimTx:
+		htlc, err := bc.store.GetHTLC(ttx.HTLC)
+		if err != nil {
+			return err
+		}
+		htlc.Claimed = true
+		htlc.Preimage = ttx.Preimage
+		return bc.closeHTLC(receipt, htlc, htlc.Recipient)
+	case HTLCRefundTx:
+		htlc, err := bc.store.GetHTLC(ttx.HTLC)
+		if err != nil {
+			return err
+		}
+		htlc.Refunded = true
+		return bc.closeHTLC(receipt, htlc, htlc.Sender)
 	}
-	if err := bc.store.IncreaseAccountNonce(tx.From.Address()); err != nil {
+	return nil
+}
+
+// closeHTLC unlocks the value of the htlc from its sender and pays it to addr
+func (bc *BlockChain) closeHTLC(receipt *Receipt, htlc *HTLC, addr types.Address) error {
+	if err := bc.store.PutHTLC(htlc); err != nil {
 		return err
 	}
-	return nil
+	if err := bc.debitLocked(receipt, htlc.Sender, htlc.Hash, htlc.Value); err != nil {
+		return err
+	}
+	return bc.credit(receipt, addr, htlc.Value)
 }
 
-func (bc *BlockChain) handleNativeNFTTransaction(tx *Transaction) error {
-	mintTx := tx.TxInner.(MintTx)
-	switch mintTx.NFT.(type) {
-	case NFTAsset:
-		// logic for mint tx processing should put here
-		if err := bc.store.PutNFT(tx); err != nil {
+// checkHTLCTransaction checks the htlc transaction executed at height against the htlc, and that the sender
+// could lock the value on top of fee
+func (bc *BlockChain) checkHTLCTransaction(tx *Transaction, height uint32, fee uint64) error {
+	switch ttx := tx.TxInner.(type) {
+	case HTLCLockTx:
+		if ttx.TimeoutHeight <= height {
+			return fmt.Errorf("%w: htlc must time out after the block it is locked in", ErrHTLCInvalid)
+		}
+		return bc.checkCanPay(tx.Sender(), fee, ttx.Value)
+	case HTLCClaimTx:
+		htlc, err := bc.store.GetHTLC(ttx.HTLC)
+		if err != nil {
 			return err
 		}
-
-	case NFTCollection:
-		// logic for collection tx processing should put here
-		if err := bc.store.PutCollection(tx); err != nil {
-			return ErrDocExisted
+		if htlc.Closed() {
+			return ErrHTLCClosed
+		}
+		if htlc.Expired(height) {
+			return ErrHTLCExpired
+		}
+		if Hashlock(ttx.Preimage) != htlc.Hashlock {
+			return ErrHTLCPreimageInvalid
+		}
+	case HTLCRefundTx:
+		htlc, err := bc.store.GetHTLC(ttx.HTLC)
+		if err != nil {
+			return err
+		}
+		if htlc.Closed() {
+			return ErrHTLCClosed
+		}
+		if !htlc.Expired(height) {
+			return ErrHTLCNotExpired
 		}
-	default:
-		return errors.New("unknow nft inside")
 	}
-
 	return nil
 }
 
-func (bc *BlockChain) handleCoinbaseTransaction(tx TransferTx) error {
-	coinbaseAccount := &AccountState{
-		Balance: tx.Value,
-	}
-	return bc.store.PutCoinbase(coinbaseAccount)
+// GetHTLC returns the htlc locked by the transaction with hash
+func (bc *BlockChain) GetHTLC(hash types.Hash) (*HTLC, error) {
+	return bc.store.GetHTLC(hash)
 }
 
-func (bc *BlockChain) handleNativeTransferTransaction(tx *Transaction) error {
-	transferTx := tx.TxInner.(TransferTx)
-
-	fromState, err := bc.store.GetAccount(transferTx.From)
-	if err != nil {
-		return err
-	}
-
-	if fromState.Balance < (tx.Fee + transferTx.Value) {
-		return ErrTxInsufficientBalance
-	}
-	if err := bc.store.PutTransfer(tx); err != nil {
-		return err
-	}
+// GetHTLCsOfAccount returns the htlcs sent or received by addr, in the order they are locked
+func (bc *BlockChain) GetHTLCsOfAccount(addr types.Address) ([]*HTLC, error) {
+	return bc.store.GetHTLCsOfAccount(addr)
+}
 
-	fromTotal := -(int(transferTx.Value) + int(tx.Fee))
-	if err := bc.store.UpdateAccountBalance(fromState.Addr, fromTotal); err != nil {
-		return err
+// checkMultisigSender checks that the multisig account sending tx is registered on the chain
+func (bc *BlockChain) checkMultisigSender(tx *Transaction) error {
+	if tx.Multisig == nil {
+		return nil
 	}
-	if err := bc.store.UpdateAccountBalance(transferTx.To, int(transferTx.Value)); err != nil {
-		return err
+	if _, err := bc.store.GetMultisig(tx.Multisig.Address()); err != nil {
+		return fmt.Errorf("%w: (%s)", err, tx.Multisig.Address())
 	}
-
 	return nil
 }
 
+// GetMultisig returns the registered multisig account at addr
+func (bc *BlockChain) GetMultisig(addr types.Address) (*MultisigAccount, error) {
+	return bc.store.GetMultisig(addr)
+}
+
 // SoftcheckTransactions check list of transaction and return list of index of transactions that not pass the soft check
 func (bc *BlockChain) SoftcheckTransactions(txx []*Transaction) []types.Hash {
 	idxx := []types.Hash{}
+	// next nonce of every sender after the transactions of the batch checked so far
+	nonces := map[types.Address]uint64{}
 	for _, tx := range txx {
 		if err := bc.checkgeneralTransaction(tx); err != nil {
 			bc.logger.Log("soft check", err)
 			idxx = append(idxx, tx.Hash(TxHasher{}))
 			continue
 		}
+		next, err := bc.checkNonceInSequence(tx, nonces)
+		if err != nil {
+			bc.logger.Log("soft check nonce", err)
+			idxx = append(idxx, tx.Hash(TxHasher{}))
+			continue
+		}
 
 		if tx.TxInner != nil {
 			switch tx.TxInner.(type) {
@@ -261,30 +1602,162 @@ func (bc *BlockChain) SoftcheckTransactions(txx []*Transaction) []types.Hash {
 					idxx = append(idxx, tx.Hash(TxHasher{}))
 					continue
 				}
+			case NFTTransferTx:
+				if err := bc.checkNFTTransferTransaction(tx); err != nil {
+					bc.logger.Log("soft check nft transfer", err)
+					idxx = append(idxx, tx.Hash(TxHasher{}))
+					continue
+				}
+			case NFTBurnTx:
+				if err := bc.checkNFTBurnTransaction(tx); err != nil {
+					bc.logger.Log("soft check nft burn", err)
+					idxx = append(idxx, tx.Hash(TxHasher{}))
+					continue
+				}
+			case NFTMetadataUpdateTx:
+				if err := bc.checkNFTMetadataUpdateTransaction(tx); err != nil {
+					bc.logger.Log("soft check nft update", err)
+					idxx = append(idxx, tx.Hash(TxHasher{}))
+					continue
+				}
+			case CollectionFreezeTx:
+				if err := bc.checkCollectionFreezeTransaction(tx); err != nil {
+					bc.logger.Log("soft check collection freeze", err)
+					idxx = append(idxx, tx.Hash(TxHasher{}))
+					continue
+				}
+			case NFTSaleTx:
+				if err := bc.checkNFTSaleTransaction(tx, tx.EffectiveFee(bc.NextBaseFee())); err != nil {
+					bc.logger.Log("soft check nft sale", err)
+					idxx = append(idxx, tx.Hash(TxHasher{}))
+					continue
+				}
+			case NFTListTx, NFTCancelListingTx, NFTBuyTx, NFTOfferTx, NFTAcceptOfferTx:
+				if err := bc.checkMarketTransaction(tx, tx.EffectiveFee(bc.NextBaseFee())); err != nil {
+					bc.logger.Log("soft check market", err)
+					idxx = append(idxx, tx.Hash(TxHasher{}))
+					continue
+				}
+			case AuctionCreateTx, AuctionBidTx, AuctionSettleTx:
+				if err := bc.checkAuctionTransaction(tx, bc.Height()+1, tx.EffectiveFee(bc.NextBaseFee())); err != nil {
+					bc.logger.Log("soft check auction", err)
+					idxx = append(idxx, tx.Hash(TxHasher{}))
+					continue
+				}
+			case HTLCLockTx, HTLCClaimTx, HTLCRefundTx:
+				if err := bc.checkHTLCTransaction(tx, bc.Height()+1, tx.EffectiveFee(bc.NextBaseFee())); err != nil {
+					bc.logger.Log("soft check htlc", err)
+					idxx = append(idxx, tx.Hash(TxHasher{}))
+					continue
+				}
+			case BatchTransferTx:
+				if err := bc.checkBatchTransferTransaction(tx); err != nil {
+					bc.logger.Log("soft check batch", err)
+					idxx = append(idxx, tx.Hash(TxHasher{}))
+					continue
+				}
+			case TokenCreateTx, TokenTransferTx, TokenMintTx, TokenBurnTx:
+				if err := bc.checkTokenTransaction(tx); err != nil {
+					bc.logger.Log("soft check token", err)
+					idxx = append(idxx, tx.Hash(TxHasher{}))
+					continue
+				}
+			case VestingTransferTx:
+				if err := bc.checkVestingTransferTransaction(tx, bc.Height()+1, time.Now().UnixNano(), tx.EffectiveFee(bc.NextBaseFee())); err != nil {
+					bc.logger.Log("soft check vesting", err)
+					idxx = append(idxx, tx.Hash(TxHasher{}))
+					continue
+				}
+			case VestingClaimTx:
+				if err := bc.checkVestingClaimTransaction(tx); err != nil {
+					bc.logger.Log("soft check claim", err)
+					idxx = append(idxx, tx.Hash(TxHasher{}))
+					continue
+				}
+			case MultisigCreateTx:
+				createTx := tx.TxInner.(MultisigCreateTx)
+				if _, err := bc.store.GetMultisig(createTx.Account.Address()); err == nil {
+					bc.logger.Log("soft check multisig", ErrDocExisted)
+					idxx = append(idxx, tx.Hash(TxHasher{}))
+					continue
+				}
 			}
 		}
+		if tx.Nonce == next {
+			nonces[tx.Sender()] = next + 1
+		}
 	}
 	return idxx
 }
 
+// checkNonceInSequence returns the nonce the next transaction of the sender of tx must have, after the transactions
+// already counted in nonces. A nonce below it is used already and could never be executed. A nonce above it waits
+// for the missing ones, see NextNonce.
+func (bc *BlockChain) checkNonceInSequence(tx *Transaction, nonces map[types.Address]uint64) (uint64, error) {
+	next, ok := nonces[tx.Sender()]
+	if !ok {
+		var err error
+		if next, err = bc.NextNonce(tx.Sender()); err != nil {
+			return 0, err
+		}
+	}
+	if tx.Nonce < next {
+		return 0, fmt.Errorf("%w: expected at least %d, given %d", ErrNonceInvalid, next, tx.Nonce)
+	}
+	return next, nil
+}
+
+// NextNonce returns the nonce the next transaction sent from addr must have, block producers must include the
+// transactions of a sender in nonce order without gap
+func (bc *BlockChain) NextNonce(addr types.Address) (uint64, error) {
+	state, err := bc.store.GetAccount(addr)
+	if err != nil {
+		return 0, err
+	}
+	return state.Nonce + 1, nil
+}
+
 func (bc *BlockChain) checkgeneralTransaction(tx *Transaction) error {
-	_, err := bc.store.GetAccount(tx.From.Address())
+	if err := tx.VerifyChainID(bc.chainID); err != nil {
+		return err
+	}
+	baseFee := bc.NextBaseFee()
+	if tx.MaxFee < baseFee {
+		return ErrFeeTooLow
+	}
+	if err := bc.checkMultisigSender(tx); err != nil {
+		return err
+	}
+	fromState, err := bc.store.GetAccount(tx.Sender())
 	if err != nil {
 		bc.logger.Log("tx", err)
 		return err
 	}
+	if fromState.Balance < tx.EffectiveFee(baseFee) {
+		return ErrTxInsufficientFee
+	}
 	return nil
 }
 
 func (bc *BlockChain) checkNativeNFTTransaction(tx *Transaction) error {
 	mintTx := tx.TxInner.(MintTx)
 	hash := tx.Hash(TxHasher{})
-	switch mintTx.NFT.(type) {
+	switch nft := mintTx.NFT.(type) {
 	case NFTAsset:
 		// logic for mint tx processing should put here
 		if ok := bc.store.HasNFT(hash); ok {
 			return ErrDocExisted
 		}
+		// nft without collection could be minted by anyone, collection decides who mints into it
+		if !nft.Collection.IsZero() {
+			collection, err := bc.store.GetCollectionState(nft.Collection)
+			if err != nil {
+				return err
+			}
+			if err := collection.CanMint(mintTx.Owner.Address()); err != nil {
+				return err
+			}
+		}
 
 	case NFTCollection:
 		// logic for collection tx processing should put here
@@ -300,20 +1773,78 @@ func (bc *BlockChain) checkNativeNFTTransaction(tx *Transaction) error {
 
 func (bc *BlockChain) checkNativeTransferTransaction(tx *Transaction) error {
 	transferTx := tx.TxInner.(TransferTx)
-	fromState, err := bc.store.GetAccount(transferTx.From)
+	return bc.checkCanPay(transferTx.From, tx.EffectiveFee(bc.NextBaseFee()), transferTx.Value)
+}
+
+func (bc *BlockChain) checkBatchTransferTransaction(tx *Transaction) error {
+	batchTx := tx.TxInner.(BatchTransferTx)
+	return bc.checkCanPay(tx.Sender(), tx.EffectiveFee(bc.NextBaseFee()), batchTx.Total())
+}
+
+// checkTokenTransaction checks the token transaction against the current state
+func (bc *BlockChain) checkTokenTransaction(tx *Transaction) error {
+	sender := tx.Sender()
+	switch ttx := tx.TxInner.(type) {
+	case TokenCreateTx:
+		if _, err := bc.store.GetToken(tx.Hash(TxHasher{})); err == nil {
+			return ErrDocExisted
+		}
+	case TokenTransferTx:
+		if _, err := bc.store.GetToken(ttx.Token); err != nil {
+			return err
+		}
+		if bc.store.GetTokenBalance(sender, ttx.Token) < ttx.Amount {
+			return ErrTokenInsufficientFunds
+		}
+	case TokenMintTx:
+		token, err := bc.store.GetToken(ttx.Token)
+		if err != nil {
+			return err
+		}
+		return token.checkMint(sender, ttx.Amount)
+	case TokenBurnTx:
+		if _, err := bc.store.GetToken(ttx.Token); err != nil {
+			return err
+		}
+		if bc.store.GetTokenBalance(sender, ttx.Token) < ttx.Amount {
+			return ErrTokenInsufficientFunds
+		}
+	}
+	return nil
+}
+
+// checkVestingTransferTransaction checks that the vesting transfer included at height with timestamp locks coins,
+// and that the sender could pay the value on top of fee
+func (bc *BlockChain) checkVestingTransferTransaction(tx *Transaction, height uint32, timestamp int64, fee uint64) error {
+	vestingTx := tx.TxInner.(VestingTransferTx)
+	if NewVesting(types.Hash{}, vestingTx, height).Vested(height, timestamp) == vestingTx.Value {
+		return ErrVestingUnlocked
+	}
+	return bc.checkCanPay(tx.Sender(), fee, vestingTx.Value)
+}
+
+func (bc *BlockChain) checkVestingClaimTransaction(tx *Transaction) error {
+	vestings, err := bc.claimableVestings(tx)
 	if err != nil {
 		return err
 	}
-	if fromState.Balance < (tx.Fee + transferTx.Value) {
-		return ErrTxInsufficientBalance
+	height := bc.Height() + 1
+	now := time.Now().UnixNano()
+	for _, vesting := range vestings {
+		if vesting.Claimable(height, now) > 0 {
+			return nil
+		}
 	}
-	return nil
+	return ErrVestingNotClaimable
 }
 
 func (bc *BlockChain) PutNewAccount(pubKey *crypto.PublicKey) error {
 	state := NewAccountState(pubKey)
 	state.Balance = 1000000 // just for testing
-	return bc.store.PutAccount(state)
+	if err := bc.store.PutAccount(state); err != nil {
+		return err
+	}
+	return bc.updateSupply(state.Balance, 0)
 }
 
 func (bc *BlockChain) AccountState() string {
@@ -328,6 +1859,11 @@ func (bc *BlockChain) GetAccountState(addr types.Address) (*AccountState, error)
 	return state, nil
 }
 
+// GetAccountStates returns every account of the chain, in no particular order
+func (bc *BlockChain) GetAccountStates() ([]*AccountState, error) {
+	return bc.store.GetAccounts()
+}
+
 func (bc *BlockChain) GetAccountTransferTransactions(addr types.Address) ([]*Transaction, []*Transaction, error) {
 	fromTxx, toTxx, err := bc.store.GetTransferOfAccount(addr)
 	if err != nil {
@@ -335,3 +1871,17 @@ func (bc *BlockChain) GetAccountTransferTransactions(addr types.Address) ([]*Tra
 	}
 	return fromTxx, toTxx, nil
 }
+
+// GetReceipt returns the receipt of the transaction executed in the chain
+func (bc *BlockChain) GetReceipt(hash types.Hash) (*Receipt, error) {
+	return bc.store.GetReceipt(hash)
+}
+
+// GetAccountTransactions returns the page of transactions the address took part in, ordered by height
+func (bc *BlockChain) GetAccountTransactions(addr types.Address, query AccountTxQuery) (*AccountTxPage, error) {
+	txx, err := bc.store.GetAccountTxs(addr)
+	if err != nil {
+		return nil, err
+	}
+	return paginateAccountTxs(txx, query)
+}
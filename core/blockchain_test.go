@@ -4,6 +4,7 @@ import (
 	"blocker/crypto"
 	"blocker/types"
 	"fmt"
+	"math"
 	"testing"
 	"time"
 
@@ -36,17 +37,28 @@ func TestSendInsuffienceTransfer(t *testing.T) {
 	}
 	assert.Nil(t, transferTx.Sign(privBob))
 
-	tx := NewNativeTransferTransaction(transferTx)
-	tx.Nonce = 1
+	tx := NewNativeTransferTransaction(transferTx, 1)
 	assert.Nil(t, tx.Sign(privBob))
 
 	newBlock.AddTransaction(tx)
 	assert.Nil(t, newBlock.ReHash(BlockHasher{}))
 	assert.Nil(t, newBlock.Sign(validator))
 
-	err = bc.AddBlock(newBlock)
-	assert.NotNil(t, err)
-	assert.Equal(t, ErrTxInsufficientBalance, err)
+	// the block is applied, the transaction failed and only used its nonce
+	assert.Nil(t, bc.AddBlock(newBlock))
+	receipt, err := bc.GetReceipt(tx.Hash(TxHasher{}))
+	assert.Nil(t, err)
+	assert.False(t, receipt.Succeeded())
+	assert.Equal(t, ErrTxInsufficientBalance.Error(), receipt.Err)
+	assert.Equal(t, uint64(0), receipt.FeeCharged)
+	assert.Equal(t, []StateChange{{Kind: StateChangeNonce, Addr: privBob.Public().Address(), Delta: 1}}, receipt.StateChanges)
+
+	bobState, err := bc.GetAccountState(privBob.Public().Address())
+	assert.Nil(t, err)
+	assert.Equal(t, uint64(1), bobState.Nonce)
+	aliceState, err := bc.GetAccountState(priveAlice.Public().Address())
+	assert.Nil(t, err)
+	assert.Equal(t, uint64(0), aliceState.Balance)
 	fmt.Println(bc.store.AccountStateString())
 }
 
@@ -77,9 +89,9 @@ func TestSendSuccessTransfer(t *testing.T) {
 	}
 	assert.Nil(t, transferTx.Sign(privBob))
 
-	tx := NewNativeTransferTransaction(transferTx)
-	tx.Fee = 200
-	tx.Nonce = 1
+	tx := NewNativeTransferTransaction(transferTx, 1)
+	tx.MaxFee = 200
+	tx.TipCap = 200
 	assert.Nil(t, tx.Sign(privBob))
 
 	newBlock.AddTransaction(tx)
@@ -88,6 +100,19 @@ func TestSendSuccessTransfer(t *testing.T) {
 
 	assert.Nil(t, bc.AddBlock(newBlock))
 
+	receipt, err := bc.GetReceipt(tx.Hash(TxHasher{}))
+	assert.Nil(t, err)
+	assert.True(t, receipt.Succeeded())
+	assert.Equal(t, newBlock.Hash(BlockHasher{}), receipt.BlockHash)
+	assert.Equal(t, uint32(1), receipt.Height)
+	assert.Equal(t, uint64(200), receipt.FeeCharged)
+	assert.Equal(t, []StateChange{
+		{Kind: StateChangeBalance, Addr: privBob.Public().Address(), Delta: -200},
+		{Kind: StateChangeBalance, Addr: privBob.Public().Address(), Delta: -100},
+		{Kind: StateChangeBalance, Addr: priveAlice.Public().Address(), Delta: 100},
+		{Kind: StateChangeNonce, Addr: privBob.Public().Address(), Delta: 1},
+	}, receipt.StateChanges)
+
 	fmt.Println(bc.store.AccountStateString())
 }
 
@@ -193,7 +218,7 @@ func newGenesisBlock() *Block {
 		To:    types.Address{},
 		Value: 1000000,
 	}
-	tx := NewNativeTransferTransaction(transferTx)
+	tx := NewNativeTransferTransaction(transferTx, 0)
 
 	block := &Block{
 		Header: &Header{
@@ -220,3 +245,234 @@ func newBlockChainWithGenesis(t *testing.T) *BlockChain {
 	assert.Equal(t, bc.Height(), uint32(0))
 	return bc
 }
+
+func TestBlockTimestampRules(t *testing.T) {
+	bc := newBlockChainWithGenesis(t)
+	validator := crypto.GeneratePrivateKey()
+	block := RandomBlock(t, 1, getPrevBlockHash(t, bc, 0))
+	assert.Nil(t, bc.AddBlock(block))
+
+	// same timestamp as the parent
+	next := RandomBlock(t, 2, getPrevBlockHash(t, bc, 1))
+	next.Timestamp = block.Timestamp
+	assert.Nil(t, next.ReHash(BlockHasher{}))
+	assert.Nil(t, next.Sign(validator))
+	assert.NotNil(t, bc.AddBlock(next))
+
+	// too far in the future
+	next.Timestamp = time.Now().Add(MaxBlockTimeDrift + time.Minute).UnixNano()
+	assert.Nil(t, next.ReHash(BlockHasher{}))
+	assert.Nil(t, next.Sign(validator))
+	assert.NotNil(t, bc.AddBlock(next))
+
+	next.Timestamp = block.Timestamp + 1
+	assert.Nil(t, next.ReHash(BlockHasher{}))
+	assert.Nil(t, next.Sign(validator))
+	assert.Nil(t, bc.AddBlock(next))
+}
+
+func TestBlockWithTxOutsideValidity(t *testing.T) {
+	bc := newBlockChainWithGenesis(t)
+	validator := crypto.GeneratePrivateKey()
+	privBob := crypto.GeneratePrivateKey()
+
+	block := RandomBlock(t, 1, getPrevBlockHash(t, bc, 0))
+	windows := []struct {
+		validFrom, validUntil int64
+		err                   error
+	}{
+		{block.Timestamp + 1, 0, ErrTxNotYetValid},
+		{0, block.Timestamp - 1, ErrTxExpired},
+		{block.Timestamp, block.Timestamp, nil},
+	}
+	for _, w := range windows {
+		tx := NewNativeTransaction([]byte{}, 1)
+		tx.ValidFrom = w.validFrom
+		tx.ValidUntil = w.validUntil
+		assert.Nil(t, tx.Sign(privBob))
+		assert.Equal(t, w.err, tx.ValidAt(block.Timestamp))
+
+		block.Transactions = []*Transaction{tx}
+		assert.Nil(t, block.ReHash(BlockHasher{}))
+		assert.Nil(t, block.Sign(validator))
+		if w.err != nil {
+			assert.ErrorIs(t, bc.AddBlock(block), w.err)
+		} else {
+			assert.Nil(t, bc.AddBlock(block))
+		}
+	}
+}
+
+func TestTransactionStatusAndFinality(t *testing.T) {
+	cfg := newTestGenesisConfig()
+	cfg.Params.ConfirmDepth = 2
+	cfg.Params.FinalityDepth = 4
+	cfg.Params.InitialBaseFee = 0
+	bc, err := NewBlockChainFromGenesis(cfg, NewInMemoryStorage(), log.NewNopLogger())
+	assert.Nil(t, err)
+	assert.Equal(t, Checkpoint{Height: 0, Hash: bc.GenesisHash()}, bc.Finalized())
+
+	tx := NewNativeTransaction([]byte{}, 1)
+	tx.ChainID = bc.ChainID()
+	assert.Nil(t, tx.Sign(crypto.GeneratePrivateKey()))
+
+	statuses := []Status{StatusIncluded, StatusIncluded, StatusConfirmed, StatusConfirmed, StatusFinalized}
+	for i, expected := range statuses {
+		height := uint32(i + 1)
+		block := RandomBlock(t, height, getPrevBlockHash(t, bc, height-1))
+		block.BaseFee = bc.NextBaseFee()
+		if height == 1 {
+			block.AddTransaction(tx)
+		}
+		assert.Nil(t, block.ReHash(BlockHasher{}))
+		assert.Nil(t, block.Sign(crypto.GeneratePrivateKey()))
+		assert.Nil(t, bc.AddBlock(block))
+
+		status, b, found, err := bc.GetTransaction(tx.Hash(TxHasher{}))
+		assert.Nil(t, err)
+		assert.Equal(t, uint32(1), b.Height)
+		assert.Equal(t, tx.Hash(TxHasher{}), found.Hash(TxHasher{}))
+		assert.Equal(t, expected, status, "height %d", height)
+	}
+	assert.Equal(t, uint32(1), bc.Finalized().Height)
+	assert.Equal(t, getPrevBlockHash(t, bc, 1), bc.Finalized().Hash)
+
+	// competing block at a finalized height is rejected, the finalized block itself only exists already
+	block := RandomBlock(t, 1, getPrevBlockHash(t, bc, 0))
+	assert.ErrorIs(t, bc.CheckFinalized(block.Header), ErrBlockFinalized)
+	assert.ErrorIs(t, bc.AddBlock(block), ErrBlockFinalized)
+	finalized, err := bc.GetBlock(1)
+	assert.Nil(t, err)
+	assert.Nil(t, bc.CheckFinalized(finalized.Header))
+	err = bc.AddBlock(finalized)
+	assert.NotNil(t, err)
+	assert.NotErrorIs(t, err, ErrBlockFinalized)
+	// blocks above the checkpoint could still be competing
+	assert.Nil(t, bc.CheckFinalized(RandomBlock(t, 2, getPrevBlockHash(t, bc, 1)).Header))
+
+	// genesis transactions have no receipt
+	status, _, _, err := bc.GetTransaction(bc.blocks[0].Transactions[0].Hash(TxHasher{}))
+	assert.Nil(t, err)
+	assert.Equal(t, StatusFinalized, status)
+}
+
+func TestSoftcheckNonceSequence(t *testing.T) {
+	privBob := crypto.GeneratePrivateKey()
+	bob := privBob.Public().Address()
+	cfg := newTestGenesisConfig()
+	cfg.Alloc[bob.String()] = 1000
+	bc, err := NewBlockChainFromGenesis(cfg, NewInMemoryStorage(), log.NewNopLogger())
+	assert.Nil(t, err)
+	newTx := func(nonce uint64, data byte) *Transaction {
+		tx := NewNativeTransaction([]byte{data}, nonce)
+		tx.ChainID = bc.ChainID()
+		tx.MaxFee = bc.NextBaseFee()
+		assert.Nil(t, tx.Sign(privBob))
+		return tx
+	}
+
+	next, err := bc.NextNonce(bob)
+	assert.Nil(t, err)
+	assert.Equal(t, uint64(1), next)
+
+	// used and duplicated nonces are denied, a gap waits for the missing nonce
+	first := newTx(1, 0x01)
+	duplicate := newTx(1, 0x02)
+	gapped := newTx(3, 0x03)
+	second := newTx(2, 0x04)
+	used := newTx(0, 0x05)
+	denied := bc.SoftcheckTransactions([]*Transaction{first, duplicate, gapped, second, used})
+	assert.Equal(t, []types.Hash{duplicate.Hash(TxHasher{}), used.Hash(TxHasher{})}, denied)
+}
+
+func TestFailedTransactionIsReverted(t *testing.T) {
+	privBob := crypto.GeneratePrivateKey()
+	privAlice := crypto.GeneratePrivateKey()
+	validator := crypto.GeneratePrivateKey()
+	bob := privBob.Public().Address()
+	cfg := newTestGenesisConfig()
+	cfg.Alloc[bob.String()] = 1000
+	bc, err := NewBlockChainFromGenesis(cfg, NewInMemoryStorage(), log.NewNopLogger())
+	assert.Nil(t, err)
+
+	// the contract stores 3 under taD before the transfer fails
+	transferTx := TransferTx{From: bob, To: privAlice.Public().Address(), Value: 5000}
+	assert.Nil(t, transferTx.Sign(privBob))
+	tx := NewNativeTransferTransaction(transferTx, 1)
+	tx.Data = []byte{0x44, 0x0b, 0x61, 0x0b, 0x74, 0x0b, 0x03, 0x0a, 0x0d, 0x03, 0x0a, 0x0f}
+	tx.ChainID = bc.ChainID()
+	tx.MaxFee = bc.NextBaseFee()
+	assert.Nil(t, tx.Sign(privBob))
+
+	block := RandomBlock(t, 1, getPrevBlockHash(t, bc, 0))
+	block.BaseFee = bc.NextBaseFee()
+	block.AddTransaction(tx)
+	assert.Nil(t, block.ReHash(BlockHasher{}))
+	assert.Nil(t, block.Sign(validator))
+	assert.Nil(t, bc.AddBlock(block))
+
+	// only the fee and the nonce are kept
+	receipt, err := bc.GetReceipt(tx.Hash(TxHasher{}))
+	assert.Nil(t, err)
+	assert.Equal(t, ErrTxInsufficientBalance.Error(), receipt.Err)
+	assert.Equal(t, uint64(len(tx.Data)), receipt.GasUsed)
+	assert.Empty(t, receipt.Logs)
+	assert.Equal(t, []StateChange{
+		{Kind: StateChangeBalance, Addr: bob, Delta: -int64(receipt.FeeCharged)},
+		{Kind: StateChangeNonce, Addr: bob, Delta: 1},
+	}, receipt.StateChanges)
+	_, err = bc.store.ContractState().Get("taD")
+	assert.ErrorIs(t, err, ErrStateNotExsited)
+	_, err = bc.store.GetTransfer(tx.Hash(TxHasher{}))
+	assert.ErrorIs(t, err, ErrDocNotExisted)
+	bobState, err := bc.GetAccountState(bob)
+	assert.Nil(t, err)
+	assert.Equal(t, 1000-receipt.FeeCharged, bobState.Balance)
+	assert.Equal(t, uint64(1), bobState.Nonce)
+	assertSupplyInvariant(t, bc)
+}
+
+func TestRejectedBlockIsReverted(t *testing.T) {
+	privBob := crypto.GeneratePrivateKey()
+	privAlice := crypto.GeneratePrivateKey()
+	bob := privBob.Public().Address()
+	cfg := newTestGenesisConfig()
+	cfg.Alloc[bob.String()] = 1000
+	cfg.Alloc[privAlice.Public().Address().String()] = 1000
+	bc := newTestChain(t, cfg)
+	before := bc.account(bob)
+	supply := bc.Supply()
+
+	// the second transaction skips a nonce, the first one must not be applied
+	transferTx := TransferTx{From: bob, To: privAlice.Public().Address(), Value: 100}
+	assert.Nil(t, transferTx.Sign(privBob))
+	first := bc.newTx(privBob, transferTx)
+	gapped := &Transaction{Nonce: 5, ChainID: bc.ChainID(), MaxFee: bc.NextBaseFee()}
+	assert.Nil(t, gapped.Sign(privAlice))
+	block := bc.newBlock(first, gapped)
+	assert.ErrorIs(t, bc.AddBlock(block), ErrNonceInvalid)
+
+	assert.Equal(t, uint32(0), bc.Height())
+	assert.Equal(t, before, bc.account(bob))
+	assert.Equal(t, supply, bc.Supply())
+	_, err := bc.GetReceipt(first.Hash(TxHasher{}))
+	assert.ErrorIs(t, err, ErrDocNotExisted)
+	_, err = bc.store.GetTransfer(first.Hash(TxHasher{}))
+	assert.ErrorIs(t, err, ErrDocNotExisted)
+	assertSupplyInvariant(t, bc.BlockChain)
+}
+
+func TestSoftcheckTransferOverflow(t *testing.T) {
+	privBob := crypto.GeneratePrivateKey()
+	bob := privBob.Public().Address()
+	cfg := newTestGenesisConfig()
+	cfg.Alloc[bob.String()] = 1000
+	bc := newTestChain(t, cfg)
+
+	// the fee plus the value wraps around, the transfer must not pass as affordable
+	transferTx := TransferTx{From: bob, To: crypto.GeneratePrivateKey().Public().Address(), Value: math.MaxUint64}
+	assert.Nil(t, transferTx.Sign(privBob))
+	tx := bc.newTx(privBob, transferTx)
+	assert.NotZero(t, tx.EffectiveFee(bc.NextBaseFee()))
+	assert.Equal(t, 1, len(bc.SoftcheckTransactions([]*Transaction{tx})))
+}
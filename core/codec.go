@@ -0,0 +1,593 @@
+package core
+
+import (
+	"blocker/crypto"
+	"blocker/serialize"
+	"blocker/types"
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+)
+
+/*
+Canonical binary codec of the chain, every hash and signature is computed over it.
+
+Primitives (see serialize.Writer):
+  - uint8, uint32, uint64, int64: fixed size, little endian
+  - bytes, string, public key, signature: uint32 length prefix then the value, nil is written as length 0
+  - hash (32 bytes), address (20 bytes): written as is
+
+Header:
+	version uint32 | prev_block_hash hash | data_hash hash | height uint32 | timestamp int64 | base_fee uint64
+
+Block:
+	header | validator pubkey | signature | tx_count uint32 | transaction...
+
+Transaction (signing bytes, see Transaction.Bytes):
+	chain_id string | from pubkey | nonce uint64 | max_fee uint64 | tip_cap uint64 | valid_from int64 | valid_until int64 |
+	data bytes | inner_tag uint8 | inner... | multisig bytes(multisig account), empty when sent by from
+
+Transaction (encoding): signing bytes | signature | sig_count uint32 | (signer_index uint32 | signature)...
+
+Multisig account:
+	threshold uint32 | signer_count uint32 | signer pubkey...
+
+Inner transaction, by tag:
+	0x00 none
+	0x01 transfer: bytes(from address | to address | value uint64) | signer pubkey | signature
+	0x02 mint:     bytes(nft_kind uint8 | nft... | metadata bytes) | owner pubkey | signature
+	               nft_kind 0x00 none, 0x01 collection: type string | max_supply uint64 | minter_count uint32 | minter address... | mutable bool | royalty,
+	               0x02 asset: type string | data bytes | collection hash | royalty
+	               royalty: bps uint32 | recipient address
+	0x03 multisig: bytes(multisig account)
+	0x04 vesting:  bytes(to address | value uint64 | unlock_height uint32 | unlock_time int64 | vesting_blocks uint32)
+	0x05 claim:    bytes(count uint32 | vesting hash...)
+	0x06 batch:    bytes(count uint32 | (to address | value uint64)...)
+	0x07 token create:   bytes(name string | symbol string | decimals uint8 | supply uint64 | mint_authority address)
+	0x08 token transfer: bytes(token hash | to address | amount uint64)
+	0x09 token mint:     bytes(token hash | to address | amount uint64)
+	0x0a token burn:     bytes(token hash | amount uint64)
+	0x0b nft transfer:   bytes(nft hash | to address)
+	0x0c nft burn:       bytes(nft hash)
+	0x0d nft update:     bytes(nft hash | metadata bytes)
+	0x0e nft sale:       bytes(nft hash | buyer address | price uint64 | transfers uint64) | seller pubkey | signature
+	0x0f nft list:       bytes(nft hash | price uint64)
+	0x10 nft unlist:     bytes(nft hash)
+	0x11 nft buy:        bytes(nft hash | price uint64)
+	0x12 nft offer:      bytes(nft hash | price uint64)
+	0x13 nft accept:     bytes(nft hash | buyer address | price uint64)
+	0x14 auction create: bytes(nft hash | reserve_price uint64 | end_height uint32)
+	0x15 auction bid:    bytes(auction hash | amount uint64)
+	0x16 auction settle: bytes(auction hash)
+	0x17 htlc lock:      bytes(to address | value uint64 | hashlock hash | timeout_height uint32)
+	0x18 htlc claim:     bytes(htlc hash | preimage bytes)
+	0x19 htlc refund:    bytes(htlc hash)
+	0x1a nft freeze:     bytes(collection hash)
+*/
+
+var ErrCodecInvalid = errors.New("codec: invalid encoding")
+
+// tags of the inner transaction in the canonical encoding
+const (
+	txInnerNone     uint8 = 0x00
+	txInnerTransfer uint8 = 0x01
+	txInnerMint     uint8 = 0x02
+	txInnerMultisig uint8 = 0x03
+	txInnerVesting  uint8 = 0x04
+	txInnerClaim    uint8 = 0x05
+	txInnerBatch    uint8 = 0x06
+
+	txInnerTokenCreate   uint8 = 0x07
+	txInnerTokenTransfer uint8 = 0x08
+	txInnerTokenMint     uint8 = 0x09
+	txInnerTokenBurn     uint8 = 0x0a
+	txInnerNFTTransfer   uint8 = 0x0b
+	txInnerNFTBurn       uint8 = 0x0c
+	txInnerNFTUpdate     uint8 = 0x0d
+	txInnerNFTSale       uint8 = 0x0e
+	txInnerNFTList       uint8 = 0x0f
+	txInnerNFTUnlist     uint8 = 0x10
+	txInnerNFTBuy        uint8 = 0x11
+	txInnerNFTOffer      uint8 = 0x12
+	txInnerNFTAccept     uint8 = 0x13
+	txInnerAuctionCreate uint8 = 0x14
+	txInnerAuctionBid    uint8 = 0x15
+	txInnerAuctionSettle uint8 = 0x16
+	txInnerHTLCLock      uint8 = 0x17
+	txInnerHTLCClaim     uint8 = 0x18
+	txInnerHTLCRefund    uint8 = 0x19
+	txInnerNFTFreeze     uint8 = 0x1a
+)
+
+func writeTxInner(w *serialize.Writer, inner any) {
+	switch txInner := inner.(type) {
+	case TransferTx:
+		w.WriteUint8(txInnerTransfer)
+		w.WriteBytes(txInner.Bytes())
+		writePublicKey(w, txInner.Signer)
+		writeSignature(w, txInner.Signature)
+	case MintTx:
+		w.WriteUint8(txInnerMint)
+		w.WriteBytes(txInner.Bytes())
+		writePublicKey(w, txInner.Owner)
+		writeSignature(w, txInner.Signature)
+	case MultisigCreateTx:
+		w.WriteUint8(txInnerMultisig)
+		w.WriteBytes(txInner.Bytes())
+	case VestingTransferTx:
+		w.WriteUint8(txInnerVesting)
+		w.WriteBytes(txInner.Bytes())
+	case VestingClaimTx:
+		w.WriteUint8(txInnerClaim)
+		w.WriteBytes(txInner.Bytes())
+	case BatchTransferTx:
+		w.WriteUint8(txInnerBatch)
+		w.WriteBytes(txInner.Bytes())
+	case TokenCreateTx:
+		w.WriteUint8(txInnerTokenCreate)
+		w.WriteBytes(txInner.Bytes())
+	case TokenTransferTx:
+		w.WriteUint8(txInnerTokenTransfer)
+		w.WriteBytes(txInner.Bytes())
+	case TokenMintTx:
+		w.WriteUint8(txInnerTokenMint)
+		w.WriteBytes(txInner.Bytes())
+	case TokenBurnTx:
+		w.WriteUint8(txInnerTokenBurn)
+		w.WriteBytes(txInner.Bytes())
+	case NFTTransferTx:
+		w.WriteUint8(txInnerNFTTransfer)
+		w.WriteBytes(txInner.Bytes())
+	case NFTBurnTx:
+		w.WriteUint8(txInnerNFTBurn)
+		w.WriteBytes(txInner.Bytes())
+	case NFTMetadataUpdateTx:
+		w.WriteUint8(txInnerNFTUpdate)
+		w.WriteBytes(txInner.Bytes())
+	case CollectionFreezeTx:
+		w.WriteUint8(txInnerNFTFreeze)
+		w.WriteBytes(txInner.Bytes())
+	case NFTSaleTx:
+		w.WriteUint8(txInnerNFTSale)
+		w.WriteBytes(txInner.Bytes())
+		writePublicKey(w, txInner.Seller)
+		writeSignature(w, txInner.Signature)
+	case NFTListTx:
+		w.WriteUint8(txInnerNFTList)
+		w.WriteBytes(txInner.Bytes())
+	case NFTCancelListingTx:
+		w.WriteUint8(txInnerNFTUnlist)
+		w.WriteBytes(txInner.Bytes())
+	case NFTBuyTx:
+		w.WriteUint8(txInnerNFTBuy)
+		w.WriteBytes(txInner.Bytes())
+	case NFTOfferTx:
+		w.WriteUint8(txInnerNFTOffer)
+		w.WriteBytes(txInner.Bytes())
+	case NFTAcceptOfferTx:
+		w.WriteUint8(txInnerNFTAccept)
+		w.WriteBytes(txInner.Bytes())
+	case AuctionCreateTx:
+		w.WriteUint8(txInnerAuctionCreate)
+		w.WriteBytes(txInner.Bytes())
+	case AuctionBidTx:
+		w.WriteUint8(txInnerAuctionBid)
+		w.WriteBytes(txInner.Bytes())
+	case AuctionSettleTx:
+		w.WriteUint8(txInnerAuctionSettle)
+		w.WriteBytes(txInner.Bytes())
+	case HTLCLockTx:
+		w.WriteUint8(txInnerHTLCLock)
+		w.WriteBytes(txInner.Bytes())
+	case HTLCClaimTx:
+		w.WriteUint8(txInnerHTLCClaim)
+		w.WriteBytes(txInner.Bytes())
+	case HTLCRefundTx:
+		w.WriteUint8(txInnerHTLCRefund)
+		w.WriteBytes(txInner.Bytes())
+	default:
+		w.WriteUint8(txInnerNone)
+	}
+}
+
+func readTxInner(r *serialize.Reader) (any, error) {
+	tag := r.ReadUint8()
+	if tag == txInnerNone {
+		return nil, r.Err()
+	}
+	payload := serialize.NewReader(bytes.NewReader(r.ReadBytes()))
+	var inner any
+	switch tag {
+	case txInnerTransfer:
+		inner = TransferTx{
+			From:      types.AddressFromBytes(payload.ReadFixed(len(types.Address{}))),
+			To:        types.AddressFromBytes(payload.ReadFixed(len(types.Address{}))),
+			Value:     payload.ReadUint64(),
+			Signer:    readPublicKey(r),
+			Signature: readSignature(r),
+		}
+	case txInnerMint:
+		mintTx := MintTx{}
+		switch kind := payload.ReadUint8(); kind {
+		case nftKindNone:
+		case nftKindCollection:
+			collection := NFTCollection{
+				Type:      NFTCollectionType(payload.ReadString()),
+				MaxSupply: payload.ReadUint64(),
+			}
+			count := payload.ReadUint32()
+			for i := uint32(0); i < count && payload.Err() == nil; i++ {
+				collection.Minters = append(collection.Minters, types.AddressFromBytes(payload.ReadFixed(len(types.Address{}))))
+			}
+			collection.Mutable = payload.ReadBool()
+			collection.Royalty = readRoyalty(payload)
+			mintTx.NFT = collection
+		case nftKindAsset:
+			mintTx.NFT = NFTAsset{
+				Type:       NFTAssetType(payload.ReadString()),
+				Data:       payload.ReadBytes(),
+				Collection: types.HashFromBytes(payload.ReadFixed(len(types.Hash{}))),
+				Royalty:    readRoyalty(payload),
+			}
+		default:
+			return nil, fmt.Errorf("%w: unknown nft kind (%d)", ErrCodecInvalid, kind)
+		}
+		mintTx.Metadata = payload.ReadBytes()
+		mintTx.Owner = readPublicKey(r)
+		mintTx.Signature = readSignature(r)
+		inner = mintTx
+	case txInnerMultisig:
+		account, err := readMultisigAccount(payload)
+		if err != nil {
+			return nil, err
+		}
+		inner = MultisigCreateTx{Account: account}
+	case txInnerVesting:
+		inner = VestingTransferTx{
+			To:            types.AddressFromBytes(payload.ReadFixed(len(types.Address{}))),
+			Value:         payload.ReadUint64(),
+			UnlockHeight:  payload.ReadUint32(),
+			UnlockTime:    payload.ReadInt64(),
+			VestingBlocks: payload.ReadUint32(),
+		}
+	case txInnerClaim:
+		claimTx := VestingClaimTx{}
+		count := payload.ReadUint32()
+		for i := uint32(0); i < count && payload.Err() == nil; i++ {
+			claimTx.Vestings = append(claimTx.Vestings, types.HashFromBytes(payload.ReadFixed(len(types.Hash{}))))
+		}
+		inner = claimTx
+	case txInnerBatch:
+		batchTx := BatchTransferTx{}
+		count := payload.ReadUint32()
+		for i := uint32(0); i < count && payload.Err() == nil; i++ {
+			batchTx.Outputs = append(batchTx.Outputs, TransferOutput{
+				To:    types.AddressFromBytes(payload.ReadFixed(len(types.Address{}))),
+				Value: payload.ReadUint64(),
+			})
+		}
+		inner = batchTx
+	case txInnerTokenCreate:
+		inner = TokenCreateTx{
+			Name:          payload.ReadString(),
+			Symbol:        payload.ReadString(),
+			Decimals:      payload.ReadUint8(),
+			Supply:        payload.ReadUint64(),
+			MintAuthority: types.AddressFromBytes(payload.ReadFixed(len(types.Address{}))),
+		}
+	case txInnerTokenTransfer:
+		inner = TokenTransferTx{
+			Token:  types.HashFromBytes(payload.ReadFixed(len(types.Hash{}))),
+			To:     types.AddressFromBytes(payload.ReadFixed(len(types.Address{}))),
+			Amount: payload.ReadUint64(),
+		}
+	case txInnerTokenMint:
+		inner = TokenMintTx{
+			Token:  types.HashFromBytes(payload.ReadFixed(len(types.Hash{}))),
+			To:     types.AddressFromBytes(payload.ReadFixed(len(types.Address{}))),
+			Amount: payload.ReadUint64(),
+		}
+	case txInnerTokenBurn:
+		inner = TokenBurnTx{
+			Token:  types.HashFromBytes(payload.ReadFixed(len(types.Hash{}))),
+			Amount: payload.ReadUint64(),
+		}
+	case txInnerNFTTransfer:
+		inner = NFTTransferTx{
+			NFT: types.HashFromBytes(payload.ReadFixed(len(types.Hash{}))),
+			To:  types.AddressFromBytes(payload.ReadFixed(len(types.Address{}))),
+		}
+	case txInnerNFTBurn:
+		inner = NFTBurnTx{NFT: types.HashFromBytes(payload.ReadFixed(len(types.Hash{})))}
+	case txInnerNFTUpdate:
+		inner = NFTMetadataUpdateTx{
+			NFT:      types.HashFromBytes(payload.ReadFixed(len(types.Hash{}))),
+			Metadata: payload.ReadBytes(),
+		}
+	case txInnerNFTFreeze:
+		inner = CollectionFreezeTx{Collection: types.HashFromBytes(payload.ReadFixed(len(types.Hash{})))}
+	case txInnerNFTSale:
+		inner = NFTSaleTx{
+			NFT:       types.HashFromBytes(payload.ReadFixed(len(types.Hash{}))),
+			Buyer:     types.AddressFromBytes(payload.ReadFixed(len(types.Address{}))),
+			Price:     payload.ReadUint64(),
+			Transfers: payload.ReadUint64(),
+			Seller:    readPublicKey(r),
+			Signature: readSignature(r),
+		}
+	case txInnerNFTList:
+		inner = NFTListTx{
+			NFT:   types.HashFromBytes(payload.ReadFixed(len(types.Hash{}))),
+			Price: payload.ReadUint64(),
+		}
+	case txInnerNFTUnlist:
+		inner = NFTCancelListingTx{NFT: types.HashFromBytes(payload.ReadFixed(len(types.Hash{})))}
+	case txInnerNFTBuy:
+		inner = NFTBuyTx{
+			NFT:   types.HashFromBytes(payload.ReadFixed(len(types.Hash{}))),
+			Price: payload.ReadUint64(),
+		}
+	case txInnerNFTOffer:
+		inner = NFTOfferTx{
+			NFT:   types.HashFromBytes(payload.ReadFixed(len(types.Hash{}))),
+			Price: payload.ReadUint64(),
+		}
+	case txInnerNFTAccept:
+		inner = NFTAcceptOfferTx{
+			NFT:   types.HashFromBytes(payload.ReadFixed(len(types.Hash{}))),
+			Buyer: types.AddressFromBytes(payload.ReadFixed(len(types.Address{}))),
+			Price: payload.ReadUint64(),
+		}
+	case txInnerAuctionCreate:
+		inner = AuctionCreateTx{
+			NFT:          types.HashFromBytes(payload.ReadFixed(len(types.Hash{}))),
+			ReservePrice: payload.ReadUint64(),
+			EndHeight:    payload.ReadUint32(),
+		}
+	case txInnerAuctionBid:
+		inner = AuctionBidTx{
+			Auction: types.HashFromBytes(payload.ReadFixed(len(types.Hash{}))),
+			Amount:  payload.ReadUint64(),
+		}
+	case txInnerAuctionSettle:
+		inner = AuctionSettleTx{Auction: types.HashFromBytes(payload.ReadFixed(len(types.Hash{})))}
+	case txInnerHTLCLock:
+		inner = HTLCLockTx{
+			To:            types.AddressFromBytes(payload.ReadFixed(len(types.Address{}))),
+			Value:         payload.ReadUint64(),
+			Hashlock:      types.HashFromBytes(payload.ReadFixed(len(types.Hash{}))),
+			TimeoutHeight: payload.ReadUint32(),
+		}
+	case txInnerHTLCClaim:
+		inner = HTLCClaimTx{
+			HTLC:     types.HashFromBytes(payload.ReadFixed(len(types.Hash{}))),
+			Preimage: payload.ReadBytes(),
+		}
+	case txInnerHTLCRefund:
+		inner = HTLCRefundTx{HTLC: types.HashFromBytes(payload.ReadFixed(len(types.Hash{})))}
+	default:
+		return nil, fmt.Errorf("%w: unknown inner transaction tag (%d)", ErrCodecInvalid, tag)
+	}
+	if err := payload.Err(); err != nil {
+		return nil, fmt.Errorf("%w: %s", ErrCodecInvalid, err.Error())
+	}
+	return inner, r.Err()
+}
+
+func writeMultisigAccount(w *serialize.Writer, m *MultisigAccount) {
+	if m == nil {
+		w.WriteBytes(nil)
+		return
+	}
+	w.WriteBytes(m.Bytes())
+}
+
+func readMultisigAccount(r *serialize.Reader) (MultisigAccount, error) {
+	m := MultisigAccount{Threshold: r.ReadUint32()}
+	count := r.ReadUint32()
+	if count > maxMultisigSigners {
+		return MultisigAccount{}, fmt.Errorf("%w: too many signers (%d)", ErrCodecInvalid, count)
+	}
+	for i := uint32(0); i < count && r.Err() == nil; i++ {
+		m.Signers = append(m.Signers, readPublicKey(r))
+	}
+	return m, nil
+}
+
+func readPublicKey(r *serialize.Reader) *crypto.PublicKey {
+	b := r.ReadBytes()
+	if len(b) == 0 {
+		return nil
+	}
+	return &crypto.PublicKey{Key: b}
+}
+
+func readSignature(r *serialize.Reader) *crypto.Signature {
+	b := r.ReadBytes()
+	if len(b) == 0 {
+		return nil
+	}
+	return &crypto.Signature{Value: b}
+}
+
+func writeHeader(w *serialize.Writer, h *Header) {
+	w.WriteUint32(h.Version)
+	w.WriteFixed(h.PrevBlockHash.Bytes())
+	w.WriteFixed(h.DataHash.Bytes())
+	w.WriteUint32(h.Height)
+	w.WriteInt64(h.Timestamp)
+	w.WriteUint64(h.BaseFee)
+}
+
+func readHeader(r *serialize.Reader) (*Header, error) {
+	h := &Header{
+		Version:       r.ReadUint32(),
+		PrevBlockHash: types.HashFromBytes(r.ReadFixed(len(types.Hash{}))),
+		DataHash:      types.HashFromBytes(r.ReadFixed(len(types.Hash{}))),
+		Height:        r.ReadUint32(),
+		Timestamp:     r.ReadInt64(),
+		BaseFee:       r.ReadUint64(),
+	}
+	return h, r.Err()
+}
+
+// HeaderFromBytes decodes the header from its canonical encoding
+func HeaderFromBytes(b []byte) (*Header, error) {
+	return readHeader(serialize.NewReader(bytes.NewReader(b)))
+}
+
+func writeTransaction(w *serialize.Writer, tx *Transaction) {
+	w.WriteFixed(tx.Bytes())
+	writeSignature(w, tx.Signature)
+	w.WriteUint32(uint32(len(tx.Signatures)))
+	for _, sig := range tx.Signatures {
+		w.WriteUint32(sig.Index)
+		writeSignature(w, sig.Signature)
+	}
+}
+
+func readTransaction(r *serialize.Reader) (*Transaction, error) {
+	tx := &Transaction{
+		ChainID:    r.ReadString(),
+		From:       readPublicKey(r),
+		Nonce:      r.ReadUint64(),
+		MaxFee:     r.ReadUint64(),
+		TipCap:     r.ReadUint64(),
+		ValidFrom:  r.ReadInt64(),
+		ValidUntil: r.ReadInt64(),
+		Data:       r.ReadBytes(),
+	}
+	inner, err := readTxInner(r)
+	if err != nil {
+		return nil, err
+	}
+	tx.TxInner = inner
+	if b := r.ReadBytes(); len(b) > 0 {
+		payload := serialize.NewReader(bytes.NewReader(b))
+		account, err := readMultisigAccount(payload)
+		if err != nil {
+			return nil, err
+		}
+		if err := payload.Err(); err != nil {
+			return nil, fmt.Errorf("%w: %s", ErrCodecInvalid, err.Error())
+		}
+		tx.Multisig = &account
+	}
+	tx.Signature = readSignature(r)
+	count := r.ReadUint32()
+	if count > maxMultisigSigners {
+		return nil, fmt.Errorf("%w: too many signatures (%d)", ErrCodecInvalid, count)
+	}
+	for i := uint32(0); i < count && r.Err() == nil; i++ {
+		tx.Signatures = append(tx.Signatures, MultisigSignature{
+			Index:     r.ReadUint32(),
+			Signature: readSignature(r),
+		})
+	}
+	return tx, r.Err()
+}
+
+func writeBlock(w *serialize.Writer, b *Block) {
+	writeHeader(w, b.Header)
+	writePublicKey(w, b.Validator)
+	writeSignature(w, b.Signature)
+	w.WriteUint32(uint32(len(b.Transactions)))
+	for _, tx := range b.Transactions {
+		writeTransaction(w, tx)
+	}
+}
+
+func readBlock(r *serialize.Reader) (*Block, error) {
+	h, err := readHeader(r)
+	if err != nil {
+		return nil, err
+	}
+	b := &Block{
+		Header:       h,
+		Validator:    readPublicKey(r),
+		Signature:    readSignature(r),
+		Transactions: []*Transaction{},
+	}
+	count := r.ReadUint32()
+	for i := uint32(0); i < count && r.Err() == nil; i++ {
+		tx, err := readTransaction(r)
+		if err != nil {
+			return nil, err
+		}
+		b.Transactions = append(b.Transactions, tx)
+	}
+	return b, r.Err()
+}
+
+type BinaryTxEncoder struct {
+	w io.Writer
+}
+
+func NewBinaryTxEncoder(w io.Writer) *BinaryTxEncoder {
+	return &BinaryTxEncoder{
+		w: w,
+	}
+}
+
+func (enc *BinaryTxEncoder) Encode(tx *Transaction) error {
+	w := serialize.NewWriter()
+	writeTransaction(w, tx)
+	_, err := enc.w.Write(w.Bytes())
+	return err
+}
+
+type BinaryTxDecoder struct {
+	r io.Reader
+}
+
+func NewBinaryTxDecoder(r io.Reader) *BinaryTxDecoder {
+	return &BinaryTxDecoder{
+		r: r,
+	}
+}
+
+func (dec *BinaryTxDecoder) Decode(tx *Transaction) error {
+	decoded, err := readTransaction(serialize.NewReader(dec.r))
+	if err != nil {
+		return err
+	}
+	*tx = *decoded
+	return nil
+}
+
+type BinaryBlockEncoder struct {
+	w io.Writer
+}
+
+func NewBinaryBlockEncoder(w io.Writer) *BinaryBlockEncoder {
+	return &BinaryBlockEncoder{
+		w: w,
+	}
+}
+
+func (enc *BinaryBlockEncoder) Encode(b *Block) error {
+	w := serialize.NewWriter()
+	writeBlock(w, b)
+	_, err := enc.w.Write(w.Bytes())
+	return err
+}
+
+type BinaryBlockDecoder struct {
+	r io.Reader
+}
+
+func NewBinaryBlockDecoder(r io.Reader) *BinaryBlockDecoder {
+	return &BinaryBlockDecoder{
+		r: r,
+	}
+}
+
+func (dec *BinaryBlockDecoder) Decode(b *Block) error {
+	decoded, err := readBlock(serialize.NewReader(dec.r))
+	if err != nil {
+		return err
+	}
+	*b = *decoded
+	return nil
+}
@@ -0,0 +1,157 @@
+package core
+
+import (
+	"blocker/crypto"
+	"blocker/serialize"
+	"blocker/types"
+	"bytes"
+	"encoding/hex"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+const (
+	vectorHeaderHex  = "01000000010000000000000000000000000000000000000000000000000000000000000002000000000000000000000000000000000000000000000000000000000000000a00000000002a36fe9c97170700000000000000"
+	vectorHeaderHash = "10aac72c59f466c25565539de8f0c4be90a0c5cdd29a49f308df71c8390a8dd0"
+)
+
+func TestHeaderCodecVector(t *testing.T) {
+	h := &Header{
+		Version:       1,
+		PrevBlockHash: types.Hash{0x01},
+		DataHash:      types.Hash{0x02},
+		Height:        10,
+		Timestamp:     1700000000000000000,
+		BaseFee:       7,
+	}
+	assert.Equal(t, vectorHeaderHex, hex.EncodeToString(h.Bytes()))
+	assert.Equal(t, vectorHeaderHash, BlockHasher{}.Hash(h).String())
+
+	decoded, err := HeaderFromBytes(h.Bytes())
+	assert.Nil(t, err)
+	assert.Equal(t, h, decoded)
+
+	_, err = HeaderFromBytes(h.Bytes()[:10])
+	assert.NotNil(t, err)
+}
+
+func TestTransactionCodec(t *testing.T) {
+	_, transferTx := vectorTransaction(t)
+
+	priv := crypto.GeneratePrivateKey()
+	mintTx := MintTx{
+		NFT: NFTAsset{
+			Type:       NFTAssetTypeImageBase64,
+			Data:       []byte("nft"),
+			Collection: types.RandomHash(),
+		},
+		Metadata: []byte("meta"),
+	}
+	assert.Nil(t, mintTx.Sign(priv))
+	collectionTx := MintTx{NFT: NFTCollection{Type: NFTCollectionTypeImage, MaxSupply: 10, Minters: []types.Address{{0x01}, {0x02}}, Mutable: true, Royalty: Royalty{Bps: 500, Recipient: types.Address{0x04}}}}
+	assert.Nil(t, collectionTx.Sign(priv))
+
+	saleTx := NFTSaleTx{NFT: types.RandomHash(), Buyer: priv.Public().Address(), Price: 100, Transfers: 2}
+	assert.Nil(t, saleTx.Sign(crypto.GeneratePrivateKey()))
+
+	txx := []*Transaction{
+		transferTx,
+		{TxInner: mintTx, Nonce: 1, ChainID: "blocker-test"},
+		{TxInner: collectionTx, Nonce: 2},
+		{Data: []byte{0x01, 0x0a}, Nonce: 3, MaxFee: 10, TipCap: 2},
+	}
+	cosigner := crypto.GeneratePrivateKey()
+	account := MultisigAccount{Threshold: 1, Signers: []*crypto.PublicKey{priv.Public(), cosigner.Public()}}
+	txx = append(txx, NewMultisigCreateTransaction(account, 4))
+	multisigTx := NewMultisigTransaction(account, TransferTx{From: account.Address(), To: crypto.GeneratePrivateKey().Public().Address(), Value: 5}, 1)
+	assert.Nil(t, multisigTx.SignMultisig(cosigner))
+	txx = append(txx, multisigTx)
+	txx = append(txx,
+		NewVestingTransferTransaction(VestingTransferTx{To: account.Address(), Value: 9, UnlockHeight: 3, UnlockTime: 7, VestingBlocks: 10}, 5),
+		NewVestingClaimTransaction(6, types.RandomHash(), types.RandomHash()),
+		NewTokenTransaction(TokenCreateTx{Name: "Loyalty", Symbol: "LOY", Decimals: 2, Supply: 1000, MintAuthority: account.Address()}, 8),
+		NewTokenTransaction(TokenTransferTx{Token: types.RandomHash(), To: account.Address(), Amount: 3}, 9),
+		NewTokenTransaction(TokenMintTx{Token: types.RandomHash(), To: account.Address(), Amount: 4}, 10),
+		NewTokenTransaction(TokenBurnTx{Token: types.RandomHash(), Amount: 5}, 11),
+		NewNFTTransferTransaction(types.RandomHash(), account.Address(), 12),
+		NewNFTBurnTransaction(types.RandomHash(), 13),
+		NewNFTMetadataUpdateTransaction(types.RandomHash(), []byte("meta"), 14),
+		NewCollectionFreezeTransaction(types.RandomHash(), 27),
+		NewNFTSaleTransaction(saleTx, 15),
+		NewMarketTransaction(NFTListTx{NFT: types.RandomHash(), Price: 10}, 16),
+		NewMarketTransaction(NFTCancelListingTx{NFT: types.RandomHash()}, 17),
+		NewMarketTransaction(NFTBuyTx{NFT: types.RandomHash(), Price: 10}, 18),
+		NewMarketTransaction(NFTOfferTx{NFT: types.RandomHash(), Price: 7}, 19),
+		NewMarketTransaction(NFTAcceptOfferTx{NFT: types.RandomHash(), Buyer: account.Address(), Price: 7}, 20),
+		NewAuctionTransaction(AuctionCreateTx{NFT: types.RandomHash(), ReservePrice: 50, EndHeight: 100}, 21),
+		NewAuctionTransaction(AuctionBidTx{Auction: types.RandomHash(), Amount: 60}, 22),
+		NewAuctionTransaction(AuctionSettleTx{Auction: types.RandomHash()}, 23),
+		NewHTLCTransaction(HTLCLockTx{To: account.Address(), Value: 30, Hashlock: Hashlock([]byte("secret")), TimeoutHeight: 40}, 24),
+		NewHTLCTransaction(HTLCClaimTx{HTLC: types.RandomHash(), Preimage: []byte("secret")}, 25),
+		NewHTLCTransaction(HTLCRefundTx{HTLC: types.RandomHash()}, 26),
+		NewBatchTransferTransaction([]TransferOutput{{To: account.Address(), Value: 1}, {To: types.Address{0x01}, Value: 2}}, 7),
+	)
+	for _, tx := range txx {
+		if tx.Signature == nil && tx.Multisig == nil {
+			assert.Nil(t, tx.Sign(priv))
+		}
+		buf := &bytes.Buffer{}
+		assert.Nil(t, tx.Encode(NewBinaryTxEncoder(buf)))
+		decoded := new(Transaction)
+		assert.Nil(t, decoded.Decode(NewBinaryTxDecoder(buf)))
+
+		assert.Equal(t, tx.Bytes(), decoded.Bytes())
+		assert.Equal(t, tx.Hash(TxHasher{}), decoded.Hash(TxHasher{}))
+		assert.Equal(t, tx.TxInner, decoded.TxInner)
+		assert.Equal(t, tx.Signatures, decoded.Signatures)
+		assert.Nil(t, decoded.Verify())
+	}
+}
+
+func TestBlockCodec(t *testing.T) {
+	_, tx := vectorTransaction(t)
+	block := RandomBlock(t, 7, types.RandomHash())
+	block.AddTransaction(tx)
+	block.AddTransaction(RandomTxWithSignature(t))
+	assert.Nil(t, block.ReHash(BlockHasher{}))
+	assert.Nil(t, block.Sign(crypto.GeneratePrivateKey()))
+
+	buf := &bytes.Buffer{}
+	assert.Nil(t, block.Encode(NewBinaryBlockEncoder(buf)))
+	decoded := new(Block)
+	assert.Nil(t, decoded.Decode(NewBinaryBlockDecoder(buf)))
+
+	assert.Equal(t, block.Header, decoded.Header)
+	assert.Equal(t, block.Hash(BlockHasher{}), decoded.Hash(BlockHasher{}))
+	assert.Equal(t, len(block.Transactions), len(decoded.Transactions))
+	assert.Nil(t, decoded.Verify())
+}
+
+func TestCodecInvalidInnerTag(t *testing.T) {
+	_, tx := vectorTransaction(t)
+	b := tx.Bytes()
+	// inner tag is right after the data of the vector transaction
+	idx := bytes.Index(b, []byte{0x02, 0x00, 0x00, 0x00, 0x01, 0x02}) + 6
+	b[idx] = 0xff
+	decoded := new(Transaction)
+	assert.ErrorIs(t, decoded.Decode(NewBinaryTxDecoder(bytes.NewReader(b))), ErrCodecInvalid)
+}
+
+func TestCodecRejectsInvalidInner(t *testing.T) {
+	signers := []*crypto.PublicKey{}
+	for i := 0; i <= maxMultisigSigners; i++ {
+		signers = append(signers, crypto.GeneratePrivateKey().Public())
+	}
+	tx := NewMultisigCreateTransaction(MultisigAccount{Threshold: 1, Signers: signers}, 1)
+	assert.Nil(t, tx.Sign(crypto.GeneratePrivateKey()))
+	buf := &bytes.Buffer{}
+	assert.Nil(t, tx.Encode(NewBinaryTxEncoder(buf)))
+	assert.ErrorIs(t, new(Transaction).Decode(NewBinaryTxDecoder(buf)), ErrCodecInvalid)
+
+	w := serialize.NewWriter()
+	w.WriteUint8(txInnerMint)
+	w.WriteBytes([]byte{0x07})
+	_, err := readTxInner(serialize.NewReader(bytes.NewReader(w.Bytes())))
+	assert.ErrorIs(t, err, ErrCodecInvalid)
+}
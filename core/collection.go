@@ -0,0 +1,67 @@
+package core
+
+import (
+	"blocker/types"
+	"errors"
+	"slices"
+)
+
+var (
+	ErrCollectionInvalid       = errors.New("collection is invalid")
+	ErrCollectionNotExisted    = errors.New("collection not existed")
+	ErrCollectionNotAuthorized = errors.New("minter is not authorized by the collection")
+	ErrCollectionSoldOut       = errors.New("collection reached its max supply")
+)
+
+const maxCollectionMinters = 64
+
+// CollectionState is the state of a collection, it is identified by the hash of its mint transaction
+type CollectionState struct {
+	Hash      types.Hash
+	Owner     types.Address
+	Minters   []types.Address
+	MaxSupply uint64 // zero is unlimited
+	Minted    uint64 // token id of the last nft minted into the collection
+	Burned    uint64
+	Mutable   bool
+	Position  TxCursor // position of the mint transaction in the chain
+	Royalty   Royalty
+}
+
+func NewCollectionState(hash types.Hash, owner types.Address, collection NFTCollection) *CollectionState {
+	return &CollectionState{
+		Hash:      hash,
+		Owner:     owner,
+		Minters:   collection.Minters,
+		MaxSupply: collection.MaxSupply,
+		Mutable:   collection.Mutable,
+		Royalty:   collection.Royalty,
+	}
+}
+
+// CanMint checks that minter could mint the next nft into the collection
+func (c *CollectionState) CanMint(minter types.Address) error {
+	if minter != c.Owner && !slices.Contains(c.Minters, minter) {
+		return ErrCollectionNotAuthorized
+	}
+	if c.MaxSupply != 0 && c.Minted >= c.MaxSupply {
+		return ErrCollectionSoldOut
+	}
+	return nil
+}
+
+// NFTState is the state of an nft, it is identified by the hash of its mint transaction. Nft minted
+// into a collection get sequential token ids from 1, nft without collection have token id 0.
+// Burned nft keep their state with zero owner.
+type NFTState struct {
+	Hash       types.Hash
+	Collection types.Hash
+	TokenID    uint64
+	Owner      types.Address
+	Metadata   []byte
+	Burned     bool
+	Position   TxCursor // position of the mint transaction in the chain
+	Royalty    Royalty
+	Transfers  uint64     // times the nft changed owner
+	Auction    types.Hash // auction holding the nft, the owner cannot move it until the auction is settled
+}
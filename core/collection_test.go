@@ -0,0 +1,92 @@
+package core
+
+import (
+	"blocker/crypto"
+	"blocker/types"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCollectionValidate(t *testing.T) {
+	minter := crypto.GeneratePrivateKey().Public().Address()
+	assert.Nil(t, (&NFTCollection{Minters: []types.Address{minter}}).Validate())
+	assert.ErrorIs(t, (&NFTCollection{Minters: []types.Address{minter, minter}}).Validate(), ErrCollectionInvalid)
+	assert.ErrorIs(t, (&NFTCollection{Minters: []types.Address{{}}}).Validate(), ErrCollectionInvalid)
+}
+
+func TestCollectionMintAuthority(t *testing.T) {
+	privOwner := crypto.GeneratePrivateKey()
+	privMinter := crypto.GeneratePrivateKey()
+	privStranger := crypto.GeneratePrivateKey()
+	cfg := newTestGenesisConfig()
+	for _, priv := range []*crypto.PrivateKey{privOwner, privMinter, privStranger} {
+		cfg.Alloc[priv.Public().Address().String()] = 1000
+	}
+	bc := newTestChain(t, cfg)
+
+	newMint := func(priv *crypto.PrivateKey, nft any) *Transaction {
+		return bc.newTx(priv, signMint(t, priv, MintTx{NFT: nft}))
+	}
+	asset := func(collection types.Hash, data string) NFTAsset {
+		return NFTAsset{Type: NFTAssetTypeImageURL, Data: []byte(data), Collection: collection}
+	}
+
+	create := newMint(privOwner, NFTCollection{
+		Type:      NFTCollectionTypeImage,
+		MaxSupply: 3,
+		Minters:   []types.Address{privMinter.Public().Address()},
+	})
+	bc.addBlock(create)
+	collectionHash := create.Hash(TxHasher{})
+	collection, err := bc.GetCollectionState(collectionHash)
+	assert.Nil(t, err)
+	assert.Equal(t, privOwner.Public().Address(), collection.Owner)
+
+	byOwner := newMint(privOwner, asset(collectionHash, "1"))
+	byMinter := newMint(privMinter, asset(collectionHash, "2"))
+	byStranger := newMint(privStranger, asset(collectionHash, "3"))
+	unknown := newMint(privStranger, asset(types.RandomHash(), "4"))
+	assert.Equal(t, 2, len(bc.SoftcheckTransactions([]*Transaction{byOwner, byMinter, byStranger, unknown})))
+	receipts := bc.addBlock(byOwner, byMinter, byStranger, unknown)
+	assert.True(t, receipts[0].Succeeded())
+	assert.True(t, receipts[1].Succeeded())
+	assert.Equal(t, ErrCollectionNotAuthorized.Error(), receipts[2].Err)
+	assert.Equal(t, ErrCollectionNotExisted.Error(), receipts[3].Err)
+	assert.False(t, bc.store.HasNFT(byStranger.Hash(TxHasher{})))
+
+	// token ids are sequential in the collection
+	for i, tx := range []*Transaction{byOwner, byMinter} {
+		state, err := bc.GetNFTState(tx.Hash(TxHasher{}))
+		assert.Nil(t, err)
+		assert.Equal(t, uint64(i+1), state.TokenID)
+		assert.Equal(t, collectionHash, state.Collection)
+	}
+
+	// a signed mint is bound to the transaction of its minter, others cannot replay it
+	replay := bc.newTx(crypto.GeneratePrivateKey(), byMinter.TxInner)
+	assert.ErrorIs(t, replay.Verify(), ErrSigInvalid)
+	assert.Equal(t, 1, len(bc.SoftcheckTransactions([]*Transaction{replay})))
+	assert.ErrorIs(t, bc.AddBlock(bc.newBlock(replay)), ErrSigInvalid)
+	collection, err = bc.GetCollectionState(collectionHash)
+	assert.Nil(t, err)
+	assert.Equal(t, uint64(2), collection.Minted)
+
+	// max supply is 3
+	last := newMint(privOwner, asset(collectionHash, "5"))
+	soldOut := newMint(privMinter, asset(collectionHash, "6"))
+	receipts = bc.addBlock(last, soldOut)
+	assert.True(t, receipts[0].Succeeded())
+	assert.Equal(t, ErrCollectionSoldOut.Error(), receipts[1].Err)
+	collection, err = bc.GetCollectionState(collectionHash)
+	assert.Nil(t, err)
+	assert.Equal(t, uint64(3), collection.Minted)
+
+	// nft without collection has no token id
+	standalone := newMint(privStranger, asset(types.Hash{}, "7"))
+	receipts = bc.addBlock(standalone)
+	assert.True(t, receipts[0].Succeeded())
+	state, err := bc.GetNFTState(standalone.Hash(TxHasher{}))
+	assert.Nil(t, err)
+	assert.Equal(t, uint64(0), state.TokenID)
+}
@@ -1,6 +1,8 @@
 package core
 
 import (
+	"blocker/crypto"
+	"blocker/serialize"
 	"encoding/gob"
 	"io"
 )
@@ -13,6 +15,24 @@ type Decoder[T any] interface {
 	Decode(T) error
 }
 
+// writePublicKey writes the key as length prefixed bytes, nil key is written as empty bytes
+func writePublicKey(w *serialize.Writer, key *crypto.PublicKey) {
+	if key == nil {
+		w.WriteBytes(nil)
+		return
+	}
+	w.WriteBytes(key.Bytes())
+}
+
+// writeSignature writes the signature as length prefixed bytes, nil signature is written as empty bytes
+func writeSignature(w *serialize.Writer, sig *crypto.Signature) {
+	if sig == nil {
+		w.WriteBytes(nil)
+		return
+	}
+	w.WriteBytes(sig.Bytes())
+}
+
 type GobTxEncoder struct {
 	w io.Writer
 }
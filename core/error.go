@@ -5,10 +5,13 @@ import (
 )
 
 var (
-	ErrDocExisted    = errors.New("document existed")
-	ErrDocNotExisted = errors.New("document not existed")
-	ErrTypeInvalid   = errors.New("document type is invalid")
-	ErrSigInvalid    = errors.New("invalid signature")
-	ErrSigNotExisted = errors.New("signature not found")
-	ErrNonceInvalid  = errors.New("nonce is invalid")
+	ErrDocExisted     = errors.New("document existed")
+	ErrDocNotExisted  = errors.New("document not existed")
+	ErrTypeInvalid    = errors.New("document type is invalid")
+	ErrSigInvalid     = errors.New("invalid signature")
+	ErrSigNotExisted  = errors.New("signature not found")
+	ErrNonceInvalid   = errors.New("nonce is invalid")
+	ErrChainIDInvalid = errors.New("chain id is invalid")
+	ErrTxNotYetValid  = errors.New("transaction is not valid yet")
+	ErrTxExpired      = errors.New("transaction is expired")
 )
@@ -0,0 +1,24 @@
+package core
+
+import "errors"
+
+var ErrFeeTooLow = errors.New("max fee of transaction is lower than base fee")
+
+// baseFeeChangeDenominator bounds the change of the base fee between two blocks to 1/8
+const baseFeeChangeDenominator = 8
+
+// NextBaseFee returns the base fee of the block after the parent with parentBaseFee and txCount transactions.
+// The base fee raises when the parent is fuller than the target size and drops when it is emptier.
+func NextBaseFee(parentBaseFee uint64, txCount int, params ChainParams) uint64 {
+	target := uint64(params.TargetBlockTxs)
+	used := uint64(txCount)
+	if target == 0 || used == target {
+		return parentBaseFee
+	}
+	if used > target {
+		delta := parentBaseFee * (used - target) / target / baseFeeChangeDenominator
+		return parentBaseFee + max(delta, 1)
+	}
+	delta := parentBaseFee * (target - used) / target / baseFeeChangeDenominator
+	return parentBaseFee - delta
+}
@@ -0,0 +1,98 @@
+package core
+
+import (
+	"blocker/crypto"
+	"testing"
+
+	"github.com/go-kit/log"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNextBaseFee(t *testing.T) {
+	params := ChainParams{TargetBlockTxs: 10}
+	assert.Equal(t, uint64(100), NextBaseFee(100, 10, params))
+	assert.Equal(t, uint64(112), NextBaseFee(100, 20, params))
+	assert.Equal(t, uint64(88), NextBaseFee(100, 0, params))
+	// base fee always raise on full block, even when it is too small to move by 1/8
+	assert.Equal(t, uint64(1), NextBaseFee(0, 11, params))
+	assert.Equal(t, uint64(100), NextBaseFee(100, 0, ChainParams{}))
+}
+
+func TestTransactionEffectiveFee(t *testing.T) {
+	tx := &Transaction{MaxFee: 30, TipCap: 4}
+	assert.Equal(t, uint64(14), tx.EffectiveFee(10))
+	assert.Equal(t, uint64(4), tx.EffectiveTip(10))
+	// tip is capped by what is left of the max fee
+	assert.Equal(t, uint64(30), tx.EffectiveFee(28))
+	assert.Equal(t, uint64(2), tx.EffectiveTip(28))
+	assert.Equal(t, uint64(0), tx.EffectiveTip(40))
+}
+
+func TestBaseFeeBurnedAndTipToValidator(t *testing.T) {
+	privBob := crypto.GeneratePrivateKey()
+	privAlice := crypto.GeneratePrivateKey()
+	validator := crypto.GeneratePrivateKey()
+	cfg := newTestGenesisConfig()
+	cfg.Alloc[privBob.Public().Address().String()] = 1000
+	cfg.Params.InitialBaseFee = 10
+	cfg.Params.TargetBlockTxs = 1
+	cfg.Params.BlockReward = 0
+	bc, err := NewBlockChainFromGenesis(cfg, NewInMemoryStorage(), log.NewNopLogger())
+	assert.Nil(t, err)
+
+	transferTx := TransferTx{
+		From:  privBob.Public().Address(),
+		To:    privAlice.Public().Address(),
+		Value: 100,
+	}
+	assert.Nil(t, transferTx.Sign(privBob))
+	tx := NewNativeTransferTransaction(transferTx, 1)
+	tx.ChainID = bc.ChainID()
+	tx.MaxFee = 30
+	tx.TipCap = 4
+	assert.Nil(t, tx.Sign(privBob))
+
+	// genesis is fuller than the target size
+	baseFee := bc.NextBaseFee()
+	assert.Equal(t, NextBaseFee(10, 4, cfg.Params), baseFee)
+
+	block := RandomBlock(t, 1, getPrevBlockHash(t, bc, 0))
+	block.BaseFee = baseFee
+	block.AddTransaction(tx)
+	assert.Nil(t, block.ReHash(BlockHasher{}))
+	assert.Nil(t, block.Sign(validator))
+	assert.Nil(t, bc.AddBlock(block))
+
+	receipt, err := bc.GetReceipt(tx.Hash(TxHasher{}))
+	assert.Nil(t, err)
+	assert.Equal(t, baseFee+4, receipt.FeeCharged)
+	assert.Equal(t, baseFee, receipt.FeeBurned)
+
+	bobState, err := bc.GetAccountState(privBob.Public().Address())
+	assert.Nil(t, err)
+	assert.Equal(t, 1000-100-baseFee-4, bobState.Balance)
+	validatorState, err := bc.GetAccountState(validator.Public().Address())
+	assert.Nil(t, err)
+	assert.Equal(t, uint64(4), validatorState.Balance)
+	// block at the target size keeps the base fee
+	assert.Equal(t, baseFee, bc.NextBaseFee())
+
+	// max fee must cover the base fee
+	tx = NewNativeTransaction([]byte{}, 2)
+	tx.ChainID = bc.ChainID()
+	tx.MaxFee = baseFee - 1
+	assert.Nil(t, tx.Sign(privBob))
+	assert.Equal(t, 1, len(bc.SoftcheckTransactions([]*Transaction{tx})))
+	block = RandomBlock(t, 2, getPrevBlockHash(t, bc, 1))
+	block.BaseFee = baseFee
+	block.AddTransaction(tx)
+	assert.Nil(t, block.ReHash(BlockHasher{}))
+	assert.Nil(t, block.Sign(validator))
+	assert.Equal(t, ErrFeeTooLow, bc.AddBlock(block))
+
+	// block must carry the expected base fee
+	block.BaseFee = baseFee + 1
+	assert.Nil(t, block.ReHash(BlockHasher{}))
+	assert.Nil(t, block.Sign(validator))
+	assert.NotNil(t, bc.AddBlock(block))
+}
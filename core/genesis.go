@@ -0,0 +1,231 @@
+package core
+
+import (
+	"blocker/crypto"
+	"blocker/types"
+	"bytes"
+	"crypto/ed25519"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"sort"
+	"time"
+)
+
+const (
+	defaultChainID         = "blocker-local"
+	defaultCoinbaseBalance = 1000000
+	defaultBlockTime       = 5 // seconds
+	defaultInitialBaseFee  = 10
+	defaultTargetBlockTxs  = 50
+	defaultBlockReward     = 10
+	defaultHalvingInterval = 100000
+	defaultConfirmDepth    = 15
+	defaultFinalityDepth   = 30
+)
+
+var ErrGenesisInvalid = errors.New("genesis config is invalid")
+
+// ChainParams are the consensus parameters every node of the chain must agree on
+type ChainParams struct {
+	BlockTime       uint64 `json:"block_time"`       // seconds between blocks
+	MaxBlockTxs     uint32 `json:"max_block_txs"`    // 0 mean unlimited
+	InitialBaseFee  uint64 `json:"initial_base_fee"` // base fee of the genesis block
+	TargetBlockTxs  uint32 `json:"target_block_txs"` // base fee moves toward blocks of this size, 0 keeps it constant
+	BlockReward     uint64 `json:"block_reward"`     // paid to the validator from the coinbase reserve
+	HalvingInterval uint32 `json:"halving_interval"` // blocks between halvings of the reward, 0 never halves
+	ConfirmDepth    uint32 `json:"confirm_depth"`    // blocks on top of a transaction to consider it confirmed
+	FinalityDepth   uint32 `json:"finality_depth"`   // blocks on top of a block to finalize it, 0 only finalizes genesis
+}
+
+// GenesisConfig describes the first block of the chain, e.g.
+//
+//	{
+//	  "chain_id": "blocker-testnet",
+//	  "genesis_time": "2024-01-01T00:00:00Z",
+//	  "coinbase": 1000000,
+//	  "alloc": {"0393f29f09c56a1d108a3ba1a9adbba889eddaa1": 5000},
+//	  "validators": ["<hex ed25519 public key>"],
+//	  "params": {"block_time": 5, "max_block_txs": 100, "initial_base_fee": 10, "target_block_txs": 50,
+//	             "block_reward": 10, "halving_interval": 100000, "confirm_depth": 15, "finality_depth": 30}
+//	}
+type GenesisConfig struct {
+	GenesisTime time.Time         `json:"genesis_time"`
+	Alloc       map[string]uint64 `json:"alloc"` // hex address => initial balance
+	ChainID     string            `json:"chain_id"`
+	Validators  []string          `json:"validators"` // hex public keys, empty mean every key could validate
+	Params      ChainParams       `json:"params"`
+	Coinbase    uint64            `json:"coinbase"` // balance of the coinbase account
+}
+
+func DefaultGenesisConfig() *GenesisConfig {
+	return &GenesisConfig{
+		ChainID:     defaultChainID,
+		GenesisTime: time.Unix(0, 0).UTC(),
+		Coinbase:    defaultCoinbaseBalance,
+		Alloc:       map[string]uint64{},
+		Validators:  []string{},
+		Params: ChainParams{
+			BlockTime:       defaultBlockTime,
+			InitialBaseFee:  defaultInitialBaseFee,
+			TargetBlockTxs:  defaultTargetBlockTxs,
+			BlockReward:     defaultBlockReward,
+			HalvingInterval: defaultHalvingInterval,
+			ConfirmDepth:    defaultConfirmDepth,
+			FinalityDepth:   defaultFinalityDepth,
+		},
+	}
+}
+
+func LoadGenesisConfig(path string) (*GenesisConfig, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	return ParseGenesisConfig(data)
+}
+
+func ParseGenesisConfig(data []byte) (*GenesisConfig, error) {
+	cfg := DefaultGenesisConfig()
+	dec := json.NewDecoder(bytes.NewReader(data))
+	dec.DisallowUnknownFields()
+	if err := dec.Decode(cfg); err != nil {
+		return nil, fmt.Errorf("%w: %s", ErrGenesisInvalid, err.Error())
+	}
+	if err := cfg.Validate(); err != nil {
+		return nil, err
+	}
+	return cfg, nil
+}
+
+func (cfg *GenesisConfig) Validate() error {
+	if cfg.ChainID == "" {
+		return fmt.Errorf("%w: chain id is empty", ErrGenesisInvalid)
+	}
+	if cfg.Params.FinalityDepth != 0 && cfg.Params.FinalityDepth < cfg.Params.ConfirmDepth {
+		return fmt.Errorf("%w: finality depth is lower than confirm depth", ErrGenesisInvalid)
+	}
+	if _, err := cfg.allocations(); err != nil {
+		return err
+	}
+	if _, err := cfg.ValidatorKeys(); err != nil {
+		return err
+	}
+	return nil
+}
+
+// ValidatorKeys returns the public keys of the initial validator set
+func (cfg *GenesisConfig) ValidatorKeys() ([]*crypto.PublicKey, error) {
+	keys := []*crypto.PublicKey{}
+	for _, str := range cfg.Validators {
+		b, err := hex.DecodeString(str)
+		if err != nil || len(b) != ed25519.PublicKeySize {
+			return nil, fmt.Errorf("%w: validator (%s) is not a valid public key", ErrGenesisInvalid, str)
+		}
+		keys = append(keys, &crypto.PublicKey{Key: b})
+	}
+	return keys, nil
+}
+
+type genesisAllocation struct {
+	addr    types.Address
+	balance uint64
+}
+
+// allocations returns the initial balances sorted by address
+func (cfg *GenesisConfig) allocations() ([]genesisAllocation, error) {
+	allocs := []genesisAllocation{}
+	for str, balance := range cfg.Alloc {
+		b, err := hex.DecodeString(str)
+		if err != nil || len(b) != len(types.Address{}) {
+			return nil, fmt.Errorf("%w: alloc address (%s) is invalid", ErrGenesisInvalid, str)
+		}
+		allocs = append(allocs, genesisAllocation{addr: types.AddressFromBytes(b), balance: balance})
+	}
+	sort.Slice(allocs, func(i, j int) bool {
+		return bytes.Compare(allocs[i].addr.Bytes(), allocs[j].addr.Bytes()) < 0
+	})
+	for i := 1; i < len(allocs); i++ {
+		if allocs[i].addr == allocs[i-1].addr {
+			return nil, fmt.Errorf("%w: alloc address (%s) is duplicated", ErrGenesisInvalid, allocs[i].addr)
+		}
+	}
+	return allocs, nil
+}
+
+// paramsHash commits to the part of the config that is not carried by genesis transactions
+func (cfg *GenesisConfig) paramsHash() (types.Hash, error) {
+	keys, err := cfg.ValidatorKeys()
+	if err != nil {
+		return types.Hash{}, err
+	}
+	validators := []string{}
+	for _, key := range keys {
+		validators = append(validators, hex.EncodeToString(key.Bytes()))
+	}
+	b, err := json.Marshal(struct {
+		ChainID    string      `json:"chain_id"`
+		Validators []string    `json:"validators"`
+		Params     ChainParams `json:"params"`
+	}{
+		ChainID:    cfg.ChainID,
+		Validators: validators,
+		Params:     cfg.Params,
+	})
+	if err != nil {
+		return types.Hash{}, err
+	}
+	return sha256.Sum256(b), nil
+}
+
+// Block builds the genesis block, the same config always produce the same block.
+// Genesis has no parent, so its PrevBlockHash commits to the chain id, validators and params.
+func (cfg *GenesisConfig) Block() (*Block, error) {
+	if err := cfg.Validate(); err != nil {
+		return nil, err
+	}
+	allocs, err := cfg.allocations()
+	if err != nil {
+		return nil, err
+	}
+	paramsHash, err := cfg.paramsHash()
+	if err != nil {
+		return nil, err
+	}
+
+	txx := []*Transaction{}
+	if cfg.Coinbase > 0 {
+		txx = append(txx, &Transaction{TxInner: TransferTx{Value: cfg.Coinbase}})
+	}
+	for _, alloc := range allocs {
+		txx = append(txx, &Transaction{TxInner: TransferTx{To: alloc.addr, Value: alloc.balance}})
+	}
+	dataHash, err := CalculateDataHash(txx)
+	if err != nil {
+		return nil, err
+	}
+
+	return NewBlock(&Header{
+		Version:       1,
+		PrevBlockHash: paramsHash,
+		DataHash:      dataHash,
+		Height:        0,
+		Timestamp:     cfg.GenesisTime.UnixNano(),
+		BaseFee:       cfg.Params.InitialBaseFee,
+	}, txx)
+}
+
+func (tx *Transaction) isGenesisAllocation() bool {
+	transferTx, ok := tx.TxInner.(TransferTx)
+	if !ok {
+		return false
+	}
+	return transferTx.From.IsZero() &&
+		!transferTx.To.IsZero() &&
+		transferTx.Signer == nil &&
+		tx.From == nil &&
+		tx.Nonce == 0
+}
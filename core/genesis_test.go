@@ -0,0 +1,124 @@
+package core
+
+import (
+	"blocker/crypto"
+	"blocker/types"
+	"encoding/hex"
+	"testing"
+	"time"
+
+	"github.com/go-kit/log"
+	"github.com/stretchr/testify/assert"
+)
+
+func newTestGenesisConfig(validators ...*crypto.PrivateKey) *GenesisConfig {
+	cfg := DefaultGenesisConfig()
+	cfg.ChainID = "blocker-test"
+	cfg.GenesisTime = time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	cfg.Alloc = map[string]uint64{
+		"0393f29f09c56a1d108a3ba1a9adbba889eddaa1": 5000,
+		"1111111111111111111111111111111111111111": 300,
+	}
+	for _, v := range validators {
+		cfg.Validators = append(cfg.Validators, hex.EncodeToString(v.Public().Bytes()))
+	}
+	return cfg
+}
+
+func TestParseGenesisConfig(t *testing.T) {
+	data := []byte(`{
+		"chain_id": "blocker-test",
+		"genesis_time": "2024-01-01T00:00:00Z",
+		"alloc": {"1111111111111111111111111111111111111111": 300, "0393f29f09c56a1d108a3ba1a9adbba889eddaa1": 5000},
+		"params": {"block_time": 3, "max_block_txs": 10}
+	}`)
+	cfg, err := ParseGenesisConfig(data)
+	assert.Nil(t, err)
+	assert.Equal(t, "blocker-test", cfg.ChainID)
+	assert.Equal(t, uint64(defaultCoinbaseBalance), cfg.Coinbase)
+	assert.Equal(t, ChainParams{BlockTime: 3, MaxBlockTxs: 10, InitialBaseFee: defaultInitialBaseFee, TargetBlockTxs: defaultTargetBlockTxs,
+		BlockReward: defaultBlockReward, HalvingInterval: defaultHalvingInterval,
+		ConfirmDepth: defaultConfirmDepth, FinalityDepth: defaultFinalityDepth}, cfg.Params)
+
+	_, err = ParseGenesisConfig([]byte(`{"chain_id": ""}`))
+	assert.ErrorIs(t, err, ErrGenesisInvalid)
+	_, err = ParseGenesisConfig([]byte(`{"chain_id": "a", "alloc": {"xyz": 1}}`))
+	assert.ErrorIs(t, err, ErrGenesisInvalid)
+	_, err = ParseGenesisConfig([]byte(`{"chain_id": "a", "validators": ["00"]}`))
+	assert.ErrorIs(t, err, ErrGenesisInvalid)
+	_, err = ParseGenesisConfig([]byte(`{"chain_id": "a", "unknown": 1}`))
+	assert.ErrorIs(t, err, ErrGenesisInvalid)
+	_, err = ParseGenesisConfig([]byte(`{"chain_id": "a", "params": {"confirm_depth": 10, "finality_depth": 5}}`))
+	assert.ErrorIs(t, err, ErrGenesisInvalid)
+}
+
+func TestGenesisBlockDeterministic(t *testing.T) {
+	b1, err := newTestGenesisConfig().Block()
+	assert.Nil(t, err)
+	b2, err := newTestGenesisConfig().Block()
+	assert.Nil(t, err)
+	assert.Equal(t, b1.Hash(BlockHasher{}), b2.Hash(BlockHasher{}))
+	assert.Equal(t, 3, len(b1.Transactions))
+
+	other := newTestGenesisConfig()
+	other.ChainID = "blocker-other"
+	b3, err := other.Block()
+	assert.Nil(t, err)
+	assert.NotEqual(t, b1.Hash(BlockHasher{}), b3.Hash(BlockHasher{}))
+
+	other = newTestGenesisConfig()
+	other.Alloc["1111111111111111111111111111111111111111"] = 301
+	b4, err := other.Block()
+	assert.Nil(t, err)
+	assert.NotEqual(t, b1.Hash(BlockHasher{}), b4.Hash(BlockHasher{}))
+}
+
+func TestBlockChainFromGenesis(t *testing.T) {
+	validator := crypto.GeneratePrivateKey()
+	cfg := newTestGenesisConfig(validator)
+	bc, err := NewBlockChainFromGenesis(cfg, NewInMemoryStorage(), log.NewNopLogger())
+	assert.Nil(t, err)
+	assert.Equal(t, "blocker-test", bc.ChainID())
+
+	genesis, err := cfg.Block()
+	assert.Nil(t, err)
+	assert.Equal(t, genesis.Hash(BlockHasher{}), bc.GenesisHash())
+
+	addr, err := hex.DecodeString("0393f29f09c56a1d108a3ba1a9adbba889eddaa1")
+	assert.Nil(t, err)
+	state, err := bc.GetAccountState(types.AddressFromBytes(addr))
+	assert.Nil(t, err)
+	assert.Equal(t, uint64(5000), state.Balance)
+	assert.Equal(t, uint64(defaultCoinbaseBalance), bc.store.GetCoinbaseState().Balance)
+
+	// only validator from genesis could sign blocks
+	block := RandomBlock(t, 1, getPrevBlockHash(t, bc, 0))
+	block.BaseFee = bc.NextBaseFee()
+	assert.NotNil(t, bc.AddBlock(block))
+	assert.Nil(t, block.Sign(validator))
+	assert.Nil(t, bc.AddBlock(block))
+}
+
+func TestBlockWithTxOfOtherChain(t *testing.T) {
+	bc, err := NewBlockChainFromGenesis(newTestGenesisConfig(), NewInMemoryStorage(), log.NewNopLogger())
+	assert.Nil(t, err)
+	validator := crypto.GeneratePrivateKey()
+
+	tx := &Transaction{Data: []byte{}, Nonce: 1, MaxFee: bc.NextBaseFee(), ChainID: "blocker-other"}
+	assert.Nil(t, tx.Sign(crypto.GeneratePrivateKey()))
+	block := RandomBlock(t, 1, getPrevBlockHash(t, bc, 0))
+	block.BaseFee = bc.NextBaseFee()
+	block.AddTransaction(tx)
+	assert.Nil(t, block.ReHash(BlockHasher{}))
+	assert.Nil(t, block.Sign(validator))
+	assert.ErrorIs(t, bc.AddBlock(block), ErrChainIDInvalid)
+
+	tx = &Transaction{Data: []byte{}, Nonce: 1, MaxFee: bc.NextBaseFee(), ChainID: bc.ChainID()}
+	assert.Nil(t, tx.Sign(crypto.GeneratePrivateKey()))
+	block = RandomBlock(t, 1, getPrevBlockHash(t, bc, 0))
+	block.BaseFee = bc.NextBaseFee()
+	block.AddTransaction(tx)
+	assert.Nil(t, block.ReHash(BlockHasher{}))
+	assert.Nil(t, block.Sign(validator))
+	assert.Nil(t, bc.AddBlock(block))
+}
@@ -7,7 +7,7 @@ import (
 )
 
 func TestHasher(t *testing.T) {
-	tx := NewNativeTransaction([]byte("hello world"))
+	tx := NewNativeTransaction([]byte("hello world"), 0)
 	txHash := tx.Hash(TxHasher{})
 	println(tx.String())
 
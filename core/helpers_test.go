@@ -0,0 +1,96 @@
+package core
+
+import (
+	"blocker/crypto"
+	"blocker/types"
+	"testing"
+
+	"github.com/go-kit/log"
+	"github.com/stretchr/testify/assert"
+)
+
+// testChain is a chain created from a genesis config, its blocks are signed by validatorKey and its
+// transactions are signed with the next nonce of each key
+type testChain struct {
+	*BlockChain
+	t            *testing.T
+	validatorKey *crypto.PrivateKey
+	nonces       map[*crypto.PrivateKey]uint64
+	coins        uint64 // issued coins plus the coinbase reserve, block rewards move coins between them
+}
+
+func newTestChain(t *testing.T, cfg *GenesisConfig) *testChain {
+	bc, err := NewBlockChainFromGenesis(cfg, NewInMemoryStorage(), log.NewNopLogger())
+	assert.Nil(t, err)
+	return &testChain{
+		BlockChain:   bc,
+		t:            t,
+		validatorKey: crypto.GeneratePrivateKey(),
+		nonces:       make(map[*crypto.PrivateKey]uint64),
+		coins:        bc.Supply().Issued + bc.CoinbaseReserve(),
+	}
+}
+
+// newTx signs the transaction of inner by priv, inner transactions like mint are signed by the caller
+func (c *testChain) newTx(priv *crypto.PrivateKey, inner any) *Transaction {
+	return c.newTipTx(priv, inner, 0)
+}
+
+func (c *testChain) newTipTx(priv *crypto.PrivateKey, inner any, tip uint64) *Transaction {
+	c.nonces[priv]++
+	tx := &Transaction{TxInner: inner, Nonce: c.nonces[priv], ChainID: c.ChainID(), TipCap: tip}
+	tx.MaxFee = c.NextBaseFee()*2 + tip
+	assert.Nil(c.t, tx.Sign(priv))
+	return tx
+}
+
+// newBlock returns the next block of the chain with txx
+func (c *testChain) newBlock(txx ...*Transaction) *Block {
+	height := c.Height() + 1
+	block := RandomBlock(c.t, height, getPrevBlockHash(c.t, c.BlockChain, height-1))
+	block.BaseFee = c.NextBaseFee()
+	for _, tx := range txx {
+		block.AddTransaction(tx)
+	}
+	assert.Nil(c.t, block.ReHash(BlockHasher{}))
+	assert.Nil(c.t, block.Sign(c.validatorKey))
+	return block
+}
+
+// addBlock adds the next block with txx and returns their receipts, the supply must stay consistent
+func (c *testChain) addBlock(txx ...*Transaction) []*Receipt {
+	assert.Nil(c.t, c.AddBlock(c.newBlock(txx...)))
+	assertSupplyInvariant(c.t, c.BlockChain)
+	assert.Equal(c.t, c.coins, c.Supply().Issued+c.CoinbaseReserve())
+	receipts := []*Receipt{}
+	for _, tx := range txx {
+		receipt, err := c.GetReceipt(tx.Hash(TxHasher{}))
+		assert.Nil(c.t, err)
+		receipts = append(receipts, receipt)
+	}
+	return receipts
+}
+
+// account returns a copy of the state of addr, so it could be compared after later blocks
+func (c *testChain) account(addr types.Address) AccountState {
+	state, err := c.GetAccountState(addr)
+	assert.Nil(c.t, err)
+	return *state
+}
+
+// signMint signs the mint transaction by the owner of the nft
+func signMint(t *testing.T, priv *crypto.PrivateKey, mintTx MintTx) MintTx {
+	assert.Nil(t, mintTx.Sign(priv))
+	return mintTx
+}
+
+// assertSupplyInvariant checks that no coin is created or destroyed outside of the tracked supply
+func assertSupplyInvariant(t *testing.T, bc *BlockChain) {
+	accounts, err := bc.GetAccountStates()
+	assert.Nil(t, err)
+	var total uint64
+	for _, acc := range accounts {
+		total += acc.Balance + acc.Locked
+	}
+	assert.Equal(t, bc.Supply().Circulating(), total, "sum of balances must equal circulating supply")
+}
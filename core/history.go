@@ -0,0 +1,235 @@
+package core
+
+import (
+	"blocker/types"
+	"cmp"
+	"errors"
+	"fmt"
+	"sort"
+)
+
+const (
+	defaultAccountTxLimit = 20
+	maxAccountTxLimit     = 100
+)
+
+var ErrCursorInvalid = errors.New("cursor is invalid")
+
+type AccountTxRole string
+
+const (
+	AccountTxRoleSender    AccountTxRole = "sender"
+	AccountTxRoleRecipient AccountTxRole = "recipient"
+	AccountTxRoleNFTOwner  AccountTxRole = "nft_owner"
+	AccountTxRoleValidator AccountTxRole = "validator"
+	// AccountTxRoleParticipant is an address whose coins or nft are moved by a transaction it neither sends nor receives from,
+	// like the sender of an htlc claimed by its recipient
+	AccountTxRoleParticipant AccountTxRole = "participant"
+)
+
+// AccountTx is an entry in the transaction history of an address.
+type AccountTx struct {
+	Hash   types.Hash
+	Type   TxType
+	Roles  []AccountTxRole
+	Height uint32
+	Index  uint32 // position of the transaction inside the block
+}
+
+func (a *AccountTx) Cursor() TxCursor {
+	return TxCursor{Height: a.Height, Index: a.Index}
+}
+
+// TxCursor point at a transaction by its position in the chain.
+type TxCursor struct {
+	Height uint32
+	Index  uint32
+}
+
+func (c TxCursor) String() string {
+	return fmt.Sprintf("%d.%d", c.Height, c.Index)
+}
+
+func (c TxCursor) Less(o TxCursor) bool {
+	return c.Compare(o) < 0
+}
+
+// Compare returns -1, 0 or 1 if c is before, at or after o in the chain
+func (c TxCursor) Compare(o TxCursor) int {
+	if c.Height != o.Height {
+		return cmp.Compare(c.Height, o.Height)
+	}
+	return cmp.Compare(c.Index, o.Index)
+}
+
+func ParseTxCursor(str string) (TxCursor, error) {
+	cursor := TxCursor{}
+	if _, err := fmt.Sscanf(str, "%d.%d", &cursor.Height, &cursor.Index); err != nil {
+		return TxCursor{}, ErrCursorInvalid
+	}
+	return cursor, nil
+}
+
+// AccountTxQuery filter and paginate the transaction history of an address.
+// Cursor is exclusive, empty cursor start from the beginning (or the end if Desc).
+type AccountTxQuery struct {
+	Cursor string
+	Types  []TxType
+	Limit  int
+	Desc   bool
+}
+
+type AccountTxPage struct {
+	Txx  []*AccountTx
+	Next string // empty mean no more transactions
+}
+
+func paginateAccountTxs(txx []*AccountTx, query AccountTxQuery) (*AccountTxPage, error) {
+	limit := query.Limit
+	if limit <= 0 {
+		limit = defaultAccountTxLimit
+	}
+	if limit > maxAccountTxLimit {
+		limit = maxAccountTxLimit
+	}
+
+	// txx are sorted ascending by (height, index), start is the first index to visit
+	start, step := 0, 1
+	if query.Desc {
+		start, step = len(txx)-1, -1
+	}
+	if query.Cursor != "" {
+		cursor, err := ParseTxCursor(query.Cursor)
+		if err != nil {
+			return nil, err
+		}
+		// first entry strictly after the cursor
+		idx := sort.Search(len(txx), func(i int) bool { return cursor.Less(txx[i].Cursor()) })
+		if query.Desc {
+			// last entry strictly before the cursor
+			idx = sort.Search(len(txx), func(i int) bool { return !txx[i].Cursor().Less(cursor) }) - 1
+		}
+		start = idx
+	}
+
+	page := &AccountTxPage{Txx: []*AccountTx{}}
+	for i := start; i >= 0 && i < len(txx); i += step {
+		if !matchTxTypes(txx[i].Type, query.Types) {
+			continue
+		}
+		if len(page.Txx) == limit {
+			page.Next = page.Txx[limit-1].Cursor().String()
+			break
+		}
+		page.Txx = append(page.Txx, txx[i])
+	}
+	return page, nil
+}
+
+func matchTxTypes(typ TxType, filter []TxType) bool {
+	if len(filter) == 0 {
+		return true
+	}
+	for _, t := range filter {
+		if t == typ {
+			return true
+		}
+	}
+	return false
+}
+
+// accountTxsOfBlock return history entries for every address that took part in transactions of the block, from the
+// fields of the transactions and from the state changes of their receipts, which are nil for the genesis block
+func accountTxsOfBlock(b *Block, receipts []*Receipt) map[types.Address][]*AccountTx {
+	entries := make(map[types.Address][]*AccountTx)
+	for i, tx := range b.Transactions {
+		roles := make(map[types.Address][]AccountTxRole)
+		addRole := func(addr types.Address, role AccountTxRole) {
+			if addr.IsZero() {
+				return
+			}
+			for _, r := range roles[addr] {
+				if r == role {
+					return
+				}
+			}
+			roles[addr] = append(roles[addr], role)
+		}
+
+		addRole(tx.Sender(), AccountTxRoleSender)
+		// a failed transaction only involves its sender, and the validator paid by its fee
+		if i >= len(receipts) || receipts[i].Succeeded() {
+			switch ttx := tx.TxInner.(type) {
+			case TransferTx:
+				addRole(ttx.From, AccountTxRoleSender)
+				addRole(ttx.To, AccountTxRoleRecipient)
+			case VestingTransferTx:
+				addRole(ttx.To, AccountTxRoleRecipient)
+			case HTLCLockTx:
+				addRole(ttx.To, AccountTxRoleRecipient)
+			case BatchTransferTx:
+				for _, output := range ttx.Outputs {
+					addRole(output.To, AccountTxRoleRecipient)
+				}
+			case TokenTransferTx:
+				addRole(ttx.To, AccountTxRoleRecipient)
+			case NFTTransferTx:
+				addRole(ttx.To, AccountTxRoleNFTOwner)
+			case NFTAcceptOfferTx:
+				addRole(ttx.Buyer, AccountTxRoleNFTOwner)
+			case NFTSaleTx:
+				addRole(ttx.Buyer, AccountTxRoleNFTOwner)
+				if ttx.Seller != nil {
+					addRole(ttx.Seller.Address(), AccountTxRoleRecipient)
+				}
+			case TokenMintTx:
+				addRole(ttx.To, AccountTxRoleRecipient)
+			case MintTx:
+				if ttx.Owner != nil {
+					addRole(ttx.Owner.Address(), AccountTxRoleNFTOwner)
+				}
+			}
+			// parties known only from the state, like the seller of a listing or the royalty recipient
+			if i < len(receipts) {
+				for _, change := range receipts[i].StateChanges {
+					if role, ok := roleOfStateChange(tx, change); ok {
+						addRole(change.Addr, role)
+					}
+				}
+			}
+		}
+		if tx.EffectiveTip(b.BaseFee) > 0 && b.Validator != nil {
+			addRole(b.Validator.Address(), AccountTxRoleValidator)
+		}
+
+		hash := tx.Hash(TxHasher{})
+		typ := tx.Type()
+		for addr, rr := range roles {
+			entries[addr] = append(entries[addr], &AccountTx{
+				Hash:   hash,
+				Type:   typ,
+				Roles:  rr,
+				Height: b.Height,
+				Index:  uint32(i),
+			})
+		}
+	}
+	return entries
+}
+
+// roleOfStateChange returns the role of the address changed by tx, false when the change adds nothing to the
+// role of the sender
+func roleOfStateChange(tx *Transaction, change StateChange) (AccountTxRole, bool) {
+	switch {
+	case change.Kind == StateChangeNFT || change.Kind == StateChangeCollection:
+		return AccountTxRoleNFTOwner, true
+	case change.Kind == StateChangeNonce:
+		return "", false
+	case change.Delta > 0:
+		return AccountTxRoleRecipient, true
+	case change.Addr == tx.Sender():
+		return "", false
+	default:
+		return AccountTxRoleParticipant, true
+	}
+}
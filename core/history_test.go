@@ -0,0 +1,117 @@
+package core
+
+import (
+	"blocker/crypto"
+	"blocker/types"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestAccountTransactionsHistory(t *testing.T) {
+	bc := newBlockChainWithGenesis(t)
+	validator := crypto.GeneratePrivateKey()
+	privBob := crypto.GeneratePrivateKey()
+	privAlice := crypto.GeneratePrivateKey()
+	bobState := NewAccountState(privBob.Public())
+	bobState.Balance = 10000
+	assert.Nil(t, bc.store.PutAccount(bobState))
+
+	nonce := uint64(1)
+	for i := 0; i < 3; i++ {
+		block := RandomBlock(t, uint32(i+1), getPrevBlockHash(t, bc, uint32(i)))
+
+		transferTx := TransferTx{
+			From:  privBob.Public().Address(),
+			To:    privAlice.Public().Address(),
+			Value: 10,
+		}
+		assert.Nil(t, transferTx.Sign(privBob))
+		tx := NewNativeTransferTransaction(transferTx, nonce)
+		tx.MaxFee = 1
+		tx.TipCap = 1
+		nonce++
+		assert.Nil(t, tx.Sign(privBob))
+		block.AddTransaction(tx)
+
+		mintTx := MintTx{NFT: NFTAsset{Type: NFTAssetTypeImageURL, Data: []byte{byte(i)}}}
+		assert.Nil(t, mintTx.Sign(privBob))
+		tx = NewNativeMintTransacton(mintTx, nonce)
+		nonce++
+		assert.Nil(t, tx.Sign(privBob))
+		block.AddTransaction(tx)
+
+		assert.Nil(t, block.ReHash(BlockHasher{}))
+		assert.Nil(t, block.Sign(validator))
+		assert.Nil(t, bc.AddBlock(block))
+	}
+
+	// bob took part in every transaction
+	page, err := bc.GetAccountTransactions(privBob.Public().Address(), AccountTxQuery{Limit: 4})
+	assert.Nil(t, err)
+	assert.Equal(t, 4, len(page.Txx))
+	assert.Equal(t, "2.1", page.Next)
+	assert.Equal(t, []AccountTxRole{AccountTxRoleSender, AccountTxRoleNFTOwner}, page.Txx[1].Roles)
+	for i := 0; i < len(page.Txx)-1; i++ {
+		assert.True(t, page.Txx[i].Cursor().Less(page.Txx[i+1].Cursor()))
+	}
+
+	page, err = bc.GetAccountTransactions(privBob.Public().Address(), AccountTxQuery{Limit: 4, Cursor: page.Next})
+	assert.Nil(t, err)
+	assert.Equal(t, 2, len(page.Txx))
+	assert.Equal(t, "", page.Next)
+
+	// type filter
+	page, err = bc.GetAccountTransactions(privBob.Public().Address(), AccountTxQuery{Types: []TxType{TxTypeMint}})
+	assert.Nil(t, err)
+	assert.Equal(t, 3, len(page.Txx))
+	for _, entry := range page.Txx {
+		assert.Equal(t, TxTypeMint, entry.Type)
+	}
+
+	// alice only received transfers
+	page, err = bc.GetAccountTransactions(privAlice.Public().Address(), AccountTxQuery{Desc: true, Limit: 2})
+	assert.Nil(t, err)
+	assert.Equal(t, 2, len(page.Txx))
+	assert.Equal(t, uint32(3), page.Txx[0].Height)
+	assert.Equal(t, []AccountTxRole{AccountTxRoleRecipient}, page.Txx[0].Roles)
+	page, err = bc.GetAccountTransactions(privAlice.Public().Address(), AccountTxQuery{Desc: true, Cursor: page.Next})
+	assert.Nil(t, err)
+	assert.Equal(t, 1, len(page.Txx))
+	assert.Equal(t, uint32(1), page.Txx[0].Height)
+
+	// validator received fee of transfers
+	page, err = bc.GetAccountTransactions(validator.Public().Address(), AccountTxQuery{})
+	assert.Nil(t, err)
+	assert.Equal(t, 3, len(page.Txx))
+	assert.Equal(t, []AccountTxRole{AccountTxRoleValidator}, page.Txx[0].Roles)
+
+	page, err = bc.GetAccountTransactions(types.Address{}, AccountTxQuery{})
+	assert.Nil(t, err)
+	assert.Equal(t, 0, len(page.Txx))
+
+	_, err = bc.GetAccountTransactions(privBob.Public().Address(), AccountTxQuery{Cursor: "invalid"})
+	assert.Equal(t, ErrCursorInvalid, err)
+}
+
+func TestAccountTransactionsHistoryOfFailedTransfer(t *testing.T) {
+	privBob := crypto.GeneratePrivateKey()
+	alice := crypto.GeneratePrivateKey().Public().Address()
+	cfg := newTestGenesisConfig()
+	cfg.Alloc[privBob.Public().Address().String()] = 1000
+	bc := newTestChain(t, cfg)
+
+	// the recipient of a failed transfer did not take part in it
+	overdraft := TransferTx{From: privBob.Public().Address(), To: alice, Value: 5000}
+	assert.Nil(t, overdraft.Sign(privBob))
+	tx := bc.newTx(privBob, overdraft)
+	assert.False(t, bc.addBlock(tx)[0].Succeeded())
+
+	page, err := bc.GetAccountTransactions(privBob.Public().Address(), AccountTxQuery{Desc: true, Limit: 1})
+	assert.Nil(t, err)
+	assert.Equal(t, tx.Hash(TxHasher{}), page.Txx[0].Hash)
+	assert.Equal(t, []AccountTxRole{AccountTxRoleSender}, page.Txx[0].Roles)
+	page, err = bc.GetAccountTransactions(alice, AccountTxQuery{})
+	assert.Nil(t, err)
+	assert.Empty(t, page.Txx)
+}
@@ -0,0 +1,146 @@
+package core
+
+import (
+	"blocker/serialize"
+	"blocker/types"
+	"crypto/sha256"
+	"encoding/gob"
+	"errors"
+	"fmt"
+)
+
+var (
+	ErrHTLCInvalid         = errors.New("htlc transaction is invalid")
+	ErrHTLCNotExisted      = errors.New("htlc not existed")
+	ErrHTLCExpired         = errors.New("htlc timed out")
+	ErrHTLCNotExpired      = errors.New("htlc not timed out yet")
+	ErrHTLCClosed          = errors.New("htlc already claimed or refunded")
+	ErrHTLCPreimageInvalid = errors.New("preimage does not match the hashlock")
+)
+
+// maxHTLCPreimageLength bounds the preimage revealed on chain, other chains commonly use 32 bytes
+const maxHTLCPreimageLength = 256
+
+// HTLC is a hash time-locked contract, it is identified by the hash of the transaction that locked it.
+// Value is locked in the account of the sender until the preimage of Hashlock is revealed, which pays the
+// recipient, or until TimeoutHeight, from which it is refunded to the sender.
+type HTLC struct {
+	Hash          types.Hash
+	Sender        types.Address
+	Recipient     types.Address
+	Value         uint64
+	Hashlock      types.Hash // sha256 of the preimage
+	TimeoutHeight uint32     // claims are accepted in blocks below TimeoutHeight, refunds from TimeoutHeight
+	Preimage      []byte     // set when claimed, so the other side of a swap could read it from the chain
+	Claimed       bool
+	Refunded      bool
+	Position      TxCursor // position of the lock transaction in the chain
+}
+
+// HTLCLockTx locks Value from the sender for To under Hashlock until TimeoutHeight
+type HTLCLockTx struct {
+	To            types.Address
+	Value         uint64
+	Hashlock      types.Hash
+	TimeoutHeight uint32
+}
+
+// HTLCClaimTx pays the htlc to its recipient by revealing the preimage of its hashlock. Anyone knowing the
+// preimage could claim, the coins only go to the recipient.
+type HTLCClaimTx struct {
+	HTLC     types.Hash
+	Preimage []byte
+}
+
+// HTLCRefundTx returns the htlc to its sender once it timed out. Anyone could refund, the coins only go to the sender.
+type HTLCRefundTx struct {
+	HTLC types.Hash
+}
+
+func NewHTLCTransaction(inner any, nonce uint64) *Transaction {
+	return &Transaction{
+		TxInner: inner,
+		Nonce:   nonce,
+	}
+}
+
+func NewHTLC(hash types.Hash, sender types.Address, tx HTLCLockTx) *HTLC {
+	return &HTLC{
+		Hash:          hash,
+		Sender:        sender,
+		Recipient:     tx.To,
+		Value:         tx.Value,
+		Hashlock:      tx.Hashlock,
+		TimeoutHeight: tx.TimeoutHeight,
+	}
+}
+
+// Hashlock returns the hashlock of preimage
+func Hashlock(preimage []byte) types.Hash {
+	return types.Hash(sha256.Sum256(preimage))
+}
+
+// Expired checks whether the htlc timed out in the block at height
+func (h *HTLC) Expired(height uint32) bool {
+	return height >= h.TimeoutHeight
+}
+
+// Closed checks whether the coins of the htlc were already paid out
+func (h *HTLC) Closed() bool {
+	return h.Claimed || h.Refunded
+}
+
+func (tx *HTLCLockTx) Bytes() []byte {
+	w := serialize.NewWriter()
+	w.WriteFixed(tx.To.Bytes())
+	w.WriteUint64(tx.Value)
+	w.WriteFixed(tx.Hashlock.Bytes())
+	w.WriteUint32(tx.TimeoutHeight)
+	return w.Bytes()
+}
+
+func (tx *HTLCLockTx) Validate() error {
+	if tx.To.IsZero() || tx.Value == 0 {
+		return fmt.Errorf("%w: recipient and value must be set", ErrHTLCInvalid)
+	}
+	if tx.Hashlock.IsZero() || tx.TimeoutHeight == 0 {
+		return fmt.Errorf("%w: hashlock and timeout height must be set", ErrHTLCInvalid)
+	}
+	return nil
+}
+
+func (tx *HTLCClaimTx) Bytes() []byte {
+	w := serialize.NewWriter()
+	w.WriteFixed(tx.HTLC.Bytes())
+	w.WriteBytes(tx.Preimage)
+	return w.Bytes()
+}
+
+func (tx *HTLCClaimTx) Validate() error {
+	if tx.HTLC.IsZero() || len(tx.Preimage) == 0 {
+		return fmt.Errorf("%w: htlc and preimage must be set", ErrHTLCInvalid)
+	}
+	if len(tx.Preimage) > maxHTLCPreimageLength {
+		return fmt.Errorf("%w: preimage longer than %d bytes", ErrHTLCInvalid, maxHTLCPreimageLength)
+	}
+	return nil
+}
+
+func (tx *HTLCRefundTx) Bytes() []byte {
+	w := serialize.NewWriter()
+	w.WriteFixed(tx.HTLC.Bytes())
+	return w.Bytes()
+}
+
+func (tx *HTLCRefundTx) Validate() error {
+	if tx.HTLC.IsZero() {
+		return fmt.Errorf("%w: htlc must be set", ErrHTLCInvalid)
+	}
+	return nil
+}
+
+func init() {
+	gob.Register(HTLCLockTx{})
+	gob.Register(HTLCClaimTx{})
+	gob.Register(HTLCRefundTx{})
+}
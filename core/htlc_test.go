@@ -0,0 +1,87 @@
+package core
+
+import (
+	"blocker/crypto"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestHTLC(t *testing.T) {
+	privAlice := crypto.GeneratePrivateKey()
+	privBob := crypto.GeneratePrivateKey()
+	alice := privAlice.Public().Address()
+	bob := privBob.Public().Address()
+	cfg := newTestGenesisConfig()
+	cfg.Alloc[alice.String()] = 10000
+	cfg.Alloc[bob.String()] = 10000
+	bc := newTestChain(t, cfg)
+
+	secret := []byte("swap secret")
+	hashlock := Hashlock(secret)
+
+	// the htlc must time out after the block it is locked in and the sender must cover the value
+	tooEarly := bc.newTx(privAlice, HTLCLockTx{To: bob, Value: 500, Hashlock: hashlock, TimeoutHeight: bc.Height() + 1})
+	tooMuch := bc.newTx(privAlice, HTLCLockTx{To: bob, Value: 20000, Hashlock: hashlock, TimeoutHeight: bc.Height() + 4})
+	lock := bc.newTx(privAlice, HTLCLockTx{To: bob, Value: 500, Hashlock: hashlock, TimeoutHeight: bc.Height() + 4})
+	assert.Equal(t, 2, len(bc.SoftcheckTransactions([]*Transaction{tooEarly, tooMuch, lock})))
+	aliceBefore := bc.account(alice)
+	receipts := bc.addBlock(tooEarly, tooMuch, lock)
+	assert.False(t, receipts[0].Succeeded())
+	assert.Equal(t, ErrTxInsufficientBalance.Error(), receipts[1].Err)
+	assert.True(t, receipts[2].Succeeded())
+	fees := receipts[0].FeeCharged + receipts[1].FeeCharged + receipts[2].FeeCharged
+	assert.Equal(t, aliceBefore.Balance-500-fees, bc.account(alice).Balance)
+	assert.Equal(t, uint64(500), bc.account(alice).Locked)
+	htlcHash := lock.Hash(TxHasher{})
+
+	wrong := bc.newTx(privBob, HTLCClaimTx{HTLC: htlcHash, Preimage: []byte("guess")})
+	early := bc.newTx(privAlice, HTLCRefundTx{HTLC: htlcHash})
+	assert.Equal(t, 2, len(bc.SoftcheckTransactions([]*Transaction{wrong, early})))
+	receipts = bc.addBlock(wrong, early)
+	assert.Equal(t, ErrHTLCPreimageInvalid.Error(), receipts[0].Err)
+	assert.Equal(t, ErrHTLCNotExpired.Error(), receipts[1].Err)
+
+	// the preimage pays the recipient and is kept for the other side of the swap
+	claim := bc.newTx(privBob, HTLCClaimTx{HTLC: htlcHash, Preimage: secret})
+	again := bc.newTx(privBob, HTLCClaimTx{HTLC: htlcHash, Preimage: secret})
+	bobBefore := bc.account(bob)
+	receipts = bc.addBlock(claim, again)
+	assert.True(t, receipts[0].Succeeded())
+	assert.Equal(t, ErrHTLCClosed.Error(), receipts[1].Err)
+	assert.Equal(t, bobBefore.Balance+500-receipts[0].FeeCharged-receipts[1].FeeCharged, bc.account(bob).Balance)
+	assert.Equal(t, uint64(0), bc.account(alice).Locked)
+	htlc, err := bc.GetHTLC(htlcHash)
+	assert.Nil(t, err)
+	assert.True(t, htlc.Claimed)
+	assert.Equal(t, secret, htlc.Preimage)
+	// the coins of alice are moved by a claim she did not send
+	history, err := bc.GetAccountTransactions(alice, AccountTxQuery{Types: []TxType{TxTypeHTLCClaim}})
+	assert.Nil(t, err)
+	assert.Equal(t, 1, len(history.Txx))
+	assert.Equal(t, claim.Hash(TxHasher{}), history.Txx[0].Hash)
+	assert.Equal(t, []AccountTxRole{AccountTxRoleParticipant}, history.Txx[0].Roles)
+
+	// once timed out the htlc could only be refunded
+	lock = bc.newTx(privAlice, HTLCLockTx{To: bob, Value: 300, Hashlock: hashlock, TimeoutHeight: bc.Height() + 2})
+	bc.addBlock(lock)
+	htlcHash = lock.Hash(TxHasher{})
+	assert.Equal(t, uint64(300), bc.account(alice).Locked)
+	late := bc.newTx(privBob, HTLCClaimTx{HTLC: htlcHash, Preimage: secret})
+	assert.Equal(t, 1, len(bc.SoftcheckTransactions([]*Transaction{late})))
+	refund := bc.newTx(privAlice, HTLCRefundTx{HTLC: htlcHash})
+	refundAgain := bc.newTx(privAlice, HTLCRefundTx{HTLC: htlcHash})
+	aliceBefore = bc.account(alice)
+	receipts = bc.addBlock(late, refund, refundAgain)
+	assert.Equal(t, ErrHTLCExpired.Error(), receipts[0].Err)
+	assert.True(t, receipts[1].Succeeded())
+	assert.Equal(t, ErrHTLCClosed.Error(), receipts[2].Err)
+	assert.Equal(t, aliceBefore.Balance+300-receipts[1].FeeCharged-receipts[2].FeeCharged, bc.account(alice).Balance)
+	assert.Equal(t, uint64(0), bc.account(alice).Locked)
+
+	htlcs, err := bc.GetHTLCsOfAccount(bob)
+	assert.Nil(t, err)
+	assert.Equal(t, 2, len(htlcs))
+	assert.True(t, htlcs[1].Refunded)
+	assertSupplyInvariant(t, bc.BlockChain)
+}
@@ -0,0 +1,145 @@
+package core
+
+import (
+	"blocker/types"
+	"sync"
+)
+
+type InMemoryIndexStore struct {
+	transferState  map[types.Hash]*Transaction
+	accountTxState map[types.Address][]*AccountTx
+	receiptState   map[types.Hash]*Receipt
+	nftHistory     map[types.Hash][]*NFTEvent
+	journal        journal
+	lock           sync.RWMutex
+}
+
+func NewInMemoryIndexStore() *InMemoryIndexStore {
+	store := &InMemoryIndexStore{
+		transferState:  make(map[types.Hash]*Transaction),
+		accountTxState: make(map[types.Address][]*AccountTx),
+		receiptState:   make(map[types.Hash]*Receipt),
+		nftHistory:     make(map[types.Hash][]*NFTEvent),
+	}
+	var _ IndexStore = store
+	return store
+}
+
+func (r *InMemoryIndexStore) PutTransfer(tx *Transaction) error {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+	hash := tx.Hash(TxHasher{})
+	journalEntry(&r.journal, r.transferState, hash)
+	r.transferState[hash] = tx
+	return nil
+}
+
+func (r *InMemoryIndexStore) GetTransfer(hash types.Hash) (*Transaction, error) {
+	r.lock.RLock()
+	defer r.lock.RUnlock()
+	tx, ok := r.transferState[hash]
+	if !ok {
+		return nil, ErrDocNotExisted
+	}
+	return tx, nil
+}
+
+func (r *InMemoryIndexStore) GetTransferOfAccount(addr types.Address) ([]*Transaction, []*Transaction, error) {
+	r.lock.RLock()
+	defer r.lock.RUnlock()
+	fromTxx := []*Transaction{}
+	toTxx := []*Transaction{}
+	for _, tx := range r.transferState {
+		switch transfer := tx.TxInner.(type) {
+		case TransferTx:
+			if transfer.From == addr {
+				fromTxx = append(fromTxx, tx)
+			}
+			if transfer.To == addr {
+				toTxx = append(toTxx, tx)
+			}
+		case BatchTransferTx:
+			if tx.Sender() == addr {
+				fromTxx = append(fromTxx, tx)
+			}
+			for _, output := range transfer.Outputs {
+				if output.To == addr {
+					toTxx = append(toTxx, tx)
+					break
+				}
+			}
+		}
+	}
+	return fromTxx, toTxx, nil
+}
+
+func (r *InMemoryIndexStore) PutAccountTx(addr types.Address, entry *AccountTx) error {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+	journalEntry(&r.journal, r.accountTxState, addr)
+	r.accountTxState[addr] = append(r.accountTxState[addr], entry)
+	return nil
+}
+
+func (r *InMemoryIndexStore) GetAccountTxs(addr types.Address) ([]*AccountTx, error) {
+	r.lock.RLock()
+	defer r.lock.RUnlock()
+	txx := r.accountTxState[addr]
+	res := make([]*AccountTx, len(txx))
+	copy(res, txx)
+	return res, nil
+}
+
+func (r *InMemoryIndexStore) PutReceipt(receipt *Receipt) error {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+	journalEntry(&r.journal, r.receiptState, receipt.TxHash)
+	r.receiptState[receipt.TxHash] = receipt
+	return nil
+}
+
+func (r *InMemoryIndexStore) GetReceipt(txHash types.Hash) (*Receipt, error) {
+	r.lock.RLock()
+	defer r.lock.RUnlock()
+	receipt, ok := r.receiptState[txHash]
+	if !ok {
+		return nil, ErrDocNotExisted
+	}
+	return receipt, nil
+}
+
+func (r *InMemoryIndexStore) PutNFTEvent(nft types.Hash, event *NFTEvent) error {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+	journalEntry(&r.journal, r.nftHistory, nft)
+	r.nftHistory[nft] = append(r.nftHistory[nft], event)
+	return nil
+}
+
+func (r *InMemoryIndexStore) GetNFTHistory(nft types.Hash) ([]*NFTEvent, error) {
+	r.lock.RLock()
+	defer r.lock.RUnlock()
+	history, ok := r.nftHistory[nft]
+	if !ok {
+		return nil, ErrNFTNotExisted
+	}
+	return append([]*NFTEvent{}, history...), nil
+}
+
+func (r *InMemoryIndexStore) Snapshot() int {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+	return r.journal.snapshot()
+}
+
+func (r *InMemoryIndexStore) RevertToSnapshot(id int) {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+	r.journal.revert(id)
+}
+
+func (r *InMemoryIndexStore) Commit() {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+	r.journal.commit()
+}
@@ -0,0 +1,56 @@
+package core
+
+// journal records how to undo the changes made to a store, changes are only recorded
+// between a snapshot and the commit that follows it.
+type journal struct {
+	undo   []func()
+	active bool
+}
+
+func (j *journal) record(undo func()) {
+	if j.active {
+		j.undo = append(j.undo, undo)
+	}
+}
+
+func (j *journal) snapshot() int {
+	j.active = true
+	return len(j.undo)
+}
+
+// revert undoes the changes recorded after the snapshot id, the latest first
+func (j *journal) revert(id int) {
+	for i := len(j.undo) - 1; i >= id; i-- {
+		j.undo[i]()
+	}
+	j.undo = j.undo[:id]
+}
+
+func (j *journal) commit() {
+	j.undo = nil
+	j.active = false
+}
+
+// journalEntry records how to restore the entry of m at key, before it is put or deleted
+func journalEntry[K comparable, V any](j *journal, m map[K]V, key K) {
+	if !j.active {
+		return
+	}
+	prev, ok := m[key]
+	j.record(func() {
+		if ok {
+			m[key] = prev
+		} else {
+			delete(m, key)
+		}
+	})
+}
+
+// journalValue records how to restore the value v points to, before it is changed in place
+func journalValue[V any](j *journal, v *V) {
+	if !j.active {
+		return
+	}
+	prev := *v
+	j.record(func() { *v = prev })
+}
@@ -0,0 +1,154 @@
+package core
+
+import (
+	"blocker/serialize"
+	"blocker/types"
+	"encoding/gob"
+	"errors"
+	"fmt"
+)
+
+var (
+	ErrMarketInvalid      = errors.New("marketplace transaction is invalid")
+	ErrListingNotExisted  = errors.New("listing not existed")
+	ErrOfferNotExisted    = errors.New("offer not existed")
+	ErrMarketPriceChanged = errors.New("price does not match the listing or offer")
+)
+
+// Listing puts the nft on sale at Price, it is removed once the nft changes owner
+type Listing struct {
+	NFT      types.Hash
+	Seller   types.Address
+	Price    uint64
+	Position TxCursor // position of the list transaction in the chain
+}
+
+// Offer is what Buyer would pay for the nft, the coins stay in the account of the buyer until the offer
+// is accepted
+type Offer struct {
+	NFT      types.Hash
+	Buyer    types.Address
+	Price    uint64
+	Position TxCursor // position of the offer transaction in the chain
+}
+
+// NFTListTx lists the nft owned by the sender at Price, it replaces the previous listing of the nft
+type NFTListTx struct {
+	NFT   types.Hash
+	Price uint64
+}
+
+// NFTCancelListingTx removes the listing of the nft, it must be sent by the seller
+type NFTCancelListingTx struct {
+	NFT types.Hash
+}
+
+// NFTBuyTx buys the listed nft, Price must be the price of the listing
+type NFTBuyTx struct {
+	NFT   types.Hash
+	Price uint64
+}
+
+// NFTOfferTx offers Price for the nft, it replaces the previous offer of the sender for the nft.
+// Zero price withdraws the offer.
+type NFTOfferTx struct {
+	NFT   types.Hash
+	Price uint64
+}
+
+// NFTAcceptOfferTx sells the nft to Buyer, it must be sent by the owner and Price must be the price of the offer
+type NFTAcceptOfferTx struct {
+	NFT   types.Hash
+	Buyer types.Address
+	Price uint64
+}
+
+func NewMarketTransaction(inner any, nonce uint64) *Transaction {
+	return &Transaction{
+		TxInner: inner,
+		Nonce:   nonce,
+	}
+}
+
+func (tx *NFTListTx) Bytes() []byte {
+	w := serialize.NewWriter()
+	w.WriteFixed(tx.NFT.Bytes())
+	w.WriteUint64(tx.Price)
+	return w.Bytes()
+}
+
+func (tx *NFTListTx) Validate() error {
+	if tx.NFT.IsZero() || tx.Price == 0 {
+		return fmt.Errorf("%w: nft and price must be set", ErrMarketInvalid)
+	}
+	return nil
+}
+
+func (tx *NFTCancelListingTx) Bytes() []byte {
+	w := serialize.NewWriter()
+	w.WriteFixed(tx.NFT.Bytes())
+	return w.Bytes()
+}
+
+func (tx *NFTCancelListingTx) Validate() error {
+	if tx.NFT.IsZero() {
+		return fmt.Errorf("%w: nft must be set", ErrMarketInvalid)
+	}
+	return nil
+}
+
+func (tx *NFTBuyTx) Bytes() []byte {
+	w := serialize.NewWriter()
+	w.WriteFixed(tx.NFT.Bytes())
+	w.WriteUint64(tx.Price)
+	return w.Bytes()
+}
+
+func (tx *NFTBuyTx) Validate() error {
+	if tx.NFT.IsZero() || tx.Price == 0 {
+		return fmt.Errorf("%w: nft and price must be set", ErrMarketInvalid)
+	}
+	return nil
+}
+
+func (tx *NFTOfferTx) Bytes() []byte {
+	w := serialize.NewWriter()
+	w.WriteFixed(tx.NFT.Bytes())
+	w.WriteUint64(tx.Price)
+	return w.Bytes()
+}
+
+func (tx *NFTOfferTx) Validate() error {
+	if tx.NFT.IsZero() {
+		return fmt.Errorf("%w: nft must be set", ErrMarketInvalid)
+	}
+	return nil
+}
+
+func (tx *NFTAcceptOfferTx) Bytes() []byte {
+	w := serialize.NewWriter()
+	w.WriteFixed(tx.NFT.Bytes())
+	w.WriteFixed(tx.Buyer.Bytes())
+	w.WriteUint64(tx.Price)
+	return w.Bytes()
+}
+
+func (tx *NFTAcceptOfferTx) Validate() error {
+	if tx.NFT.IsZero() || tx.Buyer.IsZero() || tx.Price == 0 {
+		return fmt.Errorf("%w: nft, buyer and price must be set", ErrMarketInvalid)
+	}
+	return nil
+}
+
+type ListingPage struct {
+	Listings []*Listing
+	Next     string // empty mean no more listings
+}
+
+func init() {
+	gob.Register(NFTListTx{})
+	gob.Register(NFTCancelListingTx{})
+	gob.Register(NFTBuyTx{})
+	gob.Register(NFTOfferTx{})
+	gob.Register(NFTAcceptOfferTx{})
+}
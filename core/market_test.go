@@ -0,0 +1,129 @@
+package core
+
+import (
+	"blocker/crypto"
+	"blocker/types"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMarketListingAndOffers(t *testing.T) {
+	privCreator := crypto.GeneratePrivateKey()
+	privAlice := crypto.GeneratePrivateKey()
+	privBob := crypto.GeneratePrivateKey()
+	creator := privCreator.Public().Address()
+	alice := privAlice.Public().Address()
+	bob := privBob.Public().Address()
+	cfg := newTestGenesisConfig()
+	for _, priv := range []*crypto.PrivateKey{privCreator, privAlice, privBob} {
+		cfg.Alloc[priv.Public().Address().String()] = 10000
+	}
+	bc := newTestChain(t, cfg)
+
+	owner := func(nft types.Hash) types.Address {
+		owner, err := bc.GetNFTOwner(nft)
+		assert.Nil(t, err)
+		return owner
+	}
+
+	mintA := bc.newTx(privCreator, signMint(t, privCreator, MintTx{NFT: NFTAsset{Type: NFTAssetTypeImageURL, Data: []byte("a"), Royalty: Royalty{Bps: 1000, Recipient: creator}}}))
+	mintB := bc.newTx(privCreator, signMint(t, privCreator, MintTx{NFT: NFTAsset{Type: NFTAssetTypeImageURL, Data: []byte("b")}}))
+	bc.addBlock(mintA, mintB)
+	nftA, nftB := mintA.Hash(TxHasher{}), mintB.Hash(TxHasher{})
+
+	// only the owner lists
+	stolen := bc.newTx(privAlice, NFTListTx{NFT: nftA, Price: 500})
+	listA := bc.newTx(privCreator, NFTListTx{NFT: nftA, Price: 500})
+	listB := bc.newTx(privCreator, NFTListTx{NFT: nftB, Price: 300})
+	assert.Equal(t, 1, len(bc.SoftcheckTransactions([]*Transaction{stolen, listA, listB})))
+	receipts := bc.addBlock(stolen, listA, listB)
+	assert.Equal(t, ErrNFTNotOwned.Error(), receipts[0].Err)
+	page, err := bc.GetListings(NFTQuery{Limit: 1})
+	assert.Nil(t, err)
+	assert.Equal(t, nftA, page.Listings[0].NFT)
+	page, err = bc.GetListings(NFTQuery{Cursor: page.Next})
+	assert.Nil(t, err)
+	assert.Equal(t, nftB, page.Listings[0].NFT)
+	assert.Empty(t, page.Next)
+
+	// the buyer must pay the listed price, the royalty goes to the creator
+	wrongPrice := bc.newTx(privAlice, NFTBuyTx{NFT: nftA, Price: 400})
+	buy := bc.newTx(privAlice, NFTBuyTx{NFT: nftA, Price: 500})
+	assert.Equal(t, 1, len(bc.SoftcheckTransactions([]*Transaction{wrongPrice, buy})))
+	creatorBalance, aliceBalance := bc.account(creator).Balance, bc.account(alice).Balance
+	fee := buy.EffectiveFee(bc.NextBaseFee())
+	receipts = bc.addBlock(wrongPrice, buy)
+	assert.Equal(t, ErrMarketPriceChanged.Error(), receipts[0].Err)
+	assert.True(t, receipts[1].Succeeded())
+	assert.Equal(t, alice, owner(nftA))
+	assert.Equal(t, creatorBalance+500, bc.account(creator).Balance)
+	assert.Equal(t, aliceBalance-500-2*fee, bc.account(alice).Balance)
+	_, err = bc.GetListing(nftA)
+	assert.ErrorIs(t, err, ErrListingNotExisted)
+	// the seller, known only from the listing, has the sale in its history
+	txs, err := bc.GetAccountTransactions(creator, AccountTxQuery{Types: []TxType{TxTypeNFTBuy}})
+	assert.Nil(t, err)
+	assert.Equal(t, 1, len(txs.Txx))
+	assert.Equal(t, buy.Hash(TxHasher{}), txs.Txx[0].Hash)
+	assert.Equal(t, []AccountTxRole{AccountTxRoleRecipient}, txs.Txx[0].Roles)
+
+	// listing is removed when the nft changes owner or the seller cancels it
+	bc.addBlock(bc.newTx(privAlice, NFTListTx{NFT: nftA, Price: 900}), bc.newTx(privCreator, NFTCancelListingTx{NFT: nftB}))
+	bc.addBlock(bc.newTx(privAlice, NFTTransferTx{NFT: nftA, To: bob}), bc.newTx(privBob, NFTTransferTx{NFT: nftA, To: alice}))
+	listings, err := bc.GetListings(NFTQuery{})
+	assert.Nil(t, err)
+	assert.Empty(t, listings.Listings)
+	staleBuy := bc.newTx(privBob, NFTBuyTx{NFT: nftA, Price: 900})
+	assert.Equal(t, 1, len(bc.SoftcheckTransactions([]*Transaction{staleBuy})))
+	receipts = bc.addBlock(staleBuy)
+	assert.Equal(t, ErrListingNotExisted.Error(), receipts[0].Err)
+
+	// offers are checked against the balance of the buyer when made and when accepted
+	tooHigh := bc.newTx(privBob, NFTOfferTx{NFT: nftA, Price: 100000})
+	offer := bc.newTx(privBob, NFTOfferTx{NFT: nftA, Price: 1000})
+	byOwner := bc.newTx(privAlice, NFTOfferTx{NFT: nftA, Price: 10})
+	assert.Equal(t, 2, len(bc.SoftcheckTransactions([]*Transaction{tooHigh, offer, byOwner})))
+	bc.addBlock(tooHigh, offer, byOwner)
+	offers, err := bc.GetOffersOfNFT(nftA)
+	assert.Nil(t, err)
+	assert.Equal(t, 1, len(offers))
+	assert.Equal(t, uint64(1000), offers[0].Price)
+
+	// the buyer lowers the offer before it is accepted
+	lower := bc.newTx(privBob, NFTOfferTx{NFT: nftA, Price: 800})
+	accept := bc.newTx(privAlice, NFTAcceptOfferTx{NFT: nftA, Buyer: bob, Price: 1000})
+	receipts = bc.addBlock(lower, accept)
+	assert.Equal(t, ErrMarketPriceChanged.Error(), receipts[1].Err)
+	assert.Equal(t, alice, owner(nftA))
+
+	creatorBalance, aliceBalance, bobBalance := bc.account(creator).Balance, bc.account(alice).Balance, bc.account(bob).Balance
+	accept = bc.newTx(privAlice, NFTAcceptOfferTx{NFT: nftA, Buyer: bob, Price: 800})
+	fee = accept.EffectiveFee(bc.NextBaseFee())
+	receipts = bc.addBlock(accept)
+	assert.True(t, receipts[0].Succeeded())
+	assert.Equal(t, bob, owner(nftA))
+	assert.Equal(t, creatorBalance+80, bc.account(creator).Balance)
+	assert.Equal(t, aliceBalance+720-fee, bc.account(alice).Balance)
+	assert.Equal(t, bobBalance-800, bc.account(bob).Balance)
+	offers, err = bc.GetOffersOfNFT(nftA)
+	assert.Nil(t, err)
+	assert.Empty(t, offers)
+
+	// zero price withdraws the offer
+	bc.addBlock(bc.newTx(privAlice, NFTOfferTx{NFT: nftB, Price: 50}))
+	withdraw := bc.newTx(privAlice, NFTOfferTx{NFT: nftB})
+	bc.addBlock(withdraw)
+	offers, err = bc.GetOffersOfNFT(nftB)
+	assert.Nil(t, err)
+	assert.Empty(t, offers)
+	late := bc.newTx(privCreator, NFTAcceptOfferTx{NFT: nftB, Buyer: alice, Price: 50})
+	receipts = bc.addBlock(late)
+	assert.Equal(t, ErrOfferNotExisted.Error(), receipts[0].Err)
+
+	history, err := bc.GetNFTHistory(nftA)
+	assert.Nil(t, err)
+	assert.Equal(t, NFTEventSale, history[len(history)-1].Kind)
+	assert.Equal(t, uint64(800), history[len(history)-1].Price)
+	assertSupplyInvariant(t, bc.BlockChain)
+}
@@ -2,12 +2,10 @@ package core
 
 import (
 	"blocker/crypto"
+	"blocker/serialize"
 	"blocker/types"
-	"bytes"
-	"encoding/binary"
 	"encoding/gob"
 	"fmt"
-	"math/rand"
 )
 
 type MintTx struct {
@@ -19,35 +17,45 @@ type MintTx struct {
 	hash      types.Hash // cached hash
 }
 
-func NewNativeMintTransacton(tx MintTx) *Transaction {
+func NewNativeMintTransacton(tx MintTx, nonce uint64) *Transaction {
 	return &Transaction{
-		Nonce:   rand.Uint64(),
+		Nonce:   nonce,
 		TxInner: tx,
 	}
 }
 
+// kinds of nft in the canonical encoding
+const (
+	nftKindNone       uint8 = 0x00
+	nftKindCollection uint8 = 0x01
+	nftKindAsset      uint8 = 0x02
+)
+
+// Bytes return the canonical encoding of the mint without owner and signature
 func (tx *MintTx) Bytes() []byte {
-	buf := new(bytes.Buffer)
+	w := serialize.NewWriter()
 	switch nft := tx.NFT.(type) {
 	case NFTCollection:
-		if err := binary.Write(buf, binary.LittleEndian, []byte(nft.Type)); err != nil {
-			panic(err)
+		w.WriteUint8(nftKindCollection)
+		w.WriteString(string(nft.Type))
+		w.WriteUint64(nft.MaxSupply)
+		w.WriteUint32(uint32(len(nft.Minters)))
+		for _, minter := range nft.Minters {
+			w.WriteFixed(minter.Bytes())
 		}
+		w.WriteBool(nft.Mutable)
+		writeRoyalty(w, nft.Royalty)
 	case NFTAsset:
-		if err := binary.Write(buf, binary.LittleEndian, nft.Data); err != nil {
-			panic(err)
-		}
-		if err := binary.Write(buf, binary.LittleEndian, []byte(nft.Type)); err != nil {
-			panic(err)
-		}
-		if err := binary.Write(buf, binary.LittleEndian, nft.Collection); err != nil {
-			panic(err)
-		}
+		w.WriteUint8(nftKindAsset)
+		w.WriteString(string(nft.Type))
+		w.WriteBytes(nft.Data)
+		w.WriteFixed(nft.Collection.Bytes())
+		writeRoyalty(w, nft.Royalty)
+	default:
+		w.WriteUint8(nftKindNone)
 	}
-	if err := binary.Write(buf, binary.LittleEndian, tx.Metadata); err != nil {
-		panic(err)
-	}
-	return buf.Bytes()
+	w.WriteBytes(tx.Metadata)
+	return w.Bytes()
 }
 
 func (tx *MintTx) Sign(privKey *crypto.PrivateKey) error {
@@ -71,6 +79,12 @@ func (tx *MintTx) Verify() error {
 	if !tx.Signature.Verify(tx.Owner, tx.Bytes()) {
 		return fmt.Errorf("invalid NFT signature")
 	}
+	switch nft := tx.NFT.(type) {
+	case NFTCollection:
+		return nft.Validate()
+	case NFTAsset:
+		return nft.Validate()
+	}
 	return nil
 }
 
@@ -0,0 +1,153 @@
+package core
+
+import (
+	"blocker/crypto"
+	"blocker/serialize"
+	"blocker/types"
+	"bytes"
+	"crypto/sha256"
+	"encoding/gob"
+	"errors"
+	"fmt"
+)
+
+var (
+	ErrMultisigInvalid     = errors.New("multisig account is invalid")
+	ErrMultisigNotExisted  = errors.New("multisig account not existed")
+	ErrSigNotEnough        = errors.New("not enough signatures")
+	ErrSignerNotInMultisig = errors.New("signer is not part of the multisig account")
+)
+
+// maxMultisigSigners bounds the size of multisig accounts, every signer is verified in every transaction
+const maxMultisigSigners = 16
+
+// MultisigAccount is the spending rule of a multisig address: Threshold valid signatures of Signers
+type MultisigAccount struct {
+	Threshold uint32
+	Signers   []*crypto.PublicKey
+}
+
+// Bytes return the canonical encoding of the account, its address is derived from it
+func (m *MultisigAccount) Bytes() []byte {
+	w := serialize.NewWriter()
+	w.WriteUint32(m.Threshold)
+	w.WriteUint32(uint32(len(m.Signers)))
+	for _, signer := range m.Signers {
+		writePublicKey(w, signer)
+	}
+	return w.Bytes()
+}
+
+// Address of the multisig account, the same threshold and signers in the same order give the same address
+func (m *MultisigAccount) Address() types.Address {
+	b := sha256.Sum256(append([]byte("multisig"), m.Bytes()...))
+	return types.AddressFromBytes(b[len(b)-len(types.Address{}):])
+}
+
+func (m *MultisigAccount) Validate() error {
+	if len(m.Signers) == 0 || len(m.Signers) > maxMultisigSigners {
+		return fmt.Errorf("%w: signers must be between 1 and %d", ErrMultisigInvalid, maxMultisigSigners)
+	}
+	if m.Threshold == 0 || int(m.Threshold) > len(m.Signers) {
+		return fmt.Errorf("%w: threshold (%d) of (%d) signers", ErrMultisigInvalid, m.Threshold, len(m.Signers))
+	}
+	for i, signer := range m.Signers {
+		if signer == nil || len(signer.Bytes()) == 0 {
+			return fmt.Errorf("%w: signer (%d) is empty", ErrMultisigInvalid, i)
+		}
+		for _, other := range m.Signers[:i] {
+			if bytes.Equal(signer.Bytes(), other.Bytes()) {
+				return fmt.Errorf("%w: duplicated signer (%s)", ErrMultisigInvalid, signer.Address())
+			}
+		}
+	}
+	return nil
+}
+
+// IndexOf returns the index of pubKey in the signers, -1 if it is not a signer
+func (m *MultisigAccount) IndexOf(pubKey *crypto.PublicKey) int {
+	for i, signer := range m.Signers {
+		if bytes.Equal(signer.Bytes(), pubKey.Bytes()) {
+			return i
+		}
+	}
+	return -1
+}
+
+// MultisigSignature is the signature of the transaction by the signer at Index of the multisig account
+type MultisigSignature struct {
+	Index     uint32
+	Signature *crypto.Signature
+}
+
+// MultisigCreateTx registers a multisig account, transactions could be sent from its address after that
+type MultisigCreateTx struct {
+	Account MultisigAccount
+}
+
+func NewMultisigCreateTransaction(account MultisigAccount, nonce uint64) *Transaction {
+	return &Transaction{
+		TxInner: MultisigCreateTx{Account: account},
+		Nonce:   nonce,
+	}
+}
+
+// NewMultisigTransaction creates a transaction sent from the multisig account, it is signed by
+// co-signers with SignMultisig
+func NewMultisigTransaction(account MultisigAccount, inner any, nonce uint64) *Transaction {
+	return &Transaction{
+		Multisig: &account,
+		TxInner:  inner,
+		Nonce:    nonce,
+	}
+}
+
+func (tx *MultisigCreateTx) Bytes() []byte {
+	return tx.Account.Bytes()
+}
+
+// SignMultisig adds the signature of privKey to the transaction sent from a multisig account,
+// every field must be set before the first co-signer signs
+func (tx *Transaction) SignMultisig(privKey *crypto.PrivateKey) error {
+	if tx.Multisig == nil {
+		return ErrMultisigNotExisted
+	}
+	idx := tx.Multisig.IndexOf(privKey.Public())
+	if idx < 0 {
+		return ErrSignerNotInMultisig
+	}
+	sig := MultisigSignature{Index: uint32(idx), Signature: privKey.Sign(tx.Bytes())}
+	for i, existed := range tx.Signatures {
+		if existed.Index == sig.Index {
+			tx.Signatures[i] = sig
+			return nil
+		}
+	}
+	tx.Signatures = append(tx.Signatures, sig)
+	return nil
+}
+
+func (tx *Transaction) verifyMultisig() error {
+	if err := tx.Multisig.Validate(); err != nil {
+		return err
+	}
+	msg := tx.Bytes()
+	seen := map[uint32]bool{}
+	for _, sig := range tx.Signatures {
+		if int(sig.Index) >= len(tx.Multisig.Signers) || seen[sig.Index] || sig.Signature == nil {
+			return ErrSigInvalid
+		}
+		if !sig.Signature.Verify(tx.Multisig.Signers[sig.Index], msg) {
+			return ErrSigInvalid
+		}
+		seen[sig.Index] = true
+	}
+	if len(seen) < int(tx.Multisig.Threshold) {
+		return fmt.Errorf("%w: (%d) of (%d)", ErrSigNotEnough, len(seen), tx.Multisig.Threshold)
+	}
+	return nil
+}
+
+func init() {
+	gob.Register(MultisigCreateTx{})
+}
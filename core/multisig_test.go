@@ -0,0 +1,125 @@
+package core
+
+import (
+	"blocker/crypto"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func newTestMultisig(threshold uint32, n int) (MultisigAccount, []*crypto.PrivateKey) {
+	privs := []*crypto.PrivateKey{}
+	account := MultisigAccount{Threshold: threshold}
+	for i := 0; i < n; i++ {
+		priv := crypto.GeneratePrivateKey()
+		privs = append(privs, priv)
+		account.Signers = append(account.Signers, priv.Public())
+	}
+	return account, privs
+}
+
+func TestMultisigAccountValidate(t *testing.T) {
+	account, privs := newTestMultisig(2, 3)
+	assert.Nil(t, account.Validate())
+	assert.Equal(t, account.Address(), (&MultisigAccount{Threshold: 2, Signers: account.Signers}).Address())
+	assert.NotEqual(t, account.Address(), (&MultisigAccount{Threshold: 1, Signers: account.Signers}).Address())
+
+	assert.ErrorIs(t, (&MultisigAccount{Threshold: 0, Signers: account.Signers}).Validate(), ErrMultisigInvalid)
+	assert.ErrorIs(t, (&MultisigAccount{Threshold: 4, Signers: account.Signers}).Validate(), ErrMultisigInvalid)
+	duplicated := &MultisigAccount{Threshold: 1, Signers: []*crypto.PublicKey{privs[0].Public(), privs[0].Public()}}
+	assert.ErrorIs(t, duplicated.Validate(), ErrMultisigInvalid)
+}
+
+func TestMultisigTransactionVerify(t *testing.T) {
+	account, privs := newTestMultisig(2, 3)
+	to := crypto.GeneratePrivateKey().Public().Address()
+	tx := NewMultisigTransaction(account, TransferTx{From: account.Address(), To: to, Value: 10}, 1)
+	assert.Equal(t, account.Address(), tx.Sender())
+
+	assert.Nil(t, tx.SignMultisig(privs[0]))
+	assert.ErrorIs(t, tx.Verify(), ErrSigNotEnough)
+	// signing twice by the same co-signer does not count twice
+	assert.Nil(t, tx.SignMultisig(privs[0]))
+	assert.ErrorIs(t, tx.Verify(), ErrSigNotEnough)
+	assert.Equal(t, ErrSignerNotInMultisig, tx.SignMultisig(crypto.GeneratePrivateKey()))
+
+	assert.Nil(t, tx.SignMultisig(privs[2]))
+	assert.Nil(t, tx.Verify())
+
+	duplicated := tx.Copy()
+	duplicated.Signatures = append(duplicated.Signatures, duplicated.Signatures[0])
+	assert.Equal(t, ErrSigInvalid, duplicated.Verify())
+
+	tampered := tx.Copy()
+	tampered.TxInner = TransferTx{From: account.Address(), To: to, Value: 1000}
+	assert.Equal(t, ErrSigInvalid, tampered.Verify())
+
+	// a single key cannot sign for the multisig account
+	single := tx.Copy()
+	assert.Nil(t, single.Sign(privs[0]))
+	assert.ErrorIs(t, single.Verify(), ErrSigInvalid)
+}
+
+func TestTransferFromMustBeSender(t *testing.T) {
+	privBob := crypto.GeneratePrivateKey()
+	privAlice := crypto.GeneratePrivateKey()
+	transferTx := TransferTx{From: privBob.Public().Address(), To: privAlice.Public().Address(), Value: 10}
+	assert.Nil(t, transferTx.Sign(privAlice))
+
+	tx := NewNativeTransferTransaction(transferTx, 1)
+	assert.Nil(t, tx.Sign(privAlice))
+	assert.ErrorIs(t, tx.Verify(), ErrSigInvalid)
+}
+
+func TestMultisigTransfer(t *testing.T) {
+	privBob := crypto.GeneratePrivateKey()
+	privAlice := crypto.GeneratePrivateKey()
+	account, privs := newTestMultisig(2, 3)
+	cfg := newTestGenesisConfig()
+	cfg.Alloc[privBob.Public().Address().String()] = 1000
+	cfg.Alloc[account.Address().String()] = 500
+	bc := newTestChain(t, cfg)
+
+	newMultisigTransfer := func(nonce uint64, signers ...*crypto.PrivateKey) *Transaction {
+		transferTx := TransferTx{From: account.Address(), To: privAlice.Public().Address(), Value: 100}
+		tx := NewMultisigTransaction(account, transferTx, nonce)
+		tx.ChainID = bc.ChainID()
+		tx.MaxFee = bc.NextBaseFee() * 2
+		for _, priv := range signers {
+			assert.Nil(t, tx.SignMultisig(priv))
+		}
+		return tx
+	}
+
+	// multisig account must be registered before it sends transactions
+	tx := newMultisigTransfer(1, privs[0], privs[1])
+	assert.Equal(t, 1, len(bc.SoftcheckTransactions([]*Transaction{tx})))
+	assert.ErrorIs(t, bc.AddBlock(bc.newBlock(tx)), ErrMultisigNotExisted)
+
+	createTx := bc.newTx(privBob, MultisigCreateTx{Account: account})
+	bc.addBlock(createTx)
+	registered, err := bc.GetMultisig(account.Address())
+	assert.Nil(t, err)
+	assert.Equal(t, account.Threshold, registered.Threshold)
+	assert.Equal(t, 1, len(bc.SoftcheckTransactions([]*Transaction{createTx})))
+
+	tx = newMultisigTransfer(1, privs[0], privs[2])
+	assert.Equal(t, 0, len(bc.SoftcheckTransactions([]*Transaction{tx})))
+	fee := tx.EffectiveFee(bc.NextBaseFee())
+	receipt := bc.addBlock(tx)[0]
+	assert.True(t, receipt.Succeeded())
+
+	state, err := bc.GetAccountState(account.Address())
+	assert.Nil(t, err)
+	assert.Equal(t, 500-100-fee, state.Balance)
+	assert.Equal(t, uint64(1), state.Nonce)
+	aliceState, err := bc.GetAccountState(privAlice.Public().Address())
+	assert.Nil(t, err)
+	assert.Equal(t, uint64(100), aliceState.Balance)
+
+	// one co-signer alone cannot move the funds
+	tx = newMultisigTransfer(2, privs[1])
+	assert.Equal(t, 1, len(bc.SoftcheckTransactions([]*Transaction{tx})))
+	assert.ErrorIs(t, bc.AddBlock(bc.newBlock(tx)), ErrSigNotEnough)
+	assertSupplyInvariant(t, bc.BlockChain)
+}
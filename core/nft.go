@@ -3,6 +3,8 @@ package core
 import (
 	"blocker/types"
 	"encoding/gob"
+	"fmt"
+	"slices"
 )
 
 const (
@@ -18,6 +20,7 @@ type (
 		Type       NFTAssetType
 		Data       []byte
 		Collection types.Hash // MintTx of collection
+		Royalty    Royalty    // used when the collection has no royalty
 	}
 )
 
@@ -39,10 +42,30 @@ func NewNFT(typ NFTAssetType, data []byte) *NFTAsset {
 type (
 	NFTCollectionType string
 	NFTCollection     struct {
-		Type NFTCollectionType
+		Type      NFTCollectionType
+		MaxSupply uint64          // zero is unlimited
+		Minters   []types.Address // could mint into the collection besides its owner
+		Mutable   bool            // the owner could update the metadata of the nft of the collection
+		Royalty   Royalty         // paid on sales of every nft of the collection
 	}
 )
 
+func (nft *NFTAsset) Validate() error {
+	return nft.Royalty.Validate()
+}
+
+func (nft *NFTCollection) Validate() error {
+	if len(nft.Minters) > maxCollectionMinters {
+		return fmt.Errorf("%w: at most %d minters", ErrCollectionInvalid, maxCollectionMinters)
+	}
+	for i, minter := range nft.Minters {
+		if minter.IsZero() || slices.Contains(nft.Minters[:i], minter) {
+			return fmt.Errorf("%w: minter (%s) is empty or duplicated", ErrCollectionInvalid, minter)
+		}
+	}
+	return nft.Royalty.Validate()
+}
+
 func (nft *NFTCollection) Encode(enc Encoder[*NFTCollection]) error {
 	return enc.Encode(nft)
 }
@@ -0,0 +1,59 @@
+package core
+
+import "sort"
+
+const (
+	defaultNFTLimit = 20
+	maxNFTLimit     = 100
+)
+
+// NFTQuery paginates nft and collections in the order they are minted.
+// Cursor is exclusive, it is the position of the last item of the previous page, empty start from the beginning.
+type NFTQuery struct {
+	Cursor string
+	Limit  int
+}
+
+type NFTPage struct {
+	NFTs []*NFTState
+	Next string // empty mean no more nft
+}
+
+type CollectionPage struct {
+	Collections []*CollectionState
+	Next        string // empty mean no more collections
+}
+
+// paginateByPosition returns the page of items after the cursor, items must be sorted ascending by position
+func paginateByPosition[T any](items []T, position func(T) TxCursor, query NFTQuery) ([]T, string, error) {
+	limit := query.Limit
+	if limit <= 0 {
+		limit = defaultNFTLimit
+	}
+	if limit > maxNFTLimit {
+		limit = maxNFTLimit
+	}
+	start := 0
+	if query.Cursor != "" {
+		cursor, err := ParseTxCursor(query.Cursor)
+		if err != nil {
+			return nil, "", err
+		}
+		start = sort.Search(len(items), func(i int) bool { return cursor.Less(position(items[i])) })
+	}
+	end := min(start+limit, len(items))
+	page := items[start:end]
+	next := ""
+	if end < len(items) {
+		next = position(page[len(page)-1]).String()
+	}
+	return page, next, nil
+}
+
+func paginateNFTs(states []*NFTState, query NFTQuery) (*NFTPage, error) {
+	nfts, next, err := paginateByPosition(states, func(s *NFTState) TxCursor { return s.Position }, query)
+	if err != nil {
+		return nil, err
+	}
+	return &NFTPage{NFTs: nfts, Next: next}, nil
+}
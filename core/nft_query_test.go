@@ -0,0 +1,90 @@
+package core
+
+import (
+	"blocker/crypto"
+	"blocker/types"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNFTQuery(t *testing.T) {
+	privOwner := crypto.GeneratePrivateKey()
+	privAlice := crypto.GeneratePrivateKey()
+	owner := privOwner.Public().Address()
+	alice := privAlice.Public().Address()
+	cfg := newTestGenesisConfig()
+	cfg.Alloc[owner.String()] = 1000
+	cfg.Alloc[alice.String()] = 1000
+	bc := newTestChain(t, cfg)
+	addBlock := func(txx ...*Transaction) {
+		for _, receipt := range bc.addBlock(txx...) {
+			assert.True(t, receipt.Succeeded())
+		}
+	}
+	hashesOf := func(page *NFTPage) []types.Hash {
+		hashes := []types.Hash{}
+		for _, state := range page.NFTs {
+			hashes = append(hashes, state.Hash)
+		}
+		return hashes
+	}
+
+	first := bc.newTx(privOwner, signMint(t, privOwner, MintTx{NFT: NFTCollection{Type: NFTCollectionTypeImage}}))
+	second := bc.newTx(privAlice, signMint(t, privAlice, MintTx{NFT: NFTCollection{Type: NFTCollectionTypeImage}}))
+	addBlock(first, second)
+	collection := first.Hash(TxHasher{})
+	minted := []types.Hash{}
+	for i := 0; i < 3; i++ {
+		mints := []*Transaction{}
+		for j := 0; j < 2; j++ {
+			data := []byte{byte(i), byte(j)}
+			mints = append(mints, bc.newTx(privOwner, signMint(t, privOwner, MintTx{NFT: NFTAsset{Type: NFTAssetTypeImageURL, Data: data, Collection: collection}, Metadata: data})))
+		}
+		addBlock(mints...)
+		for _, tx := range mints {
+			minted = append(minted, tx.Hash(TxHasher{}))
+		}
+	}
+	addBlock(
+		bc.newTx(privOwner, NFTTransferTx{NFT: minted[1], To: alice}),
+		bc.newTx(privOwner, NFTTransferTx{NFT: minted[4], To: alice}),
+		bc.newTx(privOwner, NFTBurnTx{NFT: minted[5]}),
+	)
+
+	page, err := bc.GetNFTsOfOwner(owner, NFTQuery{Limit: 2})
+	assert.Nil(t, err)
+	assert.Equal(t, []types.Hash{minted[0], minted[2]}, hashesOf(page))
+	assert.NotEmpty(t, page.Next)
+	page, err = bc.GetNFTsOfOwner(owner, NFTQuery{Cursor: page.Next, Limit: 2})
+	assert.Nil(t, err)
+	assert.Equal(t, []types.Hash{minted[3]}, hashesOf(page))
+	assert.Empty(t, page.Next)
+
+	page, err = bc.GetNFTsOfOwner(alice, NFTQuery{})
+	assert.Nil(t, err)
+	assert.Equal(t, []types.Hash{minted[1], minted[4]}, hashesOf(page))
+	assert.Equal(t, []byte{2, 0}, page.NFTs[1].Metadata)
+
+	// burned nft is not listed in its collection
+	page, err = bc.GetNFTsOfCollection(collection, NFTQuery{})
+	assert.Nil(t, err)
+	assert.Equal(t, minted[:5], hashesOf(page))
+	for i, state := range page.NFTs {
+		assert.Equal(t, uint64(i+1), state.TokenID)
+	}
+	_, err = bc.GetNFTsOfCollection(types.RandomHash(), NFTQuery{})
+	assert.ErrorIs(t, err, ErrCollectionNotExisted)
+
+	collections, err := bc.GetCollections(NFTQuery{Limit: 1})
+	assert.Nil(t, err)
+	assert.Equal(t, 1, len(collections.Collections))
+	assert.Equal(t, collection, collections.Collections[0].Hash)
+	collections, err = bc.GetCollections(NFTQuery{Cursor: collections.Next})
+	assert.Nil(t, err)
+	assert.Equal(t, second.Hash(TxHasher{}), collections.Collections[0].Hash)
+	assert.Empty(t, collections.Next)
+
+	_, err = bc.GetCollections(NFTQuery{Cursor: "invalid"})
+	assert.ErrorIs(t, err, ErrCursorInvalid)
+}
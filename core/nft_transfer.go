@@ -0,0 +1,68 @@
+package core
+
+import (
+	"blocker/serialize"
+	"blocker/types"
+	"encoding/gob"
+	"errors"
+	"fmt"
+)
+
+var (
+	ErrNFTNotOwned        = errors.New("sender is not the owner of the nft")
+	ErrNFTNotExisted      = errors.New("nft not existed")
+	ErrNFTTransferInvalid = errors.New("nft transfer is invalid")
+)
+
+// NFTTransferTx gives the nft minted by the transaction with hash NFT to To, it must be sent by the current owner
+type NFTTransferTx struct {
+	NFT types.Hash
+	To  types.Address
+}
+
+func NewNFTTransferTransaction(nft types.Hash, to types.Address, nonce uint64) *Transaction {
+	return &Transaction{
+		TxInner: NFTTransferTx{NFT: nft, To: to},
+		Nonce:   nonce,
+	}
+}
+
+func (tx *NFTTransferTx) Bytes() []byte {
+	w := serialize.NewWriter()
+	w.WriteFixed(tx.NFT.Bytes())
+	w.WriteFixed(tx.To.Bytes())
+	return w.Bytes()
+}
+
+func (tx *NFTTransferTx) Validate() error {
+	if tx.NFT.IsZero() || tx.To.IsZero() {
+		return fmt.Errorf("%w: nft and recipient must be set", ErrNFTTransferInvalid)
+	}
+	return nil
+}
+
+type NFTEventKind string
+
+const (
+	NFTEventMint     NFTEventKind = "mint"
+	NFTEventTransfer NFTEventKind = "transfer"
+	NFTEventBurn     NFTEventKind = "burn"
+	NFTEventMetadata NFTEventKind = "metadata"
+	NFTEventSale     NFTEventKind = "sale"
+)
+
+// NFTEvent is one entry of the history of an nft, From is zero for the mint and To is zero for the burn.
+// Metadata is the new metadata of a metadata update, Price is the price of a sale.
+type NFTEvent struct {
+	Kind     NFTEventKind
+	TxHash   types.Hash
+	From     types.Address
+	To       types.Address
+	Height   uint32
+	Metadata []byte
+	Price    uint64
+}
+
+func init() {
+	gob.Register(NFTTransferTx{})
+}
@@ -0,0 +1,58 @@
+package core
+
+import (
+	"blocker/crypto"
+	"blocker/types"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNFTTransfer(t *testing.T) {
+	privBob := crypto.GeneratePrivateKey()
+	privAlice := crypto.GeneratePrivateKey()
+	bob := privBob.Public().Address()
+	alice := privAlice.Public().Address()
+	cfg := newTestGenesisConfig()
+	cfg.Alloc[bob.String()] = 1000
+	cfg.Alloc[alice.String()] = 1000
+	bc := newTestChain(t, cfg)
+
+	mint := bc.newTx(privBob, signMint(t, privBob, MintTx{NFT: NFTAsset{Type: NFTAssetTypeImageURL, Data: []byte("https://nft")}}))
+	bc.addBlock(mint)
+	nft := mint.Hash(TxHasher{})
+	owner, err := bc.GetNFTOwner(nft)
+	assert.Nil(t, err)
+	assert.Equal(t, bob, owner)
+
+	// only the current owner transfers the nft
+	stolen := bc.newTx(privAlice, NFTTransferTx{NFT: nft, To: alice})
+	assert.Equal(t, 1, len(bc.SoftcheckTransactions([]*Transaction{stolen})))
+	transfer := bc.newTx(privBob, NFTTransferTx{NFT: nft, To: alice})
+	assert.Equal(t, 0, len(bc.SoftcheckTransactions([]*Transaction{transfer})))
+	receipts := bc.addBlock(stolen, transfer)
+	assert.Equal(t, ErrNFTNotOwned.Error(), receipts[0].Err)
+	assert.True(t, receipts[1].Succeeded())
+
+	owner, err = bc.GetNFTOwner(nft)
+	assert.Nil(t, err)
+	assert.Equal(t, alice, owner)
+	// the mint transaction keeps the first owner
+	minted, err := bc.store.GetNFT(nft)
+	assert.Nil(t, err)
+	assert.Equal(t, bob, minted.TxInner.(MintTx).Owner.Address())
+
+	back := bc.newTx(privAlice, NFTTransferTx{NFT: nft, To: bob})
+	missing := bc.newTx(privAlice, NFTTransferTx{NFT: types.RandomHash(), To: bob})
+	receipts = bc.addBlock(back, missing)
+	assert.True(t, receipts[0].Succeeded())
+	assert.Equal(t, ErrNFTNotExisted.Error(), receipts[1].Err)
+
+	history, err := bc.GetNFTHistory(nft)
+	assert.Nil(t, err)
+	assert.Equal(t, []*NFTEvent{
+		{Kind: NFTEventMint, TxHash: nft, To: bob, Height: 1},
+		{Kind: NFTEventTransfer, TxHash: transfer.Hash(TxHasher{}), From: bob, To: alice, Height: 2},
+		{Kind: NFTEventTransfer, TxHash: back.Hash(TxHasher{}), From: alice, To: bob, Height: 3},
+	}, history)
+}
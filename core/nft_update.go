@@ -0,0 +1,106 @@
+package core
+
+import (
+	"blocker/serialize"
+	"blocker/types"
+	"encoding/gob"
+	"errors"
+	"fmt"
+)
+
+var (
+	ErrNFTBurned        = errors.New("nft is burned")
+	ErrNFTImmutable     = errors.New("nft metadata is immutable")
+	ErrNFTUpdateInvalid = errors.New("nft update is invalid")
+	ErrNFTNotUpdatable  = errors.New("sender is not the owner of the collection of the nft")
+	ErrCollectionFrozen = errors.New("collection is already immutable")
+)
+
+const maxNFTMetadataLength = 1 << 16
+
+// NFTBurnTx destroys the nft, it must be sent by the current owner
+type NFTBurnTx struct {
+	NFT types.Hash
+}
+
+// NFTMetadataUpdateTx replaces the metadata of the nft, it must be sent by the owner of the collection of the nft
+// while the collection is mutable
+type NFTMetadataUpdateTx struct {
+	NFT      types.Hash
+	Metadata []byte
+}
+
+// CollectionFreezeTx makes the mutable collection immutable for good, it must be sent by the owner of the collection
+type CollectionFreezeTx struct {
+	Collection types.Hash
+}
+
+func NewNFTBurnTransaction(nft types.Hash, nonce uint64) *Transaction {
+	return &Transaction{
+		TxInner: NFTBurnTx{NFT: nft},
+		Nonce:   nonce,
+	}
+}
+
+func NewNFTMetadataUpdateTransaction(nft types.Hash, metadata []byte, nonce uint64) *Transaction {
+	return &Transaction{
+		TxInner: NFTMetadataUpdateTx{NFT: nft, Metadata: metadata},
+		Nonce:   nonce,
+	}
+}
+
+func NewCollectionFreezeTransaction(collection types.Hash, nonce uint64) *Transaction {
+	return &Transaction{
+		TxInner: CollectionFreezeTx{Collection: collection},
+		Nonce:   nonce,
+	}
+}
+
+func (tx *NFTBurnTx) Bytes() []byte {
+	w := serialize.NewWriter()
+	w.WriteFixed(tx.NFT.Bytes())
+	return w.Bytes()
+}
+
+func (tx *NFTBurnTx) Validate() error {
+	if tx.NFT.IsZero() {
+		return fmt.Errorf("%w: nft must be set", ErrNFTUpdateInvalid)
+	}
+	return nil
+}
+
+func (tx *NFTMetadataUpdateTx) Bytes() []byte {
+	w := serialize.NewWriter()
+	w.WriteFixed(tx.NFT.Bytes())
+	w.WriteBytes(tx.Metadata)
+	return w.Bytes()
+}
+
+func (tx *NFTMetadataUpdateTx) Validate() error {
+	if tx.NFT.IsZero() {
+		return fmt.Errorf("%w: nft must be set", ErrNFTUpdateInvalid)
+	}
+	if len(tx.Metadata) > maxNFTMetadataLength {
+		return fmt.Errorf("%w: metadata is larger than %d bytes", ErrNFTUpdateInvalid, maxNFTMetadataLength)
+	}
+	return nil
+}
+
+func (tx *CollectionFreezeTx) Bytes() []byte {
+	w := serialize.NewWriter()
+	w.WriteFixed(tx.Collection.Bytes())
+	return w.Bytes()
+}
+
+func (tx *CollectionFreezeTx) Validate() error {
+	if tx.Collection.IsZero() {
+		return fmt.Errorf("%w: collection must be set", ErrNFTUpdateInvalid)
+	}
+	return nil
+}
+
+func init() {
+	gob.Register(NFTBurnTx{})
+	gob.Register(NFTMetadataUpdateTx{})
+	gob.Register(CollectionFreezeTx{})
+}
@@ -0,0 +1,130 @@
+package core
+
+import (
+	"blocker/crypto"
+	"blocker/types"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNFTBurnAndMetadataUpdate(t *testing.T) {
+	privOwner := crypto.GeneratePrivateKey()
+	privAlice := crypto.GeneratePrivateKey()
+	owner := privOwner.Public().Address()
+	alice := privAlice.Public().Address()
+	cfg := newTestGenesisConfig()
+	cfg.Alloc[owner.String()] = 1000
+	cfg.Alloc[alice.String()] = 1000
+	bc := newTestChain(t, cfg)
+
+	asset := func(collection types.Hash, data string) MintTx {
+		return MintTx{NFT: NFTAsset{Type: NFTAssetTypeImageURL, Data: []byte(data), Collection: collection}, Metadata: []byte(data)}
+	}
+
+	mutable := bc.newTx(privOwner, signMint(t, privOwner, MintTx{NFT: NFTCollection{Type: NFTCollectionTypeImage, Mutable: true}}))
+	frozen := bc.newTx(privOwner, signMint(t, privOwner, MintTx{NFT: NFTCollection{Type: NFTCollectionTypeImage}}))
+	bc.addBlock(mutable, frozen)
+	mutableNFT := bc.newTx(privOwner, signMint(t, privOwner, asset(mutable.Hash(TxHasher{}), "1")))
+	frozenNFT := bc.newTx(privOwner, signMint(t, privOwner, asset(frozen.Hash(TxHasher{}), "2")))
+	standalone := bc.newTx(privAlice, signMint(t, privAlice, asset(types.Hash{}, "3")))
+	bc.addBlock(mutableNFT, frozenNFT, standalone)
+	nft := mutableNFT.Hash(TxHasher{})
+	bc.addBlock(bc.newTx(privOwner, NFTTransferTx{NFT: nft, To: alice}))
+
+	// only the owner of a mutable collection updates the metadata, even when it does not own the nft
+	byHolder := bc.newTx(privAlice, NFTMetadataUpdateTx{NFT: nft, Metadata: []byte("x")})
+	onFrozen := bc.newTx(privOwner, NFTMetadataUpdateTx{NFT: frozenNFT.Hash(TxHasher{}), Metadata: []byte("x")})
+	onStandalone := bc.newTx(privAlice, NFTMetadataUpdateTx{NFT: standalone.Hash(TxHasher{}), Metadata: []byte("x")})
+	update := bc.newTx(privOwner, NFTMetadataUpdateTx{NFT: nft, Metadata: []byte("updated")})
+	assert.Equal(t, 3, len(bc.SoftcheckTransactions([]*Transaction{byHolder, onFrozen, onStandalone, update})))
+	receipts := bc.addBlock(byHolder, onFrozen, onStandalone, update)
+	assert.Equal(t, ErrNFTNotUpdatable.Error(), receipts[0].Err)
+	assert.Equal(t, ErrNFTImmutable.Error(), receipts[1].Err)
+	assert.Equal(t, ErrNFTImmutable.Error(), receipts[2].Err)
+	assert.True(t, receipts[3].Succeeded())
+	state, err := bc.GetNFTState(nft)
+	assert.Nil(t, err)
+	assert.Equal(t, []byte("updated"), state.Metadata)
+
+	// only the current owner burns
+	stolen := bc.newTx(privOwner, NFTBurnTx{NFT: nft})
+	burn := bc.newTx(privAlice, NFTBurnTx{NFT: nft})
+	assert.Equal(t, 1, len(bc.SoftcheckTransactions([]*Transaction{stolen, burn})))
+	receipts = bc.addBlock(stolen, burn)
+	assert.Equal(t, ErrNFTNotOwned.Error(), receipts[0].Err)
+	assert.True(t, receipts[1].Succeeded())
+	state, err = bc.GetNFTState(nft)
+	assert.Nil(t, err)
+	assert.True(t, state.Burned)
+	assert.True(t, state.Owner.IsZero())
+	collection, err := bc.GetCollectionState(mutable.Hash(TxHasher{}))
+	assert.Nil(t, err)
+	assert.Equal(t, uint64(1), collection.Burned)
+
+	// burned nft is gone for good
+	again := bc.newTx(privAlice, NFTBurnTx{NFT: nft})
+	transfer := bc.newTx(privAlice, NFTTransferTx{NFT: nft, To: owner})
+	late := bc.newTx(privOwner, NFTMetadataUpdateTx{NFT: nft, Metadata: []byte("late")})
+	assert.Equal(t, 3, len(bc.SoftcheckTransactions([]*Transaction{again, transfer, late})))
+	receipts = bc.addBlock(again, transfer, late)
+	for _, receipt := range receipts {
+		assert.Equal(t, ErrNFTBurned.Error(), receipt.Err)
+	}
+
+	history, err := bc.GetNFTHistory(nft)
+	assert.Nil(t, err)
+	kinds := []NFTEventKind{}
+	for _, event := range history {
+		kinds = append(kinds, event.Kind)
+	}
+	assert.Equal(t, []NFTEventKind{NFTEventMint, NFTEventTransfer, NFTEventMetadata, NFTEventBurn}, kinds)
+	assert.Equal(t, []byte("updated"), history[2].Metadata)
+	assert.Equal(t, alice, history[3].From)
+	assertSupplyInvariant(t, bc.BlockChain)
+}
+
+func TestCollectionFreeze(t *testing.T) {
+	privOwner := crypto.GeneratePrivateKey()
+	privAlice := crypto.GeneratePrivateKey()
+	owner := privOwner.Public().Address()
+	cfg := newTestGenesisConfig()
+	cfg.Alloc[owner.String()] = 1000
+	cfg.Alloc[privAlice.Public().Address().String()] = 1000
+	bc := newTestChain(t, cfg)
+
+	mintCollection := bc.newTx(privOwner, signMint(t, privOwner, MintTx{NFT: NFTCollection{Type: NFTCollectionTypeImage, Mutable: true}}))
+	bc.addBlock(mintCollection)
+	collection := mintCollection.Hash(TxHasher{})
+	mintNFT := bc.newTx(privOwner, signMint(t, privOwner, MintTx{
+		NFT:      NFTAsset{Type: NFTAssetTypeImageURL, Data: []byte("1"), Collection: collection},
+		Metadata: []byte("1"),
+	}))
+	bc.addBlock(mintNFT)
+	nft := mintNFT.Hash(TxHasher{})
+	receipts := bc.addBlock(bc.newTx(privOwner, NFTMetadataUpdateTx{NFT: nft, Metadata: []byte("before")}))
+	assert.True(t, receipts[0].Succeeded())
+
+	// only the owner of the collection freezes it
+	byAlice := bc.newTx(privAlice, CollectionFreezeTx{Collection: collection})
+	freeze := bc.newTx(privOwner, CollectionFreezeTx{Collection: collection})
+	assert.Equal(t, 1, len(bc.SoftcheckTransactions([]*Transaction{byAlice, freeze})))
+	receipts = bc.addBlock(byAlice, freeze)
+	assert.Equal(t, ErrNFTNotUpdatable.Error(), receipts[0].Err)
+	assert.True(t, receipts[1].Succeeded())
+	state, err := bc.GetCollectionState(collection)
+	assert.Nil(t, err)
+	assert.False(t, state.Mutable)
+
+	// the metadata cannot be updated anymore, and the collection cannot be frozen twice
+	update := bc.newTx(privOwner, NFTMetadataUpdateTx{NFT: nft, Metadata: []byte("after")})
+	again := bc.newTx(privOwner, CollectionFreezeTx{Collection: collection})
+	assert.Equal(t, 2, len(bc.SoftcheckTransactions([]*Transaction{update, again})))
+	receipts = bc.addBlock(update, again)
+	assert.Equal(t, ErrNFTImmutable.Error(), receipts[0].Err)
+	assert.Equal(t, ErrCollectionFrozen.Error(), receipts[1].Err)
+	nftState, err := bc.GetNFTState(nft)
+	assert.Nil(t, err)
+	assert.Equal(t, []byte("before"), nftState.Metadata)
+	assertSupplyInvariant(t, bc.BlockChain)
+}
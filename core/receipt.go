@@ -0,0 +1,76 @@
+package core
+
+import (
+	"blocker/types"
+)
+
+type ReceiptStatus string
+
+const (
+	ReceiptStatusSuccess ReceiptStatus = "success"
+	ReceiptStatusFailed  ReceiptStatus = "failed"
+)
+
+// Log is emitted by the vm for every write into the contract state
+type Log struct {
+	Key   string
+	Value []byte
+}
+
+type StateChangeKind string
+
+const (
+	StateChangeBalance    StateChangeKind = "balance"
+	StateChangeLocked     StateChangeKind = "locked"
+	StateChangeToken      StateChangeKind = "token"
+	StateChangeNonce      StateChangeKind = "nonce"
+	StateChangeNFT        StateChangeKind = "nft"
+	StateChangeCollection StateChangeKind = "collection"
+)
+
+// StateChange is one change made by a transaction, Delta is set for balance, locked, token and nonce,
+// Hash for created or transferred nft, created collection, the vesting of locked coins and the token of token balance.
+type StateChange struct {
+	Kind  StateChangeKind
+	Addr  types.Address
+	Delta int64
+	Hash  types.Hash
+}
+
+// Receipt is the outcome of a transaction executed in a block. Failed transactions are
+// kept in the block, they pay the fee and use their nonce, every other change is reverted.
+type Receipt struct {
+	TxHash       types.Hash
+	BlockHash    types.Hash
+	Height       uint32
+	Index        uint32
+	Status       ReceiptStatus
+	Err          string
+	FeeCharged   uint64 // base fee plus the tip of the validator
+	FeeBurned    uint64
+	GasUsed      uint64
+	Logs         []Log
+	StateChanges []StateChange
+}
+
+func NewReceipt(tx *Transaction) *Receipt {
+	return &Receipt{
+		TxHash:       tx.Hash(TxHasher{}),
+		Status:       ReceiptStatusSuccess,
+		Logs:         []Log{},
+		StateChanges: []StateChange{},
+	}
+}
+
+func (r *Receipt) Succeeded() bool {
+	return r.Status == ReceiptStatusSuccess
+}
+
+func (r *Receipt) fail(err error) {
+	r.Status = ReceiptStatusFailed
+	r.Err = err.Error()
+}
+
+func (r *Receipt) addStateChange(change StateChange) {
+	r.StateChanges = append(r.StateChanges, change)
+}
@@ -0,0 +1,26 @@
+package core
+
+// BlockReward returns the reward of the validator of the block at height,
+// it is halved every HalvingInterval blocks.
+func BlockReward(height uint32, params ChainParams) uint64 {
+	if params.HalvingInterval == 0 {
+		return params.BlockReward
+	}
+	halvings := height / params.HalvingInterval
+	if halvings >= 64 {
+		return 0
+	}
+	return params.BlockReward >> halvings
+}
+
+// Supply tracks the coins in circulation. Coins are issued by genesis allocations and block rewards
+// paid from the coinbase reserve, and destroyed by burning the base fee.
+type Supply struct {
+	Issued uint64
+	Burned uint64
+}
+
+// Circulating returns the sum of every account balance and locked coins
+func (s Supply) Circulating() uint64 {
+	return s.Issued - s.Burned
+}
@@ -0,0 +1,100 @@
+package core
+
+import (
+	"blocker/crypto"
+	"testing"
+
+	"github.com/go-kit/log"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestBlockReward(t *testing.T) {
+	params := ChainParams{BlockReward: 100, HalvingInterval: 10}
+	assert.Equal(t, uint64(100), BlockReward(1, params))
+	assert.Equal(t, uint64(100), BlockReward(9, params))
+	assert.Equal(t, uint64(50), BlockReward(10, params))
+	assert.Equal(t, uint64(25), BlockReward(25, params))
+	assert.Equal(t, uint64(0), BlockReward(10*64, params))
+	assert.Equal(t, uint64(100), BlockReward(1<<31, ChainParams{BlockReward: 100}))
+}
+
+func TestBlockRewardFromCoinbase(t *testing.T) {
+	validator := crypto.GeneratePrivateKey()
+	cfg := newTestGenesisConfig()
+	cfg.Coinbase = 250
+	cfg.Params.TargetBlockTxs = 0
+	cfg.Params.BlockReward = 100
+	cfg.Params.HalvingInterval = 2
+	bc, err := NewBlockChainFromGenesis(cfg, NewInMemoryStorage(), log.NewNopLogger())
+	assert.Nil(t, err)
+	// genesis allocations are issued, the coinbase is kept as reserve
+	assert.Equal(t, Supply{Issued: 5300}, bc.Supply())
+	assert.Equal(t, uint64(250), bc.CoinbaseReserve())
+
+	// reward 100, 50, 50, then what is left of the reserve
+	for i, reward := range []uint64{100, 50, 50, 25, 25, 0} {
+		height := uint32(i + 1)
+		block := RandomBlock(t, height, getPrevBlockHash(t, bc, height-1))
+		block.BaseFee = bc.NextBaseFee()
+		assert.Nil(t, block.ReHash(BlockHasher{}))
+		assert.Nil(t, block.Sign(validator))
+		before := bc.Supply().Issued
+		assert.Nil(t, bc.AddBlock(block))
+		assert.Equal(t, before+reward, bc.Supply().Issued, "height %d", height)
+	}
+
+	state, err := bc.GetAccountState(validator.Public().Address())
+	assert.Nil(t, err)
+	assert.Equal(t, uint64(250), state.Balance)
+	assert.Equal(t, uint64(0), bc.CoinbaseReserve())
+	assert.Equal(t, uint64(5300+250), bc.Supply().Circulating())
+}
+
+func TestSupplyInvariant(t *testing.T) {
+	privBob := crypto.GeneratePrivateKey()
+	privAlice := crypto.GeneratePrivateKey()
+	cfg := newTestGenesisConfig()
+	cfg.Alloc[privBob.Public().Address().String()] = 1000
+	cfg.Params.TargetBlockTxs = 2
+	bc := newTestChain(t, cfg)
+	assertSupplyInvariant(t, bc.BlockChain)
+
+	transfer := TransferTx{From: privBob.Public().Address(), To: privAlice.Public().Address(), Value: 300}
+	assert.Nil(t, transfer.Sign(privBob))
+	bc.addBlock(
+		bc.newTipTx(privBob, transfer, 3),
+		bc.newTipTx(privBob, signMint(t, privBob, MintTx{NFT: NFTCollection{Type: NFTCollectionTypeImage}}), 2),
+		bc.newTipTx(privBob, nil, 1),
+	)
+
+	// mint and data transactions pay their fee too
+	for _, tx := range bc.blocks[1].Transactions {
+		receipt, err := bc.GetReceipt(tx.Hash(TxHasher{}))
+		assert.Nil(t, err)
+		assert.True(t, receipt.Succeeded())
+		assert.Equal(t, tx.EffectiveFee(bc.blocks[1].BaseFee), receipt.FeeCharged)
+	}
+
+	// failed transfer still pays the fee, alice cannot pay the fee at all
+	overdraft := TransferTx{From: privBob.Public().Address(), To: privAlice.Public().Address(), Value: 1 << 20}
+	assert.Nil(t, overdraft.Sign(privBob))
+	failed := bc.newTipTx(privBob, overdraft, 1)
+	unpaid := bc.newTipTx(privAlice, nil, 1000)
+	bc.addBlock(failed, unpaid)
+
+	receipt, err := bc.GetReceipt(failed.Hash(TxHasher{}))
+	assert.Nil(t, err)
+	assert.False(t, receipt.Succeeded())
+	assert.Equal(t, ErrTxInsufficientBalance.Error(), receipt.Err)
+	assert.Equal(t, failed.EffectiveFee(bc.blocks[2].BaseFee), receipt.FeeCharged)
+
+	receipt, err = bc.GetReceipt(unpaid.Hash(TxHasher{}))
+	assert.Nil(t, err)
+	assert.Equal(t, ErrTxInsufficientFee.Error(), receipt.Err)
+	assert.Equal(t, uint64(0), receipt.FeeCharged)
+	assert.Equal(t, uint64(0), receipt.FeeBurned)
+
+	assert.Nil(t, bc.PutNewAccount(crypto.GeneratePrivateKey().Public()))
+	assertSupplyInvariant(t, bc.BlockChain)
+	assert.Greater(t, bc.Supply().Burned, uint64(0))
+}
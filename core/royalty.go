@@ -0,0 +1,107 @@
+package core
+
+import (
+	"blocker/crypto"
+	"blocker/serialize"
+	"blocker/types"
+	"encoding/gob"
+	"errors"
+	"fmt"
+)
+
+var (
+	ErrRoyaltyInvalid  = errors.New("royalty is invalid")
+	ErrNFTSaleInvalid  = errors.New("nft sale is invalid")
+	ErrNFTSaleReplayed = errors.New("nft sale does not match the transfers of the nft")
+)
+
+// maxRoyaltyBps is 100%, royalties are in basis points of the sale price
+const maxRoyaltyBps = 10000
+
+// Royalty is paid to Recipient on every sale of the nft, zero Bps is no royalty
+type Royalty struct {
+	Bps       uint32
+	Recipient types.Address
+}
+
+func (r *Royalty) Validate() error {
+	if r.Bps > maxRoyaltyBps {
+		return fmt.Errorf("%w: basis points must be at most %d", ErrRoyaltyInvalid, maxRoyaltyBps)
+	}
+	if (r.Bps == 0) != r.Recipient.IsZero() {
+		return fmt.Errorf("%w: basis points and recipient must be set together", ErrRoyaltyInvalid)
+	}
+	return nil
+}
+
+// Amount returns the royalty of a sale at price
+func (r *Royalty) Amount(price uint64) uint64 {
+	return mulDiv(price, uint64(r.Bps), maxRoyaltyBps)
+}
+
+func writeRoyalty(w *serialize.Writer, r Royalty) {
+	w.WriteUint32(r.Bps)
+	w.WriteFixed(r.Recipient.Bytes())
+}
+
+func readRoyalty(r *serialize.Reader) Royalty {
+	return Royalty{
+		Bps:       r.ReadUint32(),
+		Recipient: types.AddressFromBytes(r.ReadFixed(len(types.Address{}))),
+	}
+}
+
+// NFTSaleTx sells the nft to Buyer at Price, it is signed by the seller and sent by the buyer. The buyer pays
+// the price, the royalty of the nft goes to its recipient and the rest to the seller. Transfers must be the
+// transfer count of the nft so the sale cannot be replayed once the nft changed hands.
+type NFTSaleTx struct {
+	NFT       types.Hash
+	Buyer     types.Address
+	Price     uint64
+	Transfers uint64
+	Seller    *crypto.PublicKey
+	Signature *crypto.Signature
+}
+
+func NewNFTSaleTransaction(saleTx NFTSaleTx, nonce uint64) *Transaction {
+	return &Transaction{
+		TxInner: saleTx,
+		Nonce:   nonce,
+	}
+}
+
+// Bytes return the canonical encoding of the sale without seller and signature
+func (tx *NFTSaleTx) Bytes() []byte {
+	w := serialize.NewWriter()
+	w.WriteFixed(tx.NFT.Bytes())
+	w.WriteFixed(tx.Buyer.Bytes())
+	w.WriteUint64(tx.Price)
+	w.WriteUint64(tx.Transfers)
+	return w.Bytes()
+}
+
+func (tx *NFTSaleTx) Sign(priv *crypto.PrivateKey) error {
+	tx.Signature = priv.Sign(tx.Bytes())
+	tx.Seller = priv.Public()
+	return nil
+}
+
+func (tx *NFTSaleTx) Verify() error {
+	if tx.NFT.IsZero() || tx.Buyer.IsZero() {
+		return fmt.Errorf("%w: nft and buyer must be set", ErrNFTSaleInvalid)
+	}
+	if tx.Signature == nil || tx.Seller == nil {
+		return ErrSigNotExisted
+	}
+	if !tx.Signature.Verify(tx.Seller, tx.Bytes()) {
+		return ErrSigInvalid
+	}
+	if tx.Seller.Address() == tx.Buyer {
+		return fmt.Errorf("%w: seller cannot buy its own nft", ErrNFTSaleInvalid)
+	}
+	return nil
+}
+
+func init() {
+	gob.Register(NFTSaleTx{})
+}
@@ -0,0 +1,101 @@
+package core
+
+import (
+	"blocker/crypto"
+	"blocker/types"
+	"math"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRoyalty(t *testing.T) {
+	recipient := types.Address{0x01}
+	assert.Nil(t, (&Royalty{}).Validate())
+	assert.Nil(t, (&Royalty{Bps: maxRoyaltyBps, Recipient: recipient}).Validate())
+	assert.ErrorIs(t, (&Royalty{Bps: maxRoyaltyBps + 1, Recipient: recipient}).Validate(), ErrRoyaltyInvalid)
+	assert.ErrorIs(t, (&Royalty{Bps: 100}).Validate(), ErrRoyaltyInvalid)
+	assert.ErrorIs(t, (&Royalty{Recipient: recipient}).Validate(), ErrRoyaltyInvalid)
+
+	royalty := &Royalty{Bps: 250, Recipient: recipient}
+	assert.Equal(t, uint64(25), royalty.Amount(1000))
+	assert.Equal(t, uint64(0), royalty.Amount(39))
+	assert.Equal(t, uint64(math.MaxUint64)/40, royalty.Amount(math.MaxUint64))
+}
+
+func TestNFTSaleRoyalty(t *testing.T) {
+	privCreator := crypto.GeneratePrivateKey()
+	privAlice := crypto.GeneratePrivateKey()
+	privBob := crypto.GeneratePrivateKey()
+	creator := privCreator.Public().Address()
+	alice := privAlice.Public().Address()
+	bob := privBob.Public().Address()
+	cfg := newTestGenesisConfig()
+	for _, priv := range []*crypto.PrivateKey{privCreator, privAlice, privBob} {
+		cfg.Alloc[priv.Public().Address().String()] = 10000
+	}
+	bc := newTestChain(t, cfg)
+
+	sale := func(seller *crypto.PrivateKey, nft types.Hash, buyer types.Address, price, transfers uint64) NFTSaleTx {
+		saleTx := NFTSaleTx{NFT: nft, Buyer: buyer, Price: price, Transfers: transfers}
+		assert.Nil(t, saleTx.Sign(seller))
+		return saleTx
+	}
+
+	collection := bc.newTx(privCreator, signMint(t, privCreator, MintTx{NFT: NFTCollection{
+		Type:    NFTCollectionTypeImage,
+		Royalty: Royalty{Bps: 500, Recipient: creator},
+	}}))
+	bc.addBlock(collection)
+	// royalty of the collection wins over the one of the asset
+	mint := bc.newTx(privCreator, signMint(t, privCreator, MintTx{NFT: NFTAsset{
+		Type:       NFTAssetTypeImageURL,
+		Data:       []byte("art"),
+		Collection: collection.Hash(TxHasher{}),
+		Royalty:    Royalty{Bps: 1, Recipient: alice},
+	}}))
+	bc.addBlock(mint)
+	nft := mint.Hash(TxHasher{})
+	state, err := bc.GetNFTState(nft)
+	assert.Nil(t, err)
+	assert.Equal(t, Royalty{Bps: 500, Recipient: creator}, state.Royalty)
+
+	// the buyer sends the sale signed by the seller
+	primary := sale(privCreator, nft, alice, 2000, 0)
+	byStranger := NewNFTSaleTransaction(primary, 1)
+	byStranger.ChainID = bc.ChainID()
+	assert.Nil(t, byStranger.Sign(privBob))
+	assert.ErrorIs(t, byStranger.Verify(), ErrSigInvalid)
+	receipts := bc.addBlock(bc.newTx(privAlice, primary))
+	assert.True(t, receipts[0].Succeeded())
+
+	creatorBalance, aliceBalance, bobBalance := bc.account(creator).Balance, bc.account(alice).Balance, bc.account(bob).Balance
+	secondary := bc.newTx(privBob, sale(privAlice, nft, bob, 1000, 1))
+	fee := secondary.EffectiveFee(bc.NextBaseFee())
+	assert.Equal(t, 0, len(bc.SoftcheckTransactions([]*Transaction{secondary})))
+	receipts = bc.addBlock(secondary)
+	assert.True(t, receipts[0].Succeeded())
+	assert.Equal(t, creatorBalance+50, bc.account(creator).Balance)
+	assert.Equal(t, aliceBalance+950, bc.account(alice).Balance)
+	assert.Equal(t, bobBalance-1000-fee, bc.account(bob).Balance)
+	owner, err := bc.GetNFTOwner(nft)
+	assert.Nil(t, err)
+	assert.Equal(t, bob, owner)
+
+	// the primary sale cannot be replayed once the nft changed hands, even back to its seller
+	bc.addBlock(bc.newTx(privBob, NFTTransferTx{NFT: nft, To: creator}))
+	replayed := bc.newTx(privAlice, primary)
+	notOwned := bc.newTx(privBob, sale(privAlice, nft, bob, 1, 3))
+	tooExpensive := bc.newTx(privAlice, sale(privCreator, nft, alice, 1000000, 3))
+	assert.Equal(t, 3, len(bc.SoftcheckTransactions([]*Transaction{replayed, notOwned, tooExpensive})))
+	receipts = bc.addBlock(replayed, notOwned, tooExpensive)
+	assert.Equal(t, ErrNFTSaleReplayed.Error(), receipts[0].Err)
+	assert.Equal(t, ErrNFTNotOwned.Error(), receipts[1].Err)
+	assert.Equal(t, ErrTxInsufficientBalance.Error(), receipts[2].Err)
+
+	history, err := bc.GetNFTHistory(nft)
+	assert.Nil(t, err)
+	assert.Equal(t, NFTEventSale, history[2].Kind)
+	assert.Equal(t, uint64(1000), history[2].Price)
+	assertSupplyInvariant(t, bc.BlockChain)
+}
@@ -2,8 +2,16 @@ package core
 
 import "errors"
 
+// ContractState is the key-value state that contracts run by the vm read and write
+type ContractState interface {
+	Put(key string, value []byte) error
+	Get(key string) ([]byte, error)
+	Delete(key string) error
+}
+
 type State struct {
-	data map[string][]byte
+	data    map[string][]byte
+	journal *journal // journal of the store keeping the state, if any
 }
 
 func NewState() *State {
@@ -23,6 +31,7 @@ func (s *State) Put(key string, value []byte) error {
 		return ErrStateExsited
 	}
 
+	s.record(key)
 	s.data[key] = value
 	return nil
 }
@@ -36,6 +45,13 @@ func (s *State) Get(key string) ([]byte, error) {
 }
 
 func (s *State) Delete(key string) error {
+	s.record(key)
 	delete(s.data, key)
 	return nil
 }
+
+func (s *State) record(key string) {
+	if s.journal != nil {
+		journalEntry(s.journal, s.data, key)
+	}
+}
@@ -0,0 +1,680 @@
+package core
+
+import (
+	"blocker/types"
+	"fmt"
+	"math"
+	"slices"
+	"strings"
+	"sync"
+)
+
+type InMemoryStateStore struct {
+	collectionState  map[types.Hash]*Transaction
+	nftState         map[types.Hash]*Transaction
+	nftStates        map[types.Hash]NFTState
+	collectionStates map[types.Hash]CollectionState
+	collections      []types.Hash
+	ownerNFTs        map[types.Address]map[types.Hash]struct{}
+	collectionNFTs   map[types.Hash][]types.Hash
+	listings         map[types.Hash]Listing
+	offers           map[types.Hash]map[types.Address]Offer
+	auctionState     map[types.Hash]Auction
+	auctions         []types.Hash
+	accountState     map[types.Address]*AccountState
+	multisigState    map[types.Address]MultisigAccount
+	vestingState     map[types.Hash]Vesting
+	accountVestings  map[types.Address][]types.Hash
+	htlcState        map[types.Hash]HTLC
+	accountHTLCs     map[types.Address][]types.Hash
+	tokenState       map[types.Hash]Token
+	tokenBalances    map[types.Address]map[types.Hash]uint64
+	contractState    *State
+	coinbase         *AccountState
+	supply           Supply
+	journal          journal
+	lock             sync.RWMutex
+}
+
+func NewInMemoryStateStore() *InMemoryStateStore {
+	store := &InMemoryStateStore{
+		collectionState:  make(map[types.Hash]*Transaction),
+		nftState:         make(map[types.Hash]*Transaction),
+		nftStates:        make(map[types.Hash]NFTState),
+		collectionStates: make(map[types.Hash]CollectionState),
+		ownerNFTs:        make(map[types.Address]map[types.Hash]struct{}),
+		collectionNFTs:   make(map[types.Hash][]types.Hash),
+		listings:         make(map[types.Hash]Listing),
+		offers:           make(map[types.Hash]map[types.Address]Offer),
+		auctionState:     make(map[types.Hash]Auction),
+		accountState:     make(map[types.Address]*AccountState),
+		multisigState:    make(map[types.Address]MultisigAccount),
+		vestingState:     make(map[types.Hash]Vesting),
+		accountVestings:  make(map[types.Address][]types.Hash),
+		htlcState:        make(map[types.Hash]HTLC),
+		accountHTLCs:     make(map[types.Address][]types.Hash),
+		tokenState:       make(map[types.Hash]Token),
+		tokenBalances:    make(map[types.Address]map[types.Hash]uint64),
+	}
+	store.contractState = &State{data: make(map[string][]byte), journal: &store.journal}
+	var _ StateStore = store
+	return store
+}
+
+func (r *InMemoryStateStore) PutNFT(tx *Transaction) error {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+	hash := tx.Hash(TxHasher{})
+	_, ok := r.nftState[hash]
+	if ok {
+		return ErrDocExisted
+	}
+	journalEntry(&r.journal, r.nftState, hash)
+	r.nftState[hash] = tx
+	return nil
+}
+
+func (r *InMemoryStateStore) GetNFT(hash types.Hash) (*Transaction, error) {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+	tx, ok := r.nftState[hash]
+	if !ok {
+		return nil, ErrDocNotExisted
+	}
+	return tx, nil
+}
+
+func (r *InMemoryStateStore) HasNFT(hash types.Hash) bool {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+	_, ok := r.nftState[hash]
+	return ok
+}
+
+func (r *InMemoryStateStore) PutNFTState(state *NFTState) error {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+	if _, ok := r.nftState[state.Hash]; !ok {
+		return ErrNFTNotExisted
+	}
+	prev, ok := r.nftStates[state.Hash]
+	if !ok && !state.Collection.IsZero() {
+		journalEntry(&r.journal, r.collectionNFTs, state.Collection)
+		r.collectionNFTs[state.Collection] = append(r.collectionNFTs[state.Collection], state.Hash)
+	}
+	if ok {
+		journalEntry(&r.journal, r.ownerNFTs[prev.Owner], state.Hash)
+		delete(r.ownerNFTs[prev.Owner], state.Hash)
+	}
+	if !state.Burned {
+		if r.ownerNFTs[state.Owner] == nil {
+			journalEntry(&r.journal, r.ownerNFTs, state.Owner)
+			r.ownerNFTs[state.Owner] = make(map[types.Hash]struct{})
+		}
+		journalEntry(&r.journal, r.ownerNFTs[state.Owner], state.Hash)
+		r.ownerNFTs[state.Owner][state.Hash] = struct{}{}
+	}
+	journalEntry(&r.journal, r.nftStates, state.Hash)
+	r.nftStates[state.Hash] = *state
+	return nil
+}
+
+func (r *InMemoryStateStore) GetNFTState(hash types.Hash) (*NFTState, error) {
+	r.lock.RLock()
+	defer r.lock.RUnlock()
+	state, ok := r.nftStates[hash]
+	if !ok {
+		return nil, ErrNFTNotExisted
+	}
+	return &state, nil
+}
+
+func (r *InMemoryStateStore) GetNFTStatesOfOwner(addr types.Address) ([]*NFTState, error) {
+	r.lock.RLock()
+	defer r.lock.RUnlock()
+	states := []*NFTState{}
+	for hash := range r.ownerNFTs[addr] {
+		state := r.nftStates[hash]
+		states = append(states, &state)
+	}
+	slices.SortFunc(states, func(a, b *NFTState) int { return a.Position.Compare(b.Position) })
+	return states, nil
+}
+
+func (r *InMemoryStateStore) GetNFTStatesOfCollection(hash types.Hash) ([]*NFTState, error) {
+	r.lock.RLock()
+	defer r.lock.RUnlock()
+	if _, ok := r.collectionStates[hash]; !ok {
+		return nil, ErrCollectionNotExisted
+	}
+	states := []*NFTState{}
+	for _, nft := range r.collectionNFTs[hash] {
+		state := r.nftStates[nft]
+		if !state.Burned {
+			states = append(states, &state)
+		}
+	}
+	return states, nil
+}
+
+func (r *InMemoryStateStore) PutCollectionState(state *CollectionState) error {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+	if _, ok := r.collectionState[state.Hash]; !ok {
+		return ErrCollectionNotExisted
+	}
+	if _, ok := r.collectionStates[state.Hash]; !ok {
+		journalValue(&r.journal, &r.collections)
+		r.collections = append(r.collections, state.Hash)
+	}
+	journalEntry(&r.journal, r.collectionStates, state.Hash)
+	r.collectionStates[state.Hash] = *state
+	return nil
+}
+
+func (r *InMemoryStateStore) GetCollectionState(hash types.Hash) (*CollectionState, error) {
+	r.lock.RLock()
+	defer r.lock.RUnlock()
+	state, ok := r.collectionStates[hash]
+	if !ok {
+		return nil, ErrCollectionNotExisted
+	}
+	return &state, nil
+}
+
+func (r *InMemoryStateStore) GetCollectionStates() ([]*CollectionState, error) {
+	r.lock.RLock()
+	defer r.lock.RUnlock()
+	states := []*CollectionState{}
+	for _, hash := range r.collections {
+		state := r.collectionStates[hash]
+		states = append(states, &state)
+	}
+	return states, nil
+}
+
+func (r *InMemoryStateStore) PutListing(listing *Listing) error {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+	journalEntry(&r.journal, r.listings, listing.NFT)
+	r.listings[listing.NFT] = *listing
+	return nil
+}
+
+func (r *InMemoryStateStore) GetListing(nft types.Hash) (*Listing, error) {
+	r.lock.RLock()
+	defer r.lock.RUnlock()
+	listing, ok := r.listings[nft]
+	if !ok {
+		return nil, ErrListingNotExisted
+	}
+	return &listing, nil
+}
+
+func (r *InMemoryStateStore) DeleteListing(nft types.Hash) error {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+	journalEntry(&r.journal, r.listings, nft)
+	delete(r.listings, nft)
+	return nil
+}
+
+func (r *InMemoryStateStore) GetListings() ([]*Listing, error) {
+	r.lock.RLock()
+	defer r.lock.RUnlock()
+	listings := []*Listing{}
+	for _, listing := range r.listings {
+		listing := listing
+		listings = append(listings, &listing)
+	}
+	slices.SortFunc(listings, func(a, b *Listing) int { return a.Position.Compare(b.Position) })
+	return listings, nil
+}
+
+func (r *InMemoryStateStore) PutOffer(offer *Offer) error {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+	if r.offers[offer.NFT] == nil {
+		journalEntry(&r.journal, r.offers, offer.NFT)
+		r.offers[offer.NFT] = make(map[types.Address]Offer)
+	}
+	journalEntry(&r.journal, r.offers[offer.NFT], offer.Buyer)
+	r.offers[offer.NFT][offer.Buyer] = *offer
+	return nil
+}
+
+func (r *InMemoryStateStore) GetOffer(nft types.Hash, buyer types.Address) (*Offer, error) {
+	r.lock.RLock()
+	defer r.lock.RUnlock()
+	offer, ok := r.offers[nft][buyer]
+	if !ok {
+		return nil, ErrOfferNotExisted
+	}
+	return &offer, nil
+}
+
+func (r *InMemoryStateStore) DeleteOffer(nft types.Hash, buyer types.Address) error {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+	journalEntry(&r.journal, r.offers[nft], buyer)
+	delete(r.offers[nft], buyer)
+	if len(r.offers[nft]) == 0 {
+		journalEntry(&r.journal, r.offers, nft)
+		delete(r.offers, nft)
+	}
+	return nil
+}
+
+func (r *InMemoryStateStore) GetOffersOfNFT(nft types.Hash) ([]*Offer, error) {
+	r.lock.RLock()
+	defer r.lock.RUnlock()
+	offers := []*Offer{}
+	for _, offer := range r.offers[nft] {
+		offer := offer
+		offers = append(offers, &offer)
+	}
+	slices.SortFunc(offers, func(a, b *Offer) int { return a.Position.Compare(b.Position) })
+	return offers, nil
+}
+
+func (r *InMemoryStateStore) PutAuction(auction *Auction) error {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+	if _, ok := r.auctionState[auction.Hash]; !ok {
+		journalValue(&r.journal, &r.auctions)
+		r.auctions = append(r.auctions, auction.Hash)
+	}
+	journalEntry(&r.journal, r.auctionState, auction.Hash)
+	r.auctionState[auction.Hash] = *auction
+	return nil
+}
+
+func (r *InMemoryStateStore) GetAuction(hash types.Hash) (*Auction, error) {
+	r.lock.RLock()
+	defer r.lock.RUnlock()
+	auction, ok := r.auctionState[hash]
+	if !ok {
+		return nil, ErrAuctionNotExisted
+	}
+	return &auction, nil
+}
+
+func (r *InMemoryStateStore) GetAuctions() ([]*Auction, error) {
+	r.lock.RLock()
+	defer r.lock.RUnlock()
+	auctions := []*Auction{}
+	for _, hash := range r.auctions {
+		auction := r.auctionState[hash]
+		auctions = append(auctions, &auction)
+	}
+	return auctions, nil
+}
+
+func (r *InMemoryStateStore) PutCollection(tx *Transaction) error {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+	hash := tx.Hash(TxHasher{})
+	_, ok := r.collectionState[hash]
+	if ok {
+		return ErrDocExisted
+	}
+	journalEntry(&r.journal, r.collectionState, hash)
+	r.collectionState[hash] = tx
+	return nil
+}
+
+func (r *InMemoryStateStore) GetCollection(hash types.Hash) (*Transaction, error) {
+	r.lock.Lock()
+	tx, ok := r.collectionState[hash]
+	r.lock.Unlock()
+	if !ok {
+		return nil, ErrDocNotExisted
+	}
+	return tx, nil
+}
+
+func (r *InMemoryStateStore) HasCollection(hash types.Hash) bool {
+	r.lock.Lock()
+	_, ok := r.collectionState[hash]
+	r.lock.Unlock()
+	return ok
+}
+
+func (r *InMemoryStateStore) PutAccount(acc *AccountState) error {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+	journalEntry(&r.journal, r.accountState, acc.Addr)
+	state := *acc
+	r.accountState[acc.Addr] = &state
+	return nil
+}
+
+// GetAccount returns a copy of the state of addr, a missing account is created
+func (r *InMemoryStateStore) GetAccount(addr types.Address) (*AccountState, error) {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+	acc := *r.account(addr)
+	return &acc, nil
+}
+
+func (r *InMemoryStateStore) GetAccounts() ([]*AccountState, error) {
+	r.lock.RLock()
+	defer r.lock.RUnlock()
+	accounts := make([]*AccountState, 0, len(r.accountState))
+	for _, acc := range r.accountState {
+		acc := *acc
+		accounts = append(accounts, &acc)
+	}
+	return accounts, nil
+}
+
+func (r *InMemoryStateStore) Credit(addr types.Address, amount uint64) error {
+	if amount == 0 {
+		return nil
+	}
+	r.lock.Lock()
+	defer r.lock.Unlock()
+	return r.credit(&r.account(addr).Balance, amount)
+}
+
+func (r *InMemoryStateStore) Debit(addr types.Address, amount uint64) error {
+	if amount == 0 {
+		return nil
+	}
+	r.lock.Lock()
+	defer r.lock.Unlock()
+	acc, ok := r.accountState[addr]
+	if !ok {
+		return ErrTxInsufficientBalance
+	}
+	return r.debit(&acc.Balance, amount)
+}
+
+func (r *InMemoryStateStore) CreditLocked(addr types.Address, amount uint64) error {
+	if amount == 0 {
+		return nil
+	}
+	r.lock.Lock()
+	defer r.lock.Unlock()
+	return r.credit(&r.account(addr).Locked, amount)
+}
+
+func (r *InMemoryStateStore) DebitLocked(addr types.Address, amount uint64) error {
+	if amount == 0 {
+		return nil
+	}
+	r.lock.Lock()
+	defer r.lock.Unlock()
+	acc, ok := r.accountState[addr]
+	if !ok {
+		return ErrTxInsufficientBalance
+	}
+	return r.debit(&acc.Locked, amount)
+}
+
+// account returns the state of addr to be changed in place, a missing account is created.
+// The lock must be held.
+func (r *InMemoryStateStore) account(addr types.Address) *AccountState {
+	acc, ok := r.accountState[addr]
+	if !ok {
+		journalEntry(&r.journal, r.accountState, addr)
+		acc = NewAccountStateFromAddr(addr)
+		r.accountState[addr] = acc
+	}
+	return acc
+}
+
+// credit adds amount to the coins v points to, the lock must be held
+func (r *InMemoryStateStore) credit(v *uint64, amount uint64) error {
+	if *v > math.MaxUint64-amount {
+		return ErrTxBalanceOverflow
+	}
+	journalValue(&r.journal, v)
+	*v += amount
+	return nil
+}
+
+// debit takes amount from the coins v points to, the lock must be held
+func (r *InMemoryStateStore) debit(v *uint64, amount uint64) error {
+	if *v < amount {
+		return ErrTxInsufficientBalance
+	}
+	journalValue(&r.journal, v)
+	*v -= amount
+	return nil
+}
+
+func (r *InMemoryStateStore) IncreaseAccountNonce(addr types.Address) error {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+	acc := r.account(addr)
+	journalValue(&r.journal, &acc.Nonce)
+	acc.Nonce += 1
+	return nil
+}
+
+func (r *InMemoryStateStore) AccountStateString() string {
+	r.lock.RLock()
+	defer r.lock.RUnlock()
+
+	str := &strings.Builder{}
+	str.WriteString("=====================ACCOUNT-STATE=====================\n")
+	fmt.Fprintf(str, "coinbase=>%s\n", r.coinbase)
+	for addr, state := range r.accountState {
+		fmt.Fprintf(str, "%s=>%s\n", addr.String(), state)
+	}
+	str.WriteString("=====================END-ACCOUNT-STATE=====================")
+	return str.String()
+}
+
+// GetCoinbaseState returns a copy of the coinbase account, nil if there is none
+func (r *InMemoryStateStore) GetCoinbaseState() *AccountState {
+	r.lock.RLock()
+	defer r.lock.RUnlock()
+	if r.coinbase == nil {
+		return nil
+	}
+	coinbase := *r.coinbase
+	return &coinbase
+}
+
+func (r *InMemoryStateStore) PutCoinbase(acc *AccountState) error {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+	if r.coinbase != nil {
+		return ErrDocExisted
+	}
+	journalValue(&r.journal, &r.coinbase)
+	coinbase := *acc
+	r.coinbase = &coinbase
+	return nil
+}
+
+func (r *InMemoryStateStore) DebitCoinbase(amount uint64) error {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+	if r.coinbase == nil {
+		return ErrDocNotExisted
+	}
+	return r.debit(&r.coinbase.Balance, amount)
+}
+
+func (r *InMemoryStateStore) GetSupply() Supply {
+	r.lock.RLock()
+	defer r.lock.RUnlock()
+	return r.supply
+}
+
+func (r *InMemoryStateStore) PutSupply(supply Supply) error {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+	journalValue(&r.journal, &r.supply)
+	r.supply = supply
+	return nil
+}
+
+func (r *InMemoryStateStore) PutMultisig(account MultisigAccount) error {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+	addr := account.Address()
+	if _, ok := r.multisigState[addr]; ok {
+		return ErrDocExisted
+	}
+	journalEntry(&r.journal, r.multisigState, addr)
+	r.multisigState[addr] = account
+	return nil
+}
+
+func (r *InMemoryStateStore) GetMultisig(addr types.Address) (*MultisigAccount, error) {
+	r.lock.RLock()
+	defer r.lock.RUnlock()
+	account, ok := r.multisigState[addr]
+	if !ok {
+		return nil, ErrMultisigNotExisted
+	}
+	return &account, nil
+}
+
+func (r *InMemoryStateStore) PutVesting(vesting *Vesting) error {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+	if _, ok := r.vestingState[vesting.Hash]; !ok {
+		journalEntry(&r.journal, r.accountVestings, vesting.Beneficiary)
+		r.accountVestings[vesting.Beneficiary] = append(r.accountVestings[vesting.Beneficiary], vesting.Hash)
+	}
+	journalEntry(&r.journal, r.vestingState, vesting.Hash)
+	r.vestingState[vesting.Hash] = *vesting
+	return nil
+}
+
+func (r *InMemoryStateStore) GetVesting(hash types.Hash) (*Vesting, error) {
+	r.lock.RLock()
+	defer r.lock.RUnlock()
+	vesting, ok := r.vestingState[hash]
+	if !ok {
+		return nil, ErrVestingNotExisted
+	}
+	return &vesting, nil
+}
+
+func (r *InMemoryStateStore) GetVestingsOfAccount(addr types.Address) ([]*Vesting, error) {
+	r.lock.RLock()
+	defer r.lock.RUnlock()
+	vestings := []*Vesting{}
+	for _, hash := range r.accountVestings[addr] {
+		vesting := r.vestingState[hash]
+		vestings = append(vestings, &vesting)
+	}
+	return vestings, nil
+}
+
+func (r *InMemoryStateStore) PutHTLC(htlc *HTLC) error {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+	if _, ok := r.htlcState[htlc.Hash]; !ok {
+		journalEntry(&r.journal, r.accountHTLCs, htlc.Sender)
+		r.accountHTLCs[htlc.Sender] = append(r.accountHTLCs[htlc.Sender], htlc.Hash)
+		if htlc.Recipient != htlc.Sender {
+			journalEntry(&r.journal, r.accountHTLCs, htlc.Recipient)
+			r.accountHTLCs[htlc.Recipient] = append(r.accountHTLCs[htlc.Recipient], htlc.Hash)
+		}
+	}
+	journalEntry(&r.journal, r.htlcState, htlc.Hash)
+	r.htlcState[htlc.Hash] = *htlc
+	return nil
+}
+
+func (r *InMemoryStateStore) GetHTLC(hash types.Hash) (*HTLC, error) {
+	r.lock.RLock()
+	defer r.lock.RUnlock()
+	htlc, ok := r.htlcState[hash]
+	if !ok {
+		return nil, ErrHTLCNotExisted
+	}
+	return &htlc, nil
+}
+
+func (r *InMemoryStateStore) GetHTLCsOfAccount(addr types.Address) ([]*HTLC, error) {
+	r.lock.RLock()
+	defer r.lock.RUnlock()
+	htlcs := []*HTLC{}
+	for _, hash := range r.accountHTLCs[addr] {
+		htlc := r.htlcState[hash]
+		htlcs = append(htlcs, &htlc)
+	}
+	return htlcs, nil
+}
+
+func (r *InMemoryStateStore) PutToken(token *Token) error {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+	journalEntry(&r.journal, r.tokenState, token.Hash)
+	r.tokenState[token.Hash] = *token
+	return nil
+}
+
+func (r *InMemoryStateStore) GetToken(hash types.Hash) (*Token, error) {
+	r.lock.RLock()
+	defer r.lock.RUnlock()
+	token, ok := r.tokenState[hash]
+	if !ok {
+		return nil, ErrTokenNotExisted
+	}
+	return &token, nil
+}
+
+func (r *InMemoryStateStore) GetTokenBalance(addr types.Address, token types.Hash) uint64 {
+	r.lock.RLock()
+	defer r.lock.RUnlock()
+	return r.tokenBalances[addr][token]
+}
+
+func (r *InMemoryStateStore) PutTokenBalance(addr types.Address, token types.Hash, balance uint64) error {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+	balances, ok := r.tokenBalances[addr]
+	if !ok {
+		balances = make(map[types.Hash]uint64)
+		journalEntry(&r.journal, r.tokenBalances, addr)
+		r.tokenBalances[addr] = balances
+	}
+	journalEntry(&r.journal, balances, token)
+	if balance == 0 {
+		delete(balances, token)
+		return nil
+	}
+	balances[token] = balance
+	return nil
+}
+
+func (r *InMemoryStateStore) GetTokenBalancesOfAccount(addr types.Address) (map[types.Hash]uint64, error) {
+	r.lock.RLock()
+	defer r.lock.RUnlock()
+	balances := make(map[types.Hash]uint64, len(r.tokenBalances[addr]))
+	for token, balance := range r.tokenBalances[addr] {
+		balances[token] = balance
+	}
+	return balances, nil
+}
+
+func (r *InMemoryStateStore) ContractState() ContractState {
+	return r.contractState
+}
+
+func (r *InMemoryStateStore) Snapshot() int {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+	return r.journal.snapshot()
+}
+
+func (r *InMemoryStateStore) RevertToSnapshot(id int) {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+	r.journal.revert(id)
+}
+
+func (r *InMemoryStateStore) Commit() {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+	r.journal.commit()
+}
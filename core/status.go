@@ -1,8 +1,18 @@
 package core
 
+import "blocker/types"
+
 type Status string
 
 const (
-	StatusConfirmed Status = "confirmed"
-	StatusPending   Status = "pending"
+	StatusPending   Status = "pending"   // received, not in a block yet
+	StatusIncluded  Status = "included"  // in a block with less than ConfirmationDepth blocks on top
+	StatusConfirmed Status = "confirmed" // in a block with at least ConfirmationDepth blocks on top
+	StatusFinalized Status = "finalized" // at or below the finalized checkpoint, it is never reorganized
 )
+
+// Checkpoint is the last finalized block, blocks at or below its height could not be replaced
+type Checkpoint struct {
+	Height uint32
+	Hash   types.Hash
+}
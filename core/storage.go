@@ -2,256 +2,216 @@ package core
 
 import (
 	"blocker/types"
-	"fmt"
-	"strings"
-	"sync"
 )
 
-type Storage interface {
+// BlockStore keeps the blocks of the chain.
+type BlockStore interface {
 	PutBlock(*Block) error
 	GetBlock(hash types.Hash) (*Block, error)
 	HasBlock(hash types.Hash) bool
+}
+
+// Journal reverts the changes made to a store since a snapshot, the chain takes one before a block
+// is executed and one before each of its transactions, and reverts to it when the block is rejected
+// or the transaction fails. Snapshots nest until Commit.
+type Journal interface {
+	// Snapshot starts recording the changes and returns the id to revert them to
+	Snapshot() int
+	RevertToSnapshot(id int)
+	// Commit keeps every change and stops recording them
+	Commit()
+}
 
+// CollectionStore keeps the collections and their states.
+type CollectionStore interface {
 	PutCollection(*Transaction) error
 	GetCollection(hash types.Hash) (*Transaction, error)
 	HasCollection(hash types.Hash) bool
+	// PutCollectionState put or replace the state of the collection, the mint transaction keeps how it was created
+	PutCollectionState(*CollectionState) error
+	GetCollectionState(hash types.Hash) (*CollectionState, error)
+	// GetCollectionStates returns every collection in the order they are created
+	GetCollectionStates() ([]*CollectionState, error)
+}
 
+// NFTStore keeps the nft and their states.
+type NFTStore interface {
 	PutNFT(*Transaction) error
 	GetNFT(hash types.Hash) (*Transaction, error)
 	HasNFT(hash types.Hash) bool
-
+	// PutNFTState put or replace the state of the nft, the mint transaction keeps the first owner
+	PutNFTState(*NFTState) error
+	GetNFTState(hash types.Hash) (*NFTState, error)
+	// GetNFTStatesOfOwner and GetNFTStatesOfCollection return the nft in the order they are minted, burned nft excluded
+	GetNFTStatesOfOwner(types.Address) ([]*NFTState, error)
+	GetNFTStatesOfCollection(hash types.Hash) ([]*NFTState, error)
+}
+
+// MarketStore keeps the listings and offers of the nft market.
+type MarketStore interface {
+	// PutListing put or replace the listing of the nft, listings are returned in the order they are put
+	PutListing(*Listing) error
+	GetListing(nft types.Hash) (*Listing, error)
+	DeleteListing(nft types.Hash) error
+	GetListings() ([]*Listing, error)
+	// PutOffer put or replace the offer of the buyer for the nft
+	PutOffer(*Offer) error
+	GetOffer(nft types.Hash, buyer types.Address) (*Offer, error)
+	DeleteOffer(nft types.Hash, buyer types.Address) error
+	GetOffersOfNFT(nft types.Hash) ([]*Offer, error)
+}
+
+// AuctionStore keeps the nft auctions.
+type AuctionStore interface {
+	// PutAuction put or replace the auction, auctions are returned in the order they are created
+	PutAuction(*Auction) error
+	GetAuction(hash types.Hash) (*Auction, error)
+	GetAuctions() ([]*Auction, error)
+}
+
+// AccountStore keeps the balances and nonces of the accounts.
+type AccountStore interface {
 	PutAccount(*AccountState) error
 	GetAccount(types.Address) (*AccountState, error)
-	UpdateAccountBalance(types.Address, int) error
+	// GetAccounts returns a copy of every account, in no particular order
+	GetAccounts() ([]*AccountState, error)
+	// Credit adds to the balance of the account, it fails when the balance overflows
+	Credit(types.Address, uint64) error
+	// Debit takes from the balance of the account, it fails when the balance is insufficient
+	Debit(types.Address, uint64) error
+	// CreditLocked and DebitLocked do the same with the coins locked by the account
+	CreditLocked(types.Address, uint64) error
+	DebitLocked(types.Address, uint64) error
 	IncreaseAccountNonce(types.Address) error
 	AccountStateString() string
+}
 
-	GetTransferOfAccount(addr types.Address) (fromTxx []*Transaction, toTxx []*Transaction, err error)
-
-	PutTransfer(*Transaction) error
-	GetTransfer(hash types.Hash) (*Transaction, error)
-
+// SupplyStore keeps the coinbase reserve and the supply of the coin.
+type SupplyStore interface {
 	GetCoinbaseState() *AccountState
 	PutCoinbase(*AccountState) error
-}
+	DebitCoinbase(uint64) error
 
-type InMemoryStorage struct {
-	blockState      map[types.Hash]*Block
-	collectionState map[types.Hash]*Transaction
-	nftState        map[types.Hash]*Transaction
-	accountState    map[types.Address]*AccountState
-	transferState   map[types.Hash]*Transaction
-	coinbase        *AccountState
-	lock            sync.RWMutex
+	GetSupply() Supply
+	PutSupply(Supply) error
 }
 
-func NewInMemoryStorage() *InMemoryStorage {
-	store := &InMemoryStorage{
-		blockState:      make(map[types.Hash]*Block, 10000),
-		collectionState: make(map[types.Hash]*Transaction),
-		nftState:        make(map[types.Hash]*Transaction),
-		accountState:    make(map[types.Address]*AccountState),
-		transferState:   make(map[types.Hash]*Transaction),
-	}
-	var _ Storage = store
-	return store
+// MultisigStore keeps the registered multisig accounts.
+type MultisigStore interface {
+	PutMultisig(MultisigAccount) error
+	GetMultisig(types.Address) (*MultisigAccount, error)
 }
 
-func (s *InMemoryStorage) PutBlock(b *Block) error {
-	hash := b.Hash(BlockHasher{})
-	s.lock.Lock()
-	defer s.lock.Unlock()
-	_, ok := s.blockState[hash]
-	if ok {
-		return ErrDocExisted
-	}
-	s.blockState[hash] = b
-	return nil
+// VestingStore keeps the vestings.
+type VestingStore interface {
+	// PutVesting put or replace the vesting, vestings of an account are kept in the order they are created
+	PutVesting(*Vesting) error
+	GetVesting(hash types.Hash) (*Vesting, error)
+	GetVestingsOfAccount(types.Address) ([]*Vesting, error)
 }
 
-func (r *InMemoryStorage) GetBlock(hash types.Hash) (*Block, error) {
-	r.lock.Lock()
-	defer r.lock.Unlock()
-	b, ok := r.blockState[hash]
-	if !ok {
-		return nil, ErrDocNotExisted
-	}
-	return b, nil
+// HTLCStore keeps the hash time-locked contracts.
+type HTLCStore interface {
+	// PutHTLC put or replace the htlc, htlcs of an account, as sender or recipient, are kept in the order they are locked
+	PutHTLC(*HTLC) error
+	GetHTLC(hash types.Hash) (*HTLC, error)
+	GetHTLCsOfAccount(types.Address) ([]*HTLC, error)
 }
 
-func (r *InMemoryStorage) HasBlock(hash types.Hash) bool {
-	r.lock.Lock()
-	defer r.lock.Unlock()
-	_, ok := r.blockState[hash]
-	return ok
+// TokenStore keeps the fungible tokens and their balances.
+type TokenStore interface {
+	// PutToken put or replace the token
+	PutToken(*Token) error
+	GetToken(hash types.Hash) (*Token, error)
+	GetTokenBalance(addr types.Address, token types.Hash) uint64
+	PutTokenBalance(addr types.Address, token types.Hash, balance uint64) error
+	GetTokenBalancesOfAccount(types.Address) (map[types.Hash]uint64, error)
 }
 
-func (r *InMemoryStorage) PutNFT(tx *Transaction) error {
-	r.lock.Lock()
-	defer r.lock.Unlock()
-	hash := tx.Hash(TxHasher{})
-	_, ok := r.nftState[hash]
-	if ok {
-		return ErrDocExisted
-	}
-	r.nftState[hash] = tx
-	return nil
-}
+// StateStore keeps the account and contract state that transactions are executed against.
+type StateStore interface {
+	Journal
+	CollectionStore
+	NFTStore
+	MarketStore
+	AuctionStore
+	AccountStore
+	SupplyStore
+	MultisigStore
+	VestingStore
+	HTLCStore
+	TokenStore
 
-func (r *InMemoryStorage) GetNFT(hash types.Hash) (*Transaction, error) {
-	r.lock.Lock()
-	defer r.lock.Unlock()
-	tx, ok := r.nftState[hash]
-	if !ok {
-		return nil, ErrDocNotExisted
-	}
-	return tx, nil
+	// ContractState returns the key-value state used by the vm
+	ContractState() ContractState
 }
 
-func (r *InMemoryStorage) HasNFT(hash types.Hash) bool {
-	r.lock.Lock()
-	defer r.lock.Unlock()
-	_, ok := r.nftState[hash]
-	return ok
-}
+// IndexStore keeps the indexes used to query the chain, it could always be rebuilt from the blocks.
+type IndexStore interface {
+	Journal
 
-func (r *InMemoryStorage) PutCollection(tx *Transaction) error {
-	r.lock.Lock()
-	defer r.lock.Unlock()
-	hash := tx.Hash(TxHasher{})
-	_, ok := r.collectionState[hash]
-	if ok {
-		return ErrDocExisted
-	}
-	r.collectionState[hash] = tx
-	return nil
-}
+	GetTransferOfAccount(addr types.Address) (fromTxx []*Transaction, toTxx []*Transaction, err error)
 
-func (r *InMemoryStorage) GetCollection(hash types.Hash) (*Transaction, error) {
-	r.lock.Lock()
-	tx, ok := r.collectionState[hash]
-	r.lock.Unlock()
-	if !ok {
-		return nil, ErrDocNotExisted
-	}
-	return tx, nil
-}
+	// PutAccountTx append entry into the transaction history of the address, entries must be put in chain order
+	PutAccountTx(types.Address, *AccountTx) error
+	GetAccountTxs(types.Address) ([]*AccountTx, error)
 
-func (r *InMemoryStorage) HasCollection(hash types.Hash) bool {
-	r.lock.Lock()
-	_, ok := r.collectionState[hash]
-	r.lock.Unlock()
-	return ok
-}
+	PutTransfer(*Transaction) error
+	GetTransfer(hash types.Hash) (*Transaction, error)
 
-func (r *InMemoryStorage) PutAccount(acc *AccountState) error {
-	r.lock.RLock()
-	defer r.lock.RUnlock()
-	r.accountState[acc.Addr] = acc
-	return nil
-}
+	PutReceipt(*Receipt) error
+	GetReceipt(txHash types.Hash) (*Receipt, error)
 
-func (r *InMemoryStorage) GetAccount(addr types.Address) (*AccountState, error) {
-	r.lock.Lock()
-	defer r.lock.Unlock()
-	acc, ok := r.accountState[addr]
-	if !ok {
-		acc = NewAccountStateFromAddr(addr)
-		r.accountState[addr] = acc
-	}
-	return acc, nil
+	// PutNFTEvent append event into the history of the nft, events must be put in chain order
+	PutNFTEvent(nft types.Hash, event *NFTEvent) error
+	GetNFTHistory(nft types.Hash) ([]*NFTEvent, error)
 }
 
-func (r *InMemoryStorage) PutTransfer(tx *Transaction) error {
-	r.lock.RLock()
-	defer r.lock.RUnlock()
-	r.transferState[tx.Hash(TxHasher{})] = tx
-	return nil
+type Storage interface {
+	BlockStore
+	StateStore
+	IndexStore
 }
 
-func (r *InMemoryStorage) GetTransfer(hash types.Hash) (*Transaction, error) {
-	r.lock.Lock()
-	defer r.lock.Unlock()
-	tx, ok := r.transferState[hash]
-	if !ok {
-		return nil, ErrDocNotExisted
-	}
-	return tx, nil
+// CompositeStorage compose Storage from separated backends, e.g. blocks on disk and state in memory.
+type CompositeStorage struct {
+	BlockStore
+	StateStore
+	IndexStore
+	snapshots [][2]int // ids of the snapshots of the state and the index
 }
 
-func (r *InMemoryStorage) UpdateAccountBalance(addr types.Address, amount int) error {
-	if amount == 0 {
-		return nil
-	}
-	acc, err := r.GetAccount(addr)
-	if err != nil {
-		return err
+func NewCompositeStorage(blocks BlockStore, state StateStore, index IndexStore) *CompositeStorage {
+	store := &CompositeStorage{
+		BlockStore: blocks,
+		StateStore: state,
+		IndexStore: index,
 	}
-
-	if amount > 0 {
-		acc.Balance += uint64(amount)
-	} else {
-		acc.Balance = uint64(int(acc.Balance) + amount)
-	}
-
-	r.accountState[addr] = acc
-	return nil
-}
-
-func (r *InMemoryStorage) IncreaseAccountNonce(addr types.Address) error {
-	acc, err := r.GetAccount(addr)
-	if err != nil {
-		return err
-	}
-	r.lock.RLock()
-	defer r.lock.RUnlock()
-	acc.Nonce += 1
-	r.accountState[addr] = acc
-	return nil
+	var _ Storage = store
+	return store
 }
 
-func (r *InMemoryStorage) AccountStateString() string {
-	r.lock.Lock()
-	acc := r.accountState
-	coinbase := r.coinbase
-	r.lock.Unlock()
-
-	str := &strings.Builder{}
-	str.WriteString("=====================ACCOUNT-STATE=====================\n")
-	fmt.Fprintf(str, "coinbase=>%s\n", coinbase)
-	for addr, state := range acc {
-		fmt.Fprintf(str, "%s=>%s\n", addr.String(), state)
-	}
-	str.WriteString("=====================END-ACCOUNT-STATE=====================")
-	return str.String()
+func (s *CompositeStorage) Snapshot() int {
+	s.snapshots = append(s.snapshots, [2]int{s.StateStore.Snapshot(), s.IndexStore.Snapshot()})
+	return len(s.snapshots) - 1
 }
 
-func (r *InMemoryStorage) GetTransferOfAccount(addr types.Address) ([]*Transaction, []*Transaction, error) {
-	fromTxx := []*Transaction{}
-	toTxx := []*Transaction{}
-	for _, tx := range r.transferState {
-		transfer, ok := tx.TxInner.(TransferTx)
-		if !ok {
-			continue
-		}
-		if transfer.From == addr {
-			fromTxx = append(fromTxx, tx)
-		}
-		if transfer.To == addr {
-			toTxx = append(toTxx, tx)
-		}
-	}
-	return fromTxx, toTxx, nil
+func (s *CompositeStorage) RevertToSnapshot(id int) {
+	s.StateStore.RevertToSnapshot(s.snapshots[id][0])
+	s.IndexStore.RevertToSnapshot(s.snapshots[id][1])
+	s.snapshots = s.snapshots[:id]
 }
 
-func (r *InMemoryStorage) GetCoinbaseState() *AccountState {
-	return r.coinbase
+func (s *CompositeStorage) Commit() {
+	s.StateStore.Commit()
+	s.IndexStore.Commit()
+	s.snapshots = nil
 }
 
-func (r *InMemoryStorage) PutCoinbase(acc *AccountState) error {
-	if r.coinbase != nil {
-		return ErrDocExisted
-	}
-	r.coinbase = acc
-	return nil
+func NewInMemoryStorage() *CompositeStorage {
+	return NewCompositeStorage(
+		NewInMemoryBlockStore(),
+		NewInMemoryStateStore(),
+		NewInMemoryIndexStore(),
+	)
 }
@@ -1,15 +1,18 @@
 package core
 
 import (
+	"blocker/crypto"
 	"blocker/types"
+	"math"
 	"testing"
 
+	"github.com/go-kit/log"
 	"github.com/stretchr/testify/assert"
 )
 
 func TestInMemoryCollection(t *testing.T) {
 	repo := NewInMemoryStorage()
-	tx := NewNativeTransaction(nil)
+	tx := NewNativeTransaction(nil, 0)
 	hash := tx.Hash(TxHasher{})
 	assert.Nil(t, repo.PutCollection(tx))
 	gtx, err := repo.GetCollection(hash)
@@ -27,3 +30,122 @@ func TestInMemoryCollection(t *testing.T) {
 	// assert.Equal(t, 1, len(txx))
 	// assert.Equal(t, gtx, txx[hash])
 }
+
+func TestFileBlockStore(t *testing.T) {
+	store, err := NewFileBlockStore(t.TempDir())
+	assert.Nil(t, err)
+
+	block := RandomBlock(t, 1, types.RandomHash())
+	block.AddTransaction(RandomTxWithSignature(t))
+	assert.Nil(t, block.ReHash(BlockHasher{}))
+	hash := block.Hash(BlockHasher{})
+
+	assert.False(t, store.HasBlock(hash))
+	assert.Nil(t, store.PutBlock(block))
+	assert.True(t, store.HasBlock(hash))
+	assert.Equal(t, ErrDocExisted, store.PutBlock(block))
+
+	decoded, err := store.GetBlock(hash)
+	assert.Nil(t, err)
+	assert.Equal(t, hash, decoded.Hash(BlockHasher{}))
+	assert.Equal(t, block.Header, decoded.Header)
+	assert.Equal(t, len(block.Transactions), len(decoded.Transactions))
+
+	_, err = store.GetBlock(types.RandomHash())
+	assert.Equal(t, ErrDocNotExisted, err)
+}
+
+func TestCompositeStorage(t *testing.T) {
+	blocks, err := NewFileBlockStore(t.TempDir())
+	assert.Nil(t, err)
+	store := NewCompositeStorage(blocks, NewInMemoryStateStore(), NewInMemoryIndexStore())
+
+	bc, err := NewBlockChain(newGenesisBlock(), store, log.NewNopLogger())
+	assert.Nil(t, err)
+	block := RandomBlock(t, 1, getPrevBlockHash(t, bc, 0))
+	assert.Nil(t, bc.AddBlock(block))
+	assert.True(t, blocks.HasBlock(block.Hash(BlockHasher{})))
+}
+
+func TestInMemoryStorageRevertToSnapshot(t *testing.T) {
+	repo := NewInMemoryStorage()
+	addr := crypto.GeneratePrivateKey().Public().Address()
+	nft := types.RandomHash()
+	assert.Nil(t, repo.Credit(addr, 100))
+	assert.Nil(t, repo.PutListing(&Listing{NFT: nft, Price: 10}))
+
+	snapshot := repo.Snapshot()
+	assert.Nil(t, repo.Debit(addr, 40))
+	assert.Nil(t, repo.DeleteListing(nft))
+	assert.Nil(t, repo.PutOffer(&Offer{NFT: nft, Buyer: addr, Price: 5}))
+	assert.Nil(t, repo.PutNFTEvent(nft, &NFTEvent{Kind: NFTEventSale}))
+	assert.Nil(t, repo.ContractState().Put("key", []byte{0x01}))
+	repo.RevertToSnapshot(snapshot)
+	repo.Commit()
+
+	acc, err := repo.GetAccount(addr)
+	assert.Nil(t, err)
+	assert.Equal(t, uint64(100), acc.Balance)
+	listing, err := repo.GetListing(nft)
+	assert.Nil(t, err)
+	assert.Equal(t, uint64(10), listing.Price)
+	_, err = repo.GetOffer(nft, addr)
+	assert.ErrorIs(t, err, ErrOfferNotExisted)
+	_, err = repo.GetNFTHistory(nft)
+	assert.ErrorIs(t, err, ErrNFTNotExisted)
+	_, err = repo.ContractState().Get("key")
+	assert.ErrorIs(t, err, ErrStateNotExsited)
+
+	// changes made after the commit are kept
+	assert.Nil(t, repo.Debit(addr, 40))
+	repo.RevertToSnapshot(repo.Snapshot())
+	repo.Commit()
+	acc, err = repo.GetAccount(addr)
+	assert.Nil(t, err)
+	assert.Equal(t, uint64(60), acc.Balance)
+}
+
+func TestInMemoryStorageCreditDebit(t *testing.T) {
+	repo := NewInMemoryStorage()
+	addr := crypto.GeneratePrivateKey().Public().Address()
+
+	// nothing is changed when the balance would wrap around
+	assert.ErrorIs(t, repo.Debit(addr, 1), ErrTxInsufficientBalance)
+	assert.Nil(t, repo.Credit(addr, 100))
+	assert.ErrorIs(t, repo.Credit(addr, math.MaxUint64), ErrTxBalanceOverflow)
+	assert.ErrorIs(t, repo.Debit(addr, 101), ErrTxInsufficientBalance)
+	assert.Nil(t, repo.Debit(addr, 40))
+	assert.Nil(t, repo.CreditLocked(addr, 30))
+	assert.ErrorIs(t, repo.DebitLocked(addr, 31), ErrTxInsufficientBalance)
+	assert.Nil(t, repo.DebitLocked(addr, 10))
+
+	acc, err := repo.GetAccount(addr)
+	assert.Nil(t, err)
+	assert.Equal(t, uint64(60), acc.Balance)
+	assert.Equal(t, uint64(20), acc.Locked)
+}
+
+func TestInMemoryStorageGetAccountCopy(t *testing.T) {
+	repo := NewInMemoryStorage()
+	addr := crypto.GeneratePrivateKey().Public().Address()
+	assert.Nil(t, repo.Credit(addr, 100))
+
+	// the returned state is a copy, changing it leaves the store untouched
+	acc, err := repo.GetAccount(addr)
+	assert.Nil(t, err)
+	acc.Balance = 0
+	acc, err = repo.GetAccount(addr)
+	assert.Nil(t, err)
+	assert.Equal(t, uint64(100), acc.Balance)
+
+	// an account created by a read is removed by the revert
+	missing := crypto.GeneratePrivateKey().Public().Address()
+	snapshot := repo.Snapshot()
+	_, err = repo.GetAccount(missing)
+	assert.Nil(t, err)
+	repo.RevertToSnapshot(snapshot)
+	repo.Commit()
+	accounts, err := repo.GetAccounts()
+	assert.Nil(t, err)
+	assert.Equal(t, 1, len(accounts))
+}
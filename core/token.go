@@ -0,0 +1,175 @@
+package core
+
+import (
+	"blocker/serialize"
+	"blocker/types"
+	"encoding/gob"
+	"errors"
+	"fmt"
+	"math"
+)
+
+var (
+	ErrTokenInvalid           = errors.New("token transaction is invalid")
+	ErrTokenNotExisted        = errors.New("token not existed")
+	ErrTokenNotAuthorized     = errors.New("sender is not the mint authority of the token")
+	ErrTokenInsufficientFunds = errors.New("given account token balance insufficient")
+)
+
+const (
+	// maxTokenAmount bounds the supply of a token, so every token balance change fits in the receipt
+	maxTokenAmount    = math.MaxInt64
+	maxTokenNameLen   = 64
+	maxTokenSymbolLen = 12
+	maxTokenDecimals  = 18
+)
+
+// Token is a fungible token, it is identified by the hash of the transaction that created it.
+// Supply is what is in circulation, tokens are only minted by the authority, zero authority means fixed supply.
+type Token struct {
+	Hash      types.Hash
+	Name      string
+	Symbol    string
+	Decimals  uint8
+	Supply    uint64
+	Authority types.Address
+	Creator   types.Address
+}
+
+// TokenCreateTx creates a token, the initial supply is credited to the sender
+type TokenCreateTx struct {
+	Name          string
+	Symbol        string
+	Decimals      uint8
+	Supply        uint64
+	MintAuthority types.Address
+}
+
+type TokenTransferTx struct {
+	Token  types.Hash
+	To     types.Address
+	Amount uint64
+}
+
+// TokenMintTx mints new tokens to To, the sender must be the mint authority
+type TokenMintTx struct {
+	Token  types.Hash
+	To     types.Address
+	Amount uint64
+}
+
+// TokenBurnTx destroys tokens of the sender
+type TokenBurnTx struct {
+	Token  types.Hash
+	Amount uint64
+}
+
+func NewTokenTransaction(inner any, nonce uint64) *Transaction {
+	return &Transaction{
+		TxInner: inner,
+		Nonce:   nonce,
+	}
+}
+
+func NewToken(hash types.Hash, creator types.Address, tx TokenCreateTx) *Token {
+	return &Token{
+		Hash:      hash,
+		Name:      tx.Name,
+		Symbol:    tx.Symbol,
+		Decimals:  tx.Decimals,
+		Supply:    tx.Supply,
+		Authority: tx.MintAuthority,
+		Creator:   creator,
+	}
+}
+
+func (tx *TokenCreateTx) Bytes() []byte {
+	w := serialize.NewWriter()
+	w.WriteString(tx.Name)
+	w.WriteString(tx.Symbol)
+	w.WriteUint8(tx.Decimals)
+	w.WriteUint64(tx.Supply)
+	w.WriteFixed(tx.MintAuthority.Bytes())
+	return w.Bytes()
+}
+
+func (tx *TokenCreateTx) Validate() error {
+	if len(tx.Name) == 0 || len(tx.Name) > maxTokenNameLen {
+		return fmt.Errorf("%w: name must be between 1 and %d bytes", ErrTokenInvalid, maxTokenNameLen)
+	}
+	if len(tx.Symbol) == 0 || len(tx.Symbol) > maxTokenSymbolLen {
+		return fmt.Errorf("%w: symbol must be between 1 and %d bytes", ErrTokenInvalid, maxTokenSymbolLen)
+	}
+	if tx.Decimals > maxTokenDecimals {
+		return fmt.Errorf("%w: decimals must be at most %d", ErrTokenInvalid, maxTokenDecimals)
+	}
+	if tx.Supply == 0 && tx.MintAuthority.IsZero() {
+		return fmt.Errorf("%w: token without supply nor mint authority", ErrTokenInvalid)
+	}
+	if tx.Supply > maxTokenAmount {
+		return fmt.Errorf("%w: supply must be at most %d", ErrTokenInvalid, uint64(maxTokenAmount))
+	}
+	return nil
+}
+
+func (tx *TokenTransferTx) Bytes() []byte {
+	w := serialize.NewWriter()
+	w.WriteFixed(tx.Token.Bytes())
+	w.WriteFixed(tx.To.Bytes())
+	w.WriteUint64(tx.Amount)
+	return w.Bytes()
+}
+
+func (tx *TokenTransferTx) Validate() error {
+	if tx.To.IsZero() || tx.Amount == 0 || tx.Amount > maxTokenAmount {
+		return fmt.Errorf("%w: recipient must be set and amount between 1 and %d", ErrTokenInvalid, uint64(maxTokenAmount))
+	}
+	return nil
+}
+
+func (tx *TokenMintTx) Bytes() []byte {
+	w := serialize.NewWriter()
+	w.WriteFixed(tx.Token.Bytes())
+	w.WriteFixed(tx.To.Bytes())
+	w.WriteUint64(tx.Amount)
+	return w.Bytes()
+}
+
+func (tx *TokenMintTx) Validate() error {
+	if tx.To.IsZero() || tx.Amount == 0 || tx.Amount > maxTokenAmount {
+		return fmt.Errorf("%w: recipient must be set and amount between 1 and %d", ErrTokenInvalid, uint64(maxTokenAmount))
+	}
+	return nil
+}
+
+func (tx *TokenBurnTx) Bytes() []byte {
+	w := serialize.NewWriter()
+	w.WriteFixed(tx.Token.Bytes())
+	w.WriteUint64(tx.Amount)
+	return w.Bytes()
+}
+
+func (tx *TokenBurnTx) Validate() error {
+	if tx.Amount == 0 || tx.Amount > maxTokenAmount {
+		return fmt.Errorf("%w: amount must be between 1 and %d", ErrTokenInvalid, uint64(maxTokenAmount))
+	}
+	return nil
+}
+
+// checkMint checks that sender could mint amount of the token
+func (t *Token) checkMint(sender types.Address, amount uint64) error {
+	if t.Authority.IsZero() || t.Authority != sender {
+		return ErrTokenNotAuthorized
+	}
+	if t.Supply > maxTokenAmount-amount {
+		return fmt.Errorf("%w: supply overflows", ErrTokenInvalid)
+	}
+	return nil
+}
+
+func init() {
+	gob.Register(TokenCreateTx{})
+	gob.Register(TokenTransferTx{})
+	gob.Register(TokenMintTx{})
+	gob.Register(TokenBurnTx{})
+}
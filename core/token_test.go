@@ -0,0 +1,79 @@
+package core
+
+import (
+	"blocker/crypto"
+	"blocker/types"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestTokenCreateValidate(t *testing.T) {
+	authority := crypto.GeneratePrivateKey().Public().Address()
+	assert.Nil(t, (&TokenCreateTx{Name: "Loyalty", Symbol: "LOY", Supply: 10}).Validate())
+	assert.Nil(t, (&TokenCreateTx{Name: "Loyalty", Symbol: "LOY", MintAuthority: authority}).Validate())
+	assert.ErrorIs(t, (&TokenCreateTx{Symbol: "LOY", Supply: 10}).Validate(), ErrTokenInvalid)
+	assert.ErrorIs(t, (&TokenCreateTx{Name: "Loyalty", Symbol: "LOYALTYPOINTS", Supply: 10}).Validate(), ErrTokenInvalid)
+	assert.ErrorIs(t, (&TokenCreateTx{Name: "Loyalty", Symbol: "LOY", Decimals: 19, Supply: 10}).Validate(), ErrTokenInvalid)
+	assert.ErrorIs(t, (&TokenCreateTx{Name: "Loyalty", Symbol: "LOY"}).Validate(), ErrTokenInvalid)
+	assert.ErrorIs(t, (&TokenCreateTx{Name: "Loyalty", Symbol: "LOY", Supply: 1 << 63}).Validate(), ErrTokenInvalid)
+}
+
+func TestTokenLifecycle(t *testing.T) {
+	privBob := crypto.GeneratePrivateKey()
+	privAlice := crypto.GeneratePrivateKey()
+	bob := privBob.Public().Address()
+	alice := privAlice.Public().Address()
+	cfg := newTestGenesisConfig()
+	cfg.Alloc[bob.String()] = 1000
+	cfg.Alloc[alice.String()] = 1000
+	bc := newTestChain(t, cfg)
+
+	assertTokenSupply := func(hash types.Hash, supply uint64) {
+		token, err := bc.GetToken(hash)
+		assert.Nil(t, err)
+		assert.Equal(t, supply, token.Supply)
+		assert.Equal(t, supply, bc.GetTokenBalance(bob, hash)+bc.GetTokenBalance(alice, hash))
+	}
+
+	createTx := bc.newTx(privBob, TokenCreateTx{Name: "Loyalty", Symbol: "LOY", Decimals: 2, Supply: 1000, MintAuthority: bob})
+	assert.Equal(t, 0, len(bc.SoftcheckTransactions([]*Transaction{createTx})))
+	receipts := bc.addBlock(createTx)
+	assert.True(t, receipts[0].Succeeded())
+	hash := createTx.Hash(TxHasher{})
+	assertTokenSupply(hash, 1000)
+
+	transferTx := bc.newTx(privBob, TokenTransferTx{Token: hash, To: alice, Amount: 400})
+	overdraft := bc.newTx(privAlice, TokenTransferTx{Token: hash, To: bob, Amount: 401})
+	unknown := bc.newTx(privAlice, TokenTransferTx{Token: types.RandomHash(), To: bob, Amount: 1})
+	receipts = bc.addBlock(transferTx, overdraft, unknown)
+	assert.True(t, receipts[0].Succeeded())
+	assert.Equal(t, ErrTokenInsufficientFunds.Error(), receipts[1].Err)
+	assert.Equal(t, ErrTokenNotExisted.Error(), receipts[2].Err)
+	assert.Equal(t, uint64(600), bc.GetTokenBalance(bob, hash))
+	assert.Equal(t, uint64(400), bc.GetTokenBalance(alice, hash))
+	assertTokenSupply(hash, 1000)
+
+	// only the mint authority mints
+	unauthorized := bc.newTx(privAlice, TokenMintTx{Token: hash, To: alice, Amount: 10})
+	assert.Equal(t, 1, len(bc.SoftcheckTransactions([]*Transaction{unauthorized})))
+	mintTx := bc.newTx(privBob, TokenMintTx{Token: hash, To: alice, Amount: 50})
+	receipts = bc.addBlock(unauthorized, mintTx)
+	assert.Equal(t, ErrTokenNotAuthorized.Error(), receipts[0].Err)
+	assert.True(t, receipts[1].Succeeded())
+	assertTokenSupply(hash, 1050)
+
+	burnTx := bc.newTx(privAlice, TokenBurnTx{Token: hash, Amount: 450})
+	receipts = bc.addBlock(burnTx)
+	assert.True(t, receipts[0].Succeeded())
+	assert.Equal(t, uint64(0), bc.GetTokenBalance(alice, hash))
+	assertTokenSupply(hash, 600)
+
+	balances, err := bc.GetTokenBalancesOfAccount(bob)
+	assert.Nil(t, err)
+	assert.Equal(t, map[types.Hash]uint64{hash: 600}, balances)
+	balances, err = bc.GetTokenBalancesOfAccount(alice)
+	assert.Nil(t, err)
+	assert.Equal(t, 0, len(balances))
+	assertSupplyInvariant(t, bc.BlockChain)
+}
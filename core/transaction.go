@@ -2,11 +2,9 @@ package core
 
 import (
 	"blocker/crypto"
+	"blocker/serialize"
 	"blocker/types"
-	"bytes"
-	"encoding/binary"
 	"fmt"
-	"math/rand"
 	"time"
 )
 
@@ -18,6 +16,34 @@ const (
 	TxTypeMint     TxType = "mint"
 	TxTypeTransfer TxType = "transfer"
 	TxTypeNative   TxType = "native"
+	TxTypeMultisig TxType = "multisig"
+	TxTypeVesting  TxType = "vesting"
+	TxTypeClaim    TxType = "claim"
+	TxTypeBatch    TxType = "batch"
+
+	TxTypeNFTTransfer TxType = "nft_transfer"
+	TxTypeNFTBurn     TxType = "nft_burn"
+	TxTypeNFTUpdate   TxType = "nft_update"
+	TxTypeNFTFreeze   TxType = "nft_freeze"
+	TxTypeNFTSale     TxType = "nft_sale"
+	TxTypeNFTList     TxType = "nft_list"
+	TxTypeNFTUnlist   TxType = "nft_unlist"
+	TxTypeNFTBuy      TxType = "nft_buy"
+	TxTypeNFTOffer    TxType = "nft_offer"
+	TxTypeNFTAccept   TxType = "nft_accept"
+
+	TxTypeAuctionCreate TxType = "auction_create"
+	TxTypeAuctionBid    TxType = "auction_bid"
+	TxTypeAuctionSettle TxType = "auction_settle"
+
+	TxTypeHTLCLock   TxType = "htlc_lock"
+	TxTypeHTLCClaim  TxType = "htlc_claim"
+	TxTypeHTLCRefund TxType = "htlc_refund"
+
+	TxTypeTokenCreate   TxType = "token_create"
+	TxTypeTokenTransfer TxType = "token_transfer"
+	TxTypeTokenMint     TxType = "token_mint"
+	TxTypeTokenBurn     TxType = "token_burn"
 )
 
 type Transaction struct {
@@ -30,14 +56,18 @@ type Transaction struct {
 	hash       types.Hash // Cached hash version of transaction
 	ValidFrom  int64      // unixnano
 	ValidUntil int64      // unixnano
-	Fee        uint64
+	MaxFee     uint64     // most the sender pays, base fee included
+	TipCap     uint64     // most of the fee paid to the validator on top of the base fee
 	Nonce      uint64
+	ChainID    string              // chain the transaction is signed for, replay on other chains are rejected
+	Multisig   *MultisigAccount    // set when the transaction is sent from a multisig account instead of From
+	Signatures []MultisigSignature // signatures of the co-signers of Multisig
 }
 
 func (t Transaction) String() string {
 	from := "unknown"
-	if t.From != nil {
-		from = t.From.Address().String()
+	if t.From != nil || t.Multisig != nil {
+		from = t.Sender().String()
 	}
 	validFrom := "instance"
 	if t.ValidFrom != 0 {
@@ -48,64 +78,153 @@ func (t Transaction) String() string {
 		validTo = time.Until(time.Unix(0, t.ValidUntil)).String()
 	}
 	return fmt.Sprintf(
-		"%s=>[from=%s, Nonce=%v, fee=%v, timestamp=%v, validFrom=%v, validUntil=%v]",
+		"%s=>[from=%s, Nonce=%v, maxFee=%v, tipCap=%v, timestamp=%v, validFrom=%v, validUntil=%v]",
 		t.Hash(TxHasher{}).Short(),
 		from,
 		t.Nonce,
-		t.Fee,
+		t.MaxFee,
+		t.TipCap,
 		t.timeStamp,
 		validFrom,
 		validTo,
 	)
 }
 
-// NewNativeTransaction is deprecated, transaction should be created from account
-func NewNativeTransaction(data []byte) *Transaction {
+// NewNativeTransaction is deprecated, transaction should be created from account.
+// Nonce must be the next nonce of the sender, which is the account nonce plus one.
+func NewNativeTransaction(data []byte, nonce uint64) *Transaction {
 	return &Transaction{
 		Data:  data,
-		Nonce: rand.Uint64(), // TODO: find better way to handle Nonce of user
+		Nonce: nonce,
 	}
 }
 
-func (tx *Transaction) IsTransferTx() bool {
-	_, ok := tx.TxInner.(TransferTx)
-	return ok
+// Type returns the type of the transaction based on its inner transaction
+func (tx *Transaction) Type() TxType {
+	switch tx.TxInner.(type) {
+	case TransferTx:
+		return TxTypeTransfer
+	case MintTx:
+		return TxTypeMint
+	case MultisigCreateTx:
+		return TxTypeMultisig
+	case VestingTransferTx:
+		return TxTypeVesting
+	case VestingClaimTx:
+		return TxTypeClaim
+	case BatchTransferTx:
+		return TxTypeBatch
+	case NFTTransferTx:
+		return TxTypeNFTTransfer
+	case NFTBurnTx:
+		return TxTypeNFTBurn
+	case NFTMetadataUpdateTx:
+		return TxTypeNFTUpdate
+	case CollectionFreezeTx:
+		return TxTypeNFTFreeze
+	case NFTSaleTx:
+		return TxTypeNFTSale
+	case NFTListTx:
+		return TxTypeNFTList
+	case NFTCancelListingTx:
+		return TxTypeNFTUnlist
+	case NFTBuyTx:
+		return TxTypeNFTBuy
+	case NFTOfferTx:
+		return TxTypeNFTOffer
+	case NFTAcceptOfferTx:
+		return TxTypeNFTAccept
+	case AuctionCreateTx:
+		return TxTypeAuctionCreate
+	case AuctionBidTx:
+		return TxTypeAuctionBid
+	case AuctionSettleTx:
+		return TxTypeAuctionSettle
+	case HTLCLockTx:
+		return TxTypeHTLCLock
+	case HTLCClaimTx:
+		return TxTypeHTLCClaim
+	case HTLCRefundTx:
+		return TxTypeHTLCRefund
+	case TokenCreateTx:
+		return TxTypeTokenCreate
+	case TokenTransferTx:
+		return TxTypeTokenTransfer
+	case TokenMintTx:
+		return TxTypeTokenMint
+	case TokenBurnTx:
+		return TxTypeTokenBurn
+	default:
+		return TxTypeNative
+	}
 }
 
-// Bytes return data bytes in the transaction
-func (tx *Transaction) Bytes() []byte {
-	buf := new(bytes.Buffer)
+// ValidAt checks the validity window of the transaction at timestamp (unixnano), zero bound is open
+func (tx *Transaction) ValidAt(timestamp int64) error {
+	if tx.ValidFrom != 0 && timestamp < tx.ValidFrom {
+		return ErrTxNotYetValid
+	}
+	if tx.ValidUntil != 0 && timestamp > tx.ValidUntil {
+		return ErrTxExpired
+	}
+	return nil
+}
 
-	if tx.Data != nil {
-		if err := binary.Write(buf, binary.LittleEndian, tx.Data); err != nil {
-			panic(err)
-		}
+// EffectiveFee returns the fee paid in a block with baseFee: the base fee plus the tip, capped by MaxFee
+func (tx *Transaction) EffectiveFee(baseFee uint64) uint64 {
+	if tx.MaxFee < baseFee {
+		return tx.MaxFee
 	}
+	return baseFee + min(tx.TipCap, tx.MaxFee-baseFee)
+}
 
-	if tx.TxInner != nil {
-		switch txInner := tx.TxInner.(type) {
-		case TransferTx:
-			if err := binary.Write(buf, binary.LittleEndian, txInner.Bytes()); err != nil {
-				panic(err)
-			}
-		case MintTx:
-			if err := binary.Write(buf, binary.LittleEndian, txInner.Bytes()); err != nil {
-				panic(err)
-			}
-		}
+// EffectiveTip returns the part of the effective fee paid to the validator
+func (tx *Transaction) EffectiveTip(baseFee uint64) uint64 {
+	fee := tx.EffectiveFee(baseFee)
+	if fee < baseFee {
+		return 0
 	}
+	return fee - baseFee
+}
 
-	if err := binary.Write(buf, binary.LittleEndian, tx.Nonce); err != nil {
-		panic(err)
+// Sender returns the account that sends the transaction, pays its fee and uses its nonce
+func (tx *Transaction) Sender() types.Address {
+	if tx.Multisig != nil {
+		return tx.Multisig.Address()
+	}
+	if tx.From == nil {
+		return types.Address{}
 	}
+	return tx.From.Address()
+}
 
-	return buf.Bytes()
+func (tx *Transaction) IsTransferTx() bool {
+	_, ok := tx.TxInner.(TransferTx)
+	return ok
+}
+
+// Bytes return the canonical encoding of every field of the transaction except the signature, this is what get signed.
+// Inner transaction are covered with their own signer and signature.
+func (tx *Transaction) Bytes() []byte {
+	w := serialize.NewWriter()
+	w.WriteString(tx.ChainID)
+	writePublicKey(w, tx.From)
+	w.WriteUint64(tx.Nonce)
+	w.WriteUint64(tx.MaxFee)
+	w.WriteUint64(tx.TipCap)
+	w.WriteInt64(tx.ValidFrom)
+	w.WriteInt64(tx.ValidUntil)
+	w.WriteBytes(tx.Data)
+
+	writeTxInner(w, tx.TxInner)
+	writeMultisigAccount(w, tx.Multisig)
+	return w.Bytes()
 }
 
 func (tx *Transaction) Sign(privKey *crypto.PrivateKey) error {
-	sig := privKey.Sign(tx.Bytes())
+	// signer is part of the signed bytes
 	tx.From = privKey.Public()
-	tx.Signature = sig
+	tx.Signature = privKey.Sign(tx.Bytes())
 	return nil
 }
 
@@ -117,18 +236,90 @@ func (tx *Transaction) Hash(hasher Hasher[*Transaction]) types.Hash {
 }
 
 func (tx *Transaction) Verify() error {
-	if tx.Signature == nil || tx.From == nil {
-		return ErrSigNotExisted
-	}
-	if !tx.Signature.Verify(tx.From, tx.Bytes()) {
-		return ErrSigInvalid
+	if tx.Multisig != nil {
+		if tx.From != nil || tx.Signature != nil {
+			return fmt.Errorf("%w: multisig transaction is signed by a single key", ErrSigInvalid)
+		}
+		if err := tx.verifyMultisig(); err != nil {
+			return err
+		}
+	} else {
+		if tx.Signature == nil || tx.From == nil {
+			return ErrSigNotExisted
+		}
+		if !tx.Signature.Verify(tx.From, tx.Bytes()) {
+			return ErrSigInvalid
+		}
 	}
 	if tx.TxInner != nil {
 		switch ttx := tx.TxInner.(type) {
 		case MintTx:
-			return ttx.Verify()
+			if err := ttx.Verify(); err != nil {
+				return err
+			}
+			// the signed mint is bound to the transaction of its owner, so it could not be replayed by others
+			if ttx.Owner.Address() != tx.Sender() {
+				return fmt.Errorf("%w: mint of (%s) is not sent by (%s)", ErrSigInvalid, ttx.Owner.Address(), tx.Sender())
+			}
+			return nil
 		case TransferTx:
+			if ttx.From != tx.Sender() {
+				return fmt.Errorf("%w: transfer from (%s) is not sent by (%s)", ErrSigInvalid, ttx.From, tx.Sender())
+			}
+			// transfer from a multisig account is covered by the signatures of its co-signers
+			if tx.Multisig != nil {
+				return nil
+			}
 			return ttx.Verify()
+		case MultisigCreateTx:
+			return ttx.Account.Validate()
+		case VestingTransferTx:
+			return ttx.Validate()
+		case BatchTransferTx:
+			return ttx.Validate()
+		case NFTTransferTx:
+			return ttx.Validate()
+		case NFTBurnTx:
+			return ttx.Validate()
+		case NFTMetadataUpdateTx:
+			return ttx.Validate()
+		case CollectionFreezeTx:
+			return ttx.Validate()
+		case NFTSaleTx:
+			if ttx.Buyer != tx.Sender() {
+				return fmt.Errorf("%w: sale to (%s) is not sent by (%s)", ErrSigInvalid, ttx.Buyer, tx.Sender())
+			}
+			return ttx.Verify()
+		case NFTListTx:
+			return ttx.Validate()
+		case NFTCancelListingTx:
+			return ttx.Validate()
+		case NFTBuyTx:
+			return ttx.Validate()
+		case NFTOfferTx:
+			return ttx.Validate()
+		case NFTAcceptOfferTx:
+			return ttx.Validate()
+		case AuctionCreateTx:
+			return ttx.Validate()
+		case AuctionBidTx:
+			return ttx.Validate()
+		case AuctionSettleTx:
+			return ttx.Validate()
+		case HTLCLockTx:
+			return ttx.Validate()
+		case HTLCClaimTx:
+			return ttx.Validate()
+		case HTLCRefundTx:
+			return ttx.Validate()
+		case TokenCreateTx:
+			return ttx.Validate()
+		case TokenTransferTx:
+			return ttx.Validate()
+		case TokenMintTx:
+			return ttx.Validate()
+		case TokenBurnTx:
+			return ttx.Validate()
 		default:
 			return nil
 		}
@@ -136,6 +327,14 @@ func (tx *Transaction) Verify() error {
 	return nil
 }
 
+// VerifyChainID verify the transaction like Verify and check that it was signed for the given chain
+func (tx *Transaction) VerifyChainID(chainID string) error {
+	if tx.ChainID != chainID {
+		return fmt.Errorf("%w: expected (%s), given (%s)", ErrChainIDInvalid, chainID, tx.ChainID)
+	}
+	return tx.Verify()
+}
+
 func (tx *Transaction) Encode(enc Encoder[*Transaction]) error {
 	return enc.Encode(tx)
 }
@@ -159,13 +358,19 @@ func (tx *Transaction) ReHash(hasher Hasher[*Transaction]) types.Hash {
 
 func (tx *Transaction) Copy() *Transaction {
 	newTx := &Transaction{
-		From:      tx.From,
-		Signature: tx.Signature,
-		Nonce:     tx.Nonce,
-		TxInner:   tx.TxInner,
-		timeStamp: tx.timeStamp,
-		Data:      tx.Data[:],
-		Fee:       tx.Fee,
+		From:       tx.From,
+		Signature:  tx.Signature,
+		Nonce:      tx.Nonce,
+		TxInner:    tx.TxInner,
+		timeStamp:  tx.timeStamp,
+		Data:       tx.Data[:],
+		MaxFee:     tx.MaxFee,
+		TipCap:     tx.TipCap,
+		ValidFrom:  tx.ValidFrom,
+		ValidUntil: tx.ValidUntil,
+		ChainID:    tx.ChainID,
+		Multisig:   tx.Multisig,
+		Signatures: append([]MultisigSignature{}, tx.Signatures...),
 	}
 	return newTx
 }
@@ -2,7 +2,9 @@ package core
 
 import (
 	"blocker/crypto"
+	"blocker/types"
 	"bytes"
+	"encoding/hex"
 	"testing"
 
 	"github.com/stretchr/testify/assert"
@@ -88,3 +90,93 @@ func RandomTxWithSignature(t *testing.T) *Transaction {
 	assert.Nil(t, tx.Sign(privKkey))
 	return tx
 }
+
+func TestTransactionChainID(t *testing.T) {
+	privKey := crypto.GeneratePrivateKey()
+	tx := &Transaction{
+		Data:    []byte("sample"),
+		ChainID: "blocker-testnet",
+	}
+	assert.Nil(t, tx.Sign(privKey))
+	assert.Nil(t, tx.VerifyChainID("blocker-testnet"))
+	assert.ErrorIs(t, tx.VerifyChainID("blocker-mainnet"), ErrChainIDInvalid)
+
+	// chain id is part of the signed bytes, it cannot be replaced without the signer
+	tx.ChainID = "blocker-mainnet"
+	assert.Equal(t, ErrSigInvalid, tx.VerifyChainID("blocker-mainnet"))
+}
+
+// vectors lock the canonical signing encoding, changing them breaks every signed transaction
+const (
+	vectorSeed           = "70e8b2282a89475436a50e13e94839b565f25d138eac87cbfee1bf3cca85d22d"
+	vectorTransferHex    = "0393f29f09c56a1d108a3ba1a9adbba889eddaa10102030405060708090a0b0c0d0e0f1011121314e803000000000000"
+	vectorTransferSig    = "dab73f4e13c7abbbddf58cf8397f7a00de5f85c0c3c15596f2b57dc437e92ece3bb04963233f8e2d9f9751dfe139b8b514f482bac8d4983c5c82969cce77f403"
+	vectorMintHex        = "0209000000696d6167652d75726c0a000000697066733a2f2f6e6674aa00000000000000000000000000000000000000000000000000000000000000fa000000bb00000000000000000000000000000000000000040000006d657461"
+	vectorTransactionHex = "0c000000626c6f636b65722d74657374200000000fd93b3ca5010d8287d01b4d2543086b30d70ba09f6624da85ff9a022df6973607000000000000003200000000000000050000000000000000002a36fe9c97170000b49376e2fa1802000000010201300000000393f29f09c56a1d108a3ba1a9adbba889eddaa10102030405060708090a0b0c0d0e0f1011121314e803000000000000200000000fd93b3ca5010d8287d01b4d2543086b30d70ba09f6624da85ff9a022df6973640000000dab73f4e13c7abbbddf58cf8397f7a00de5f85c0c3c15596f2b57dc437e92ece3bb04963233f8e2d9f9751dfe139b8b514f482bac8d4983c5c82969cce77f40300000000"
+	vectorTransactionSig = "6423e1442a33073be1e69d7166da57ef8d16ebd02d667471ed50e369c3f89dac2644926c98d87e9a19eb780596f31120b9c9e47047558d9545d638df0fc5ca0e"
+)
+
+func vectorTransaction(t *testing.T) (*crypto.PrivateKey, *Transaction) {
+	priv := crypto.GeneratePrivateKeyFromString(vectorSeed)
+	transfer := TransferTx{
+		From:  priv.Public().Address(),
+		To:    types.Address{1, 2, 3, 4, 5, 6, 7, 8, 9, 10, 11, 12, 13, 14, 15, 16, 17, 18, 19, 20},
+		Value: 1000,
+	}
+	assert.Equal(t, vectorTransferHex, hex.EncodeToString(transfer.Bytes()))
+	assert.Nil(t, transfer.Sign(priv))
+	assert.Equal(t, vectorTransferSig, hex.EncodeToString(transfer.Signature.Bytes()))
+
+	tx := &Transaction{
+		TxInner:    transfer,
+		Data:       []byte{0x01, 0x02},
+		MaxFee:     50,
+		TipCap:     5,
+		Nonce:      7,
+		ValidFrom:  1700000000000000000,
+		ValidUntil: 1800000000000000000,
+		ChainID:    "blocker-test",
+	}
+	assert.Nil(t, tx.Sign(priv))
+	return priv, tx
+}
+
+func TestTransactionSigningVectors(t *testing.T) {
+	_, tx := vectorTransaction(t)
+	assert.Equal(t, vectorTransactionHex, hex.EncodeToString(tx.Bytes()))
+	assert.Equal(t, vectorTransactionSig, hex.EncodeToString(tx.Signature.Bytes()))
+	assert.Nil(t, tx.Verify())
+
+	mint := MintTx{
+		NFT: NFTAsset{
+			Type:       NFTAssetTypeImageURL,
+			Data:       []byte("ipfs://nft"),
+			Collection: types.Hash{0xaa},
+			Royalty:    Royalty{Bps: 250, Recipient: types.Address{0xbb}},
+		},
+		Metadata: []byte("meta"),
+	}
+	assert.Equal(t, vectorMintHex, hex.EncodeToString(mint.Bytes()))
+}
+
+func TestTransactionSignatureCoverEveryField(t *testing.T) {
+	tamper := []func(tx *Transaction){
+		func(tx *Transaction) { tx.MaxFee++ },
+		func(tx *Transaction) { tx.TipCap++ },
+		func(tx *Transaction) { tx.Nonce++ },
+		func(tx *Transaction) { tx.ValidFrom++ },
+		func(tx *Transaction) { tx.ValidUntil++ },
+		func(tx *Transaction) { tx.Data = []byte{0x01} },
+		func(tx *Transaction) { tx.ChainID = "blocker-other" },
+		func(tx *Transaction) {
+			transfer := tx.TxInner.(TransferTx)
+			transfer.Value++
+			tx.TxInner = transfer
+		},
+	}
+	for _, fn := range tamper {
+		_, tx := vectorTransaction(t)
+		fn(tx)
+		assert.Equal(t, ErrSigInvalid, tx.Verify())
+	}
+}
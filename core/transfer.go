@@ -2,11 +2,9 @@ package core
 
 import (
 	"blocker/crypto"
+	"blocker/serialize"
 	"blocker/types"
-	"bytes"
-	"encoding/binary"
 	"encoding/gob"
-	"math/rand"
 )
 
 type TransferTx struct {
@@ -17,25 +15,20 @@ type TransferTx struct {
 	Value     uint64
 }
 
-func NewNativeTransferTransaction(transferTx TransferTx) *Transaction {
+func NewNativeTransferTransaction(transferTx TransferTx, nonce uint64) *Transaction {
 	return &Transaction{
 		TxInner: transferTx,
-		Nonce:   rand.Uint64(),
+		Nonce:   nonce,
 	}
 }
 
+// Bytes return the canonical encoding of the transfer without signature
 func (tx *TransferTx) Bytes() []byte {
-	buf := new(bytes.Buffer)
-	if err := binary.Write(buf, binary.LittleEndian, tx.Value); err != nil {
-		panic(err)
-	}
-	if err := binary.Write(buf, binary.NativeEndian, tx.From.Bytes()); err != nil {
-		panic(err)
-	}
-	if err := binary.Write(buf, binary.NativeEndian, tx.To.Bytes()); err != nil {
-		panic(err)
-	}
-	return buf.Bytes()
+	w := serialize.NewWriter()
+	w.WriteFixed(tx.From.Bytes())
+	w.WriteFixed(tx.To.Bytes())
+	w.WriteUint64(tx.Value)
+	return w.Bytes()
 }
 
 func (tx *TransferTx) Sign(priv *crypto.PrivateKey) error {
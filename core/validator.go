@@ -1,6 +1,12 @@
 package core
 
-import "fmt"
+import (
+	"fmt"
+	"time"
+)
+
+// MaxBlockTimeDrift is how far in the future of the local clock a block timestamp could be
+const MaxBlockTimeDrift = 15 * time.Second
 
 type Validator interface {
 	Validate(*Block) error
@@ -17,6 +23,10 @@ func NewBlockValidator(bc *BlockChain) *BlockValidator {
 }
 
 func (v *BlockValidator) Validate(block *Block) error {
+	if err := v.bc.CheckFinalized(block.Header); err != nil {
+		return err
+	}
+
 	if v.bc.HasBlock(block.Height) {
 		return fmt.Errorf("Block (%s) with height (%d) existed", block.Hash(BlockHasher{}), block.Height)
 	}
@@ -34,8 +44,40 @@ func (v *BlockValidator) Validate(block *Block) error {
 		return fmt.Errorf("Block (%s) has invalid previousDataHash(%s) => previousDataHash (%s)", block.Hash(BlockHasher{}), block.PrevBlockHash.Short(), prevHash.Short())
 	}
 
+	if block.Timestamp <= prevHeader.Timestamp {
+		return fmt.Errorf("Block (%s) has timestamp (%d) not after previous block (%d)", block.Hash(BlockHasher{}), block.Timestamp, prevHeader.Timestamp)
+	}
+	if maxTimestamp := time.Now().Add(MaxBlockTimeDrift).UnixNano(); block.Timestamp > maxTimestamp {
+		return fmt.Errorf("Block (%s) has timestamp (%d) too far in the future", block.Hash(BlockHasher{}), block.Timestamp)
+	}
+
+	prevBlock, err := v.bc.GetBlock(block.Height - 1)
+	if err != nil {
+		return err
+	}
+	if baseFee := NextBaseFee(prevHeader.BaseFee, len(prevBlock.Transactions), v.bc.params); block.BaseFee != baseFee {
+		return fmt.Errorf("Block (%s) has base fee (%d) => expected (%d)", block.Hash(BlockHasher{}), block.BaseFee, baseFee)
+	}
+
 	if err := block.Verify(); err != nil {
 		return err
 	}
+
+	for _, tx := range block.Transactions {
+		if err := tx.VerifyChainID(v.bc.chainID); err != nil {
+			return err
+		}
+		if err := tx.ValidAt(block.Timestamp); err != nil {
+			return fmt.Errorf("Block (%s) has transaction (%s) outside its validity window: %w", block.Hash(BlockHasher{}), tx.Hash(TxHasher{}).Short(), err)
+		}
+	}
+
+	if !v.bc.IsValidator(block.Validator) {
+		return fmt.Errorf("Block (%s) signed by (%s) which is not a validator", block.Hash(BlockHasher{}), block.Validator.Address())
+	}
+
+	if maxTxs := v.bc.params.MaxBlockTxs; maxTxs > 0 && len(block.Transactions) > int(maxTxs) {
+		return fmt.Errorf("Block (%s) has (%d) transactions => max (%d)", block.Hash(BlockHasher{}), len(block.Transactions), maxTxs)
+	}
 	return nil
 }
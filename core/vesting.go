@@ -0,0 +1,129 @@
+package core
+
+import (
+	"blocker/serialize"
+	"blocker/types"
+	"encoding/gob"
+	"errors"
+	"fmt"
+)
+
+var (
+	ErrVestingInvalid      = errors.New("vesting transfer is invalid")
+	ErrVestingNotExisted   = errors.New("vesting not existed")
+	ErrVestingNotClaimable = errors.New("nothing vested to claim")
+	ErrVestingUnlocked     = errors.New("vesting transfer locks nothing, every coin is vested when it is included")
+)
+
+// VestingTransferTx transfers Value to To, the coins are locked until UnlockHeight and UnlockTime (unixnano)
+// are both reached, zero bound is open. With VestingBlocks the coins are released linearly over that many
+// blocks from UnlockHeight, or from the block the transfer is included in when it is later, instead of all at once.
+type VestingTransferTx struct {
+	To            types.Address
+	Value         uint64
+	UnlockHeight  uint32
+	UnlockTime    int64
+	VestingBlocks uint32
+}
+
+func NewVestingTransferTransaction(vestingTx VestingTransferTx, nonce uint64) *Transaction {
+	return &Transaction{
+		TxInner: vestingTx,
+		Nonce:   nonce,
+	}
+}
+
+// Bytes return the canonical encoding of the vesting transfer
+func (tx *VestingTransferTx) Bytes() []byte {
+	w := serialize.NewWriter()
+	w.WriteFixed(tx.To.Bytes())
+	w.WriteUint64(tx.Value)
+	w.WriteUint32(tx.UnlockHeight)
+	w.WriteInt64(tx.UnlockTime)
+	w.WriteUint32(tx.VestingBlocks)
+	return w.Bytes()
+}
+
+func (tx *VestingTransferTx) Validate() error {
+	if tx.To.IsZero() || tx.Value == 0 {
+		return fmt.Errorf("%w: recipient and value must be set", ErrVestingInvalid)
+	}
+	if tx.UnlockHeight == 0 && tx.UnlockTime == 0 && tx.VestingBlocks == 0 {
+		return fmt.Errorf("%w: coins are never locked", ErrVestingInvalid)
+	}
+	return nil
+}
+
+// VestingClaimTx unlocks what has vested so far from the given vestings of the sender, every vesting of
+// the sender when none is given.
+type VestingClaimTx struct {
+	Vestings []types.Hash
+}
+
+func NewVestingClaimTransaction(nonce uint64, vestings ...types.Hash) *Transaction {
+	return &Transaction{
+		TxInner: VestingClaimTx{Vestings: vestings},
+		Nonce:   nonce,
+	}
+}
+
+func (tx *VestingClaimTx) Bytes() []byte {
+	w := serialize.NewWriter()
+	w.WriteUint32(uint32(len(tx.Vestings)))
+	for _, hash := range tx.Vestings {
+		w.WriteFixed(hash.Bytes())
+	}
+	return w.Bytes()
+}
+
+// Vesting is the schedule of coins locked by a vesting transfer, it is identified by the hash of that transaction
+type Vesting struct {
+	Hash          types.Hash
+	Beneficiary   types.Address
+	Total         uint64
+	Claimed       uint64
+	UnlockHeight  uint32
+	UnlockTime    int64
+	VestingBlocks uint32
+	StartHeight   uint32 // height of the block the vesting transfer is included in
+}
+
+func NewVesting(hash types.Hash, tx VestingTransferTx, height uint32) *Vesting {
+	return &Vesting{
+		Hash:          hash,
+		Beneficiary:   tx.To,
+		Total:         tx.Value,
+		UnlockHeight:  tx.UnlockHeight,
+		UnlockTime:    tx.UnlockTime,
+		VestingBlocks: tx.VestingBlocks,
+		StartHeight:   height,
+	}
+}
+
+// Vested returns the coins vested in the block at height with timestamp, claimed coins included
+func (v *Vesting) Vested(height uint32, timestamp int64) uint64 {
+	start := max(v.UnlockHeight, v.StartHeight)
+	if height < start || (v.UnlockTime != 0 && timestamp < v.UnlockTime) {
+		return 0
+	}
+	elapsed := height - start
+	if v.VestingBlocks == 0 || elapsed >= v.VestingBlocks {
+		return v.Total
+	}
+	return mulDiv(v.Total, uint64(elapsed), uint64(v.VestingBlocks))
+}
+
+// mulDiv returns a*b/d rounded down without overflowing, b must not be greater than d and d must fit in 32 bits
+func mulDiv(a, b, d uint64) uint64 {
+	return a/d*b + a%d*b/d
+}
+
+// Claimable returns the vested coins not claimed yet
+func (v *Vesting) Claimable(height uint32, timestamp int64) uint64 {
+	return v.Vested(height, timestamp) - v.Claimed
+}
+
+func init() {
+	gob.Register(VestingTransferTx{})
+	gob.Register(VestingClaimTx{})
+}
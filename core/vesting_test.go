@@ -0,0 +1,107 @@
+package core
+
+import (
+	"blocker/crypto"
+	"blocker/types"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestVestingVested(t *testing.T) {
+	cliff := &Vesting{Total: 1000, UnlockHeight: 10}
+	assert.Equal(t, uint64(0), cliff.Vested(9, 0))
+	assert.Equal(t, uint64(1000), cliff.Vested(10, 0))
+
+	timed := &Vesting{Total: 1000, UnlockTime: 100}
+	assert.Equal(t, uint64(0), timed.Vested(50, 99))
+	assert.Equal(t, uint64(1000), timed.Vested(50, 100))
+
+	linear := &Vesting{Total: 1000, UnlockHeight: 10, VestingBlocks: 3, Claimed: 300}
+	assert.Equal(t, uint64(0), linear.Vested(9, 0))
+	assert.Equal(t, uint64(0), linear.Vested(10, 0))
+	assert.Equal(t, uint64(333), linear.Vested(11, 0))
+	assert.Equal(t, uint64(666), linear.Vested(12, 0))
+	assert.Equal(t, uint64(1000), linear.Vested(13, 0))
+	assert.Equal(t, uint64(366), linear.Claimable(12, 0))
+
+	// linear vesting never starts before the block it is included in
+	late := &Vesting{Total: 900, UnlockHeight: 5, VestingBlocks: 3, StartHeight: 20}
+	assert.Equal(t, uint64(0), late.Vested(20, 0))
+	assert.Equal(t, uint64(300), late.Vested(21, 0))
+	assert.Equal(t, uint64(900), late.Vested(23, 0))
+
+	huge := &Vesting{Total: 1 << 63, VestingBlocks: 1 << 31}
+	assert.Equal(t, uint64(1<<62), huge.Vested(1<<30, 0))
+}
+
+func TestVestingTransferAndClaim(t *testing.T) {
+	privBob := crypto.GeneratePrivateKey()
+	privAlice := crypto.GeneratePrivateKey()
+	cfg := newTestGenesisConfig()
+	cfg.Alloc[privBob.Public().Address().String()] = 1000
+	cfg.Alloc[privAlice.Public().Address().String()] = 100
+	bc := newTestChain(t, cfg)
+
+	// 300 released over 3 blocks from height 2, 200 locked for a long time
+	linear := bc.newTx(privBob, VestingTransferTx{To: privAlice.Public().Address(), Value: 300, UnlockHeight: 2, VestingBlocks: 3})
+	timed := bc.newTx(privBob, VestingTransferTx{To: privAlice.Public().Address(), Value: 200, UnlockTime: time.Now().Add(time.Hour).UnixNano()})
+	bc.addBlock(linear, timed)
+	assert.Equal(t, uint64(500), bc.account(privAlice.Public().Address()).Locked)
+	vestings, err := bc.GetVestingsOfAccount(privAlice.Public().Address())
+	assert.Nil(t, err)
+	assert.Equal(t, 2, len(vestings))
+	assert.Equal(t, linear.Hash(TxHasher{}), vestings[0].Hash)
+
+	// nothing vested at height 2
+	claim := bc.newTx(privAlice, VestingClaimTx{})
+	assert.Equal(t, 1, len(bc.SoftcheckTransactions([]*Transaction{claim})))
+	bc.addBlock(claim)
+	receipt, err := bc.GetReceipt(claim.Hash(TxHasher{}))
+	assert.Nil(t, err)
+	assert.Equal(t, ErrVestingNotClaimable.Error(), receipt.Err)
+
+	// height 3 vests a third of the linear vesting
+	before := bc.account(privAlice.Public().Address())
+	claim = bc.newTx(privAlice, VestingClaimTx{})
+	bc.addBlock(claim)
+	receipt, err = bc.GetReceipt(claim.Hash(TxHasher{}))
+	assert.Nil(t, err)
+	assert.True(t, receipt.Succeeded())
+	assert.Equal(t, uint64(400), bc.account(privAlice.Public().Address()).Locked)
+	assert.Equal(t, before.Balance+100-receipt.FeeCharged, bc.account(privAlice.Public().Address()).Balance)
+
+	// claim of a vesting of another account fails
+	claim = bc.newTx(privBob, VestingClaimTx{Vestings: []types.Hash{linear.Hash(TxHasher{})}})
+	assert.Equal(t, 1, len(bc.SoftcheckTransactions([]*Transaction{claim})))
+	bc.addBlock(claim)
+	receipt, err = bc.GetReceipt(claim.Hash(TxHasher{}))
+	assert.Nil(t, err)
+	assert.False(t, receipt.Succeeded())
+
+	// everything of the linear vesting is vested at height 5, the timed one stays locked
+	bc.addBlock()
+	claim = bc.newTx(privAlice, VestingClaimTx{Vestings: []types.Hash{linear.Hash(TxHasher{}), timed.Hash(TxHasher{})}})
+	bc.addBlock(claim)
+	assert.Equal(t, uint64(200), bc.account(privAlice.Public().Address()).Locked)
+	vestings, err = bc.GetVestingsOfAccount(privAlice.Public().Address())
+	assert.Nil(t, err)
+	assert.Equal(t, uint64(300), vestings[0].Claimed)
+	assert.Equal(t, uint64(0), vestings[1].Claimed)
+
+	// a cliff already reached locks nothing, a linear vesting starts from the block it is included in
+	cliff := bc.newTx(privBob, VestingTransferTx{To: privAlice.Public().Address(), Value: 10, UnlockHeight: 1})
+	assert.Equal(t, 1, len(bc.SoftcheckTransactions([]*Transaction{cliff})))
+	linear = bc.newTx(privBob, VestingTransferTx{To: privAlice.Public().Address(), Value: 30, VestingBlocks: 3})
+	bc.addBlock(cliff, linear)
+	receipt, err = bc.GetReceipt(cliff.Hash(TxHasher{}))
+	assert.Nil(t, err)
+	assert.Equal(t, ErrVestingUnlocked.Error(), receipt.Err)
+	assert.Equal(t, uint64(230), bc.account(privAlice.Public().Address()).Locked)
+	vestings, err = bc.GetVestingsOfAccount(privAlice.Public().Address())
+	assert.Nil(t, err)
+	assert.Equal(t, 3, len(vestings))
+	assert.Equal(t, bc.Height(), vestings[2].StartHeight)
+	assert.Equal(t, uint64(10), vestings[2].Vested(bc.Height()+1, 0))
+}
@@ -18,29 +18,41 @@ const (
 )
 
 type VM struct {
-	contractState *State // TODO: should change state to interface
+	contractState ContractState
 	stack         *types.Stack
 	data          []byte
-	ip            int // Instruction pointer
-	sp            int // Stack pointer
+	ip            int    // Instruction pointer
+	sp            int    // Stack pointer
+	gasUsed       uint64 // number of executed instructions
+	logs          []Log
 }
 
-func NewVM(data []byte, state *State) *VM {
+func NewVM(data []byte, state ContractState) *VM {
 	return &VM{
 		data:          data,
 		ip:            0,
 		stack:         types.NewStack(),
 		sp:            -1,
 		contractState: state,
+		logs:          []Log{},
 	}
 }
 
+func (vm *VM) GasUsed() uint64 {
+	return vm.gasUsed
+}
+
+func (vm *VM) Logs() []Log {
+	return vm.logs
+}
+
 func (vm *VM) Run() error {
 	if len(vm.data) == 0 {
 		return nil
 	}
 	for {
 		instr := Instruction(vm.data[vm.ip])
+		vm.gasUsed++
 		if err := vm.ExecInstruction(instr); err != nil {
 			return err
 		}
@@ -70,6 +82,7 @@ func (vm *VM) ExecInstruction(instr Instruction) error {
 			if err != nil {
 				panic(fmt.Sprintf("vm: error while put to state: %v", err))
 			}
+			vm.logs = append(vm.logs, Log{Key: string(key), Value: buf})
 
 		default:
 			panic("vm: unknow type")
@@ -43,6 +43,8 @@ func TestPackWithState(t *testing.T) {
 	assert.Nil(t, err)
 	val := serialize.DeSerializeUint64(buf)
 	assert.Equal(t, uint64(3), val)
+	assert.Equal(t, uint64(len(data)), vm.GasUsed())
+	assert.Equal(t, []Log{{Key: "taD", Value: buf}}, vm.Logs())
 
 	fmt.Println(vm.stack)
 }
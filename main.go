@@ -7,12 +7,25 @@ import (
 	"blocker/types"
 	"blocker/wallet"
 	"bytes"
+	"flag"
 	"math/rand"
 	"strconv"
 	"time"
 )
 
 func main() {
+	genesisPath := flag.String("genesis", "", "path to the genesis config file, default genesis is used if empty")
+	flag.Parse()
+
+	genesis := core.DefaultGenesisConfig()
+	if *genesisPath != "" {
+		cfg, err := core.LoadGenesisConfig(*genesisPath)
+		if err != nil {
+			panic(err)
+		}
+		genesis = cfg
+	}
+
 	trLocal := network.NewTCPTransport("LOCAL", ":3000")
 
 	go func() {
@@ -39,7 +52,7 @@ func main() {
 	}()
 
 	privKey := crypto.GeneratePrivateKey()
-	server := makeServer("localhost:8080", trLocal, []network.Peer{}, privKey)
+	server := makeServer("localhost:8080", trLocal, []network.Peer{}, privKey, genesis)
 	server.Start()
 }
 
@@ -57,13 +70,14 @@ func makeTCPServer(node network.Transport, tcpSeed []string, privKey *crypto.Pri
 	return server
 }
 
-func makeServer(apiAddr string, node network.Transport, seed []network.Peer, privKey *crypto.PrivateKey) *network.Server {
+func makeServer(apiAddr string, node network.Transport, seed []network.Peer, privKey *crypto.PrivateKey, genesis *core.GenesisConfig) *network.Server {
 	opt := network.ServerOptions{
 		Transport: node,
 		ID:        string(node.Addr()),
 		Addr:      apiAddr,
 		PrivKey:   privKey,
 		LocalSeed: seed,
+		Genesis:   genesis,
 	}
 	server, err := network.NewServer(opt)
 	if err != nil {
@@ -74,7 +88,7 @@ func makeServer(apiAddr string, node network.Transport, seed []network.Peer, pri
 
 func sendLocalTransaction(to network.Transport, from network.Transport) error {
 	data := []byte{0x01, 0x0a, 0x02, 0x0a, 0x0b}
-	tx := core.NewNativeTransaction(data)
+	tx := core.NewNativeTransaction(data, 1)
 	privKey := crypto.GeneratePrivateKey()
 	tx.Sign(privKey)
 	buf := &bytes.Buffer{}
@@ -101,6 +115,7 @@ func sendMintTransaction(from *wallet.Wallet) error {
 		core.NFTAssetTypeImageBase64,
 		[]byte(randInt),
 		types.Hash{},
+		core.Royalty{},
 		map[string]any{"name": "hello"},
 		0)
 }
@@ -2,6 +2,7 @@ package network
 
 import (
 	"blocker/core"
+	"blocker/types"
 	"bytes"
 	"encoding/gob"
 	"fmt"
@@ -37,7 +38,8 @@ func (msg *ResponseBlocksMessage) Bytes() []byte {
 
 type GetStatusMessage struct {
 	// The ID of the requester
-	ID string
+	ID          string
+	GenesisHash types.Hash
 }
 
 func (msg *GetStatusMessage) Bytes() []byte {
@@ -53,6 +55,7 @@ type StatusMessage struct {
 	ID            string
 	Version       uint32
 	CurrentHeight uint32
+	GenesisHash   types.Hash
 }
 
 func (msg *StatusMessage) Bytes() []byte {
@@ -7,9 +7,11 @@ import (
 	"blocker/pool"
 	"blocker/types"
 	"bytes"
+	"errors"
 	"fmt"
 	"net"
 	"os"
+	"sync"
 	"time"
 
 	"github.com/go-kit/log"
@@ -20,6 +22,8 @@ const (
 	defaultBlockTime  = 5 * time.Second
 )
 
+var ErrGenesisMismatch = errors.New("peer genesis hash mismatch")
+
 type ServerOptions struct {
 	// RootAccount   core.Account
 	Transport     Transport
@@ -35,6 +39,7 @@ type ServerOptions struct {
 	MaxPoolLen    int
 	blockTime     time.Duration
 	Version       uint32
+	Genesis       *core.GenesisConfig // genesis of the chain, default genesis if nil
 }
 
 type Server struct {
@@ -48,10 +53,19 @@ type Server struct {
 	ServerOptions               // Embed ServerOptions
 	blockTime     time.Duration // duration of generating new blokc
 	isValidator   bool
+	rejectedPeers map[NetAddr]bool // peers running a chain with different genesis
+	peerLock      sync.RWMutex
 }
 
 func NewServer(opts ServerOptions) (*Server, error) {
+	if opts.Genesis == nil {
+		opts.Genesis = core.DefaultGenesisConfig()
+	}
+
 	bt := opts.blockTime
+	if bt == 0 {
+		bt = time.Duration(opts.Genesis.Params.BlockTime) * time.Second
+	}
 	if bt == 0 {
 		bt = defaultBlockTime
 	}
@@ -64,7 +78,7 @@ func NewServer(opts ServerOptions) (*Server, error) {
 	if opts.MaxPoolLen == 0 {
 		opts.MaxPoolLen = defaultMaxPoolLen
 	}
-	chain, err := core.NewBlockChain(Genesis(), core.NewInMemoryStorage(), opts.Logger)
+	chain, err := core.NewBlockChainFromGenesis(opts.Genesis, core.NewInMemoryStorage(), opts.Logger)
 	if err != nil {
 		return nil, err
 	}
@@ -77,6 +91,7 @@ func NewServer(opts ServerOptions) (*Server, error) {
 		isValidator:   opts.PrivKey != nil,
 		quitCh:        make(chan struct{}, 1),
 		txChan:        make(chan *core.Transaction, 1024),
+		rejectedPeers: make(map[NetAddr]bool),
 	}
 	if sv.RPCDecodeFunc == nil {
 		sv.RPCDecodeFunc = DefaultDecodeMessageFunc
@@ -98,7 +113,8 @@ func NewServer(opts ServerOptions) (*Server, error) {
 	if sv.Addr != "" {
 		// Init API Server with this server
 		opts := api.ServerOpts{
-			Addr: sv.Addr,
+			Addr:   sv.Addr,
+			TxPool: sv.memPool,
 		}
 		apiServer := api.NewServer(sv.chain, sv.txChan, opts)
 		go func() {
@@ -163,6 +179,9 @@ func (s *Server) validatorLoop() {
 }
 
 func (s *Server) ProcessMessage(msg *DecodedMessage) error {
+	if s.isRejectedPeer(msg.From) {
+		return ErrGenesisMismatch
+	}
 	switch t := msg.Data.(type) {
 	case *core.Transaction:
 		return s.processTransaction(t)
@@ -173,7 +192,7 @@ func (s *Server) ProcessMessage(msg *DecodedMessage) error {
 	case *ResponseBlocksMessage:
 		return s.processResponseBlocksMessage(msg.From, t)
 	case *GetStatusMessage:
-		return s.processGetStatusMessage(msg.From)
+		return s.processGetStatusMessage(msg.From, t)
 	case *StatusMessage:
 		return s.processStatusMessage(msg.From, t)
 	}
@@ -189,7 +208,7 @@ func (s *Server) broadcast(msg []byte) error {
 }
 
 func (s *Server) processTransaction(tx *core.Transaction) error {
-	if err := tx.Verify(); err != nil {
+	if err := tx.VerifyChainID(s.chain.ChainID()); err != nil {
 		return err
 	}
 	hash := tx.Hash(core.TxHasher{})
@@ -276,17 +295,26 @@ func (s *Server) processResponseBlocksMessage(from NetAddr, msg *ResponseBlocksM
 	return nil
 }
 
-func (s *Server) processGetStatusMessage(from NetAddr) error {
+func (s *Server) processGetStatusMessage(from NetAddr, data *GetStatusMessage) error {
 	status := StatusMessage{
 		ID:            s.ID,
 		Version:       s.Version,
 		CurrentHeight: s.chain.Height(),
+		GenesisHash:   s.chain.GenesisHash(),
 	}
 	msg := NewMesage(MessageTypeResponseStatus, status.Bytes())
-	return s.send(from, msg.Bytes())
+	// always reply, so the requester could reject us as well
+	if err := s.send(from, msg.Bytes()); err != nil {
+		return err
+	}
+	return s.checkPeerGenesis(from, data.GenesisHash)
 }
 
 func (s *Server) processStatusMessage(from NetAddr, data *StatusMessage) error {
+	if err := s.checkPeerGenesis(from, data.GenesisHash); err != nil {
+		return err
+	}
+
 	if s.Version != data.Version {
 		s.Version = data.Version
 	}
@@ -347,7 +375,37 @@ func (s *Server) createNewBlock() error {
 		fmt.Println("==========END-ACCOUNT-STATE==========")
 	}
 
-	block, err := core.NewBlockFromPrevHeader(currentHeader, txx)
+	// block timestamp must be after its parent, and every transaction must be valid at it
+	timestamp := max(time.Now().UnixNano(), currentHeader.Timestamp+1)
+	// transactions of a sender must follow its nonce without gap, the others stay pending for a later block
+	nonces := map[types.Address]uint64{}
+	validTxx := make([]*core.Transaction, 0, len(txx))
+	for _, tx := range txx {
+		if err := tx.ValidAt(timestamp); err != nil {
+			s.Logger.Log("msg", "skip transaction outside its validity window", "hash", tx.Hash(core.TxHasher{}).Short(), "error", err)
+			continue
+		}
+		sender := tx.Sender()
+		next, ok := nonces[sender]
+		if !ok {
+			if next, err = s.chain.NextNonce(sender); err != nil {
+				return err
+			}
+		}
+		if tx.Nonce != next {
+			s.Logger.Log("msg", "defer transaction out of nonce order", "hash", tx.Hash(core.TxHasher{}).Short(), "expected", next, "given", tx.Nonce)
+			continue
+		}
+		nonces[sender] = next + 1
+		validTxx = append(validTxx, tx)
+	}
+	txx = validTxx
+
+	if maxTxs := int(s.chain.Params().MaxBlockTxs); maxTxs > 0 && len(txx) > maxTxs {
+		txx = txx[:maxTxs]
+	}
+
+	block, err := core.NewBlockFromPrevHeader(currentHeader, timestamp, s.chain.NextBaseFee(), txx)
 	if err != nil {
 		return err
 	}
@@ -375,37 +433,30 @@ func (s *Server) bootstrapTCPNetwork() {
 	}
 }
 
+// checkPeerGenesis rejects every further message from the peer if it runs a chain with different genesis
+func (s *Server) checkPeerGenesis(from NetAddr, genesisHash types.Hash) error {
+	if genesisHash == s.chain.GenesisHash() {
+		return nil
+	}
+	s.peerLock.Lock()
+	s.rejectedPeers[from] = true
+	s.peerLock.Unlock()
+	s.Logger.Log("msg", "reject peer with different genesis", "addr", from, "genesis", genesisHash.Short())
+	return ErrGenesisMismatch
+}
+
+func (s *Server) isRejectedPeer(addr NetAddr) bool {
+	s.peerLock.RLock()
+	defer s.peerLock.RUnlock()
+	return s.rejectedPeers[addr]
+}
+
 func (s *Server) sendGetStatusMessage(toPeer Peer) error {
 	requestMessage := GetStatusMessage{
-		ID: s.ID,
+		ID:          s.ID,
+		GenesisHash: s.chain.GenesisHash(),
 	}
 	msg := NewMesage(MessageTypeRequestStatus, requestMessage.Bytes())
 	s.Logger.Log("action", "send get status message", "to", toPeer.Addr())
 	return s.Transport.Send(toPeer.Addr(), msg.Bytes())
 }
-
-func Genesis() *core.Block {
-	// coinbase := core.Account{}
-	transferTx := core.TransferTx{
-		From:  types.Address{},
-		To:    types.Address{},
-		Value: 1000000,
-	}
-
-	tx := &core.Transaction{
-		TxInner: transferTx,
-		Nonce:   0,
-	}
-	block := &core.Block{
-		Header: &core.Header{
-			Version:       1,
-			PrevBlockHash: types.Hash{},
-			Height:        0,
-			Timestamp:     00000000,
-		},
-		Transactions: []*core.Transaction{
-			tx,
-		},
-	}
-	return block
-}
@@ -1,6 +1,7 @@
 package network
 
 import (
+	"blocker/core"
 	"fmt"
 	"testing"
 	"unsafe"
@@ -29,3 +30,24 @@ func TestServerOptions(t *testing.T) {
 	fmt.Printf("Full: %d\n", unsafe.Sizeof(opt))
 	assert.NotNil(t, opt)
 }
+
+func TestRejectPeerWithDifferentGenesis(t *testing.T) {
+	s, err := NewServer(ServerOptions{ID: "A"})
+	assert.Nil(t, err)
+
+	other := core.DefaultGenesisConfig()
+	other.ChainID = "blocker-other"
+	otherGenesis, err := other.Block()
+	assert.Nil(t, err)
+
+	// peer with same genesis is accepted
+	status := &StatusMessage{ID: "B", CurrentHeight: 0, GenesisHash: s.chain.GenesisHash()}
+	assert.Nil(t, s.ProcessMessage(&DecodedMessage{From: "B", Data: status}))
+
+	status = &StatusMessage{ID: "C", CurrentHeight: 10, GenesisHash: otherGenesis.Hash(core.BlockHasher{})}
+	assert.Equal(t, ErrGenesisMismatch, s.ProcessMessage(&DecodedMessage{From: "C", Data: status}))
+
+	// every later message from the rejected peer is dropped
+	assert.Equal(t, ErrGenesisMismatch, s.ProcessMessage(&DecodedMessage{From: "C", Data: &ResponseBlocksMessage{}}))
+	assert.Nil(t, s.ProcessMessage(&DecodedMessage{From: "B", Data: &ResponseBlocksMessage{}}))
+}
@@ -83,6 +83,12 @@ func (p *TxPool) Get(hash types.Hash) (TxPoolStatus, *core.Transaction, error) {
 	return TxPoolUnknown, tx, nil
 }
 
+// PendingNonce returns the last nonce used by pending transactions sent from addr whose account is at nonce,
+// only the run of pending nonces following nonce without gap is counted, since the others cannot be executed yet
+func (p *TxPool) PendingNonce(addr types.Address, nonce uint64) uint64 {
+	return p.pending.ContiguousNonce(addr, nonce)
+}
+
 func (p *TxPool) LockPending() {
 	p.pending.Lock()
 }
@@ -211,6 +217,26 @@ func (t *TxSortedMap) Remove(h types.Hash) *core.Transaction {
 	return tx
 }
 
+// ContiguousNonce returns the highest nonce of the transactions from addr with nonces nonce+1, nonce+2, ... without gap,
+// nonce if there is none
+func (t *TxSortedMap) ContiguousNonce(addr types.Address, nonce uint64) uint64 {
+	t.lock.RLock()
+	defer t.lock.RUnlock()
+
+	nonces := make(map[uint64]struct{})
+	for _, tx := range t.lookup {
+		if tx.Sender() == addr {
+			nonces[tx.Nonce] = struct{}{}
+		}
+	}
+	for {
+		if _, ok := nonces[nonce+1]; !ok {
+			return nonce
+		}
+		nonce++
+	}
+}
+
 func (t *TxSortedMap) Count() int {
 	t.lock.RLock()
 	defer t.lock.RUnlock()
@@ -2,6 +2,7 @@ package pool
 
 import (
 	"blocker/core"
+	"blocker/crypto"
 	"blocker/types"
 	"fmt"
 	"testing"
@@ -13,7 +14,7 @@ import (
 func TestTxPoolAddSequenceTx(t *testing.T) {
 	pool := NewTxPool(10)
 	assert.Equal(t, 0, pool.PendingCount())
-	tx := core.NewNativeTransaction([]byte("foo"))
+	tx := core.NewNativeTransaction([]byte("foo"), 0)
 	tx.Nonce = 0
 	pool.Add(tx)
 	tx = tx.Copy()
@@ -64,7 +65,7 @@ func TestTxPool(t *testing.T) {
 func TestTxPoolAddTx(t *testing.T) {
 	p := NewTxPool(10)
 	assert.Equal(t, 0, p.PendingCount())
-	tx := core.NewNativeTransaction([]byte("foo"))
+	tx := core.NewNativeTransaction([]byte("foo"), 0)
 	p.Add(tx)
 	assert.Equal(t, 1, p.PendingCount())
 }
@@ -72,12 +73,12 @@ func TestTxPoolAddTx(t *testing.T) {
 func TestTxPoolFlush(t *testing.T) {
 	p := NewTxPool(10)
 	assert.Equal(t, 0, p.PendingCount())
-	tx := core.NewNativeTransaction([]byte("foo"))
+	tx := core.NewNativeTransaction([]byte("foo"), 0)
 	p.Add(tx)
 	assert.Equal(t, 1, p.PendingCount())
 	p.ClearPending()
 	assert.Equal(t, 0, p.PendingCount())
-	tx2 := core.NewNativeTransaction([]byte("new"))
+	tx2 := core.NewNativeTransaction([]byte("new"), 0)
 	p.Add(tx2)
 	assert.Equal(t, 1, p.PendingCount())
 }
@@ -85,19 +86,54 @@ func TestTxPoolFlush(t *testing.T) {
 func TestTxPoolAddDuplicateTx(t *testing.T) {
 	p := NewTxPool(10)
 	assert.Equal(t, 0, p.PendingCount())
-	tx := core.NewNativeTransaction([]byte("foo"))
+	tx := core.NewNativeTransaction([]byte("foo"), 0)
 	p.Add(tx)
 	assert.Equal(t, 1, p.PendingCount())
 	p.Add(tx)
 	assert.Equal(t, 1, p.PendingCount())
 }
 
+func TestTxPoolPendingNonce(t *testing.T) {
+	p := NewTxPool(10)
+	privKey := crypto.GeneratePrivateKey()
+	addr := privKey.Public().Address()
+	assert.Equal(t, uint64(0), p.PendingNonce(addr, 0))
+
+	add := func(nonces ...uint64) {
+		for _, nonce := range nonces {
+			tx := core.NewNativeTransaction([]byte(fmt.Sprintf("%d", nonce)), nonce)
+			assert.Nil(t, tx.Sign(privKey))
+			p.Add(tx)
+		}
+	}
+	add(3, 1, 2)
+	other := core.NewNativeTransaction([]byte("other"), 10)
+	assert.Nil(t, other.Sign(crypto.GeneratePrivateKey()))
+	p.Add(other)
+	assert.Equal(t, uint64(3), p.PendingNonce(addr, 0))
+
+	// nonces after a gap are not counted
+	add(5)
+	assert.Equal(t, uint64(3), p.PendingNonce(addr, 0))
+	assert.Equal(t, uint64(3), p.PendingNonce(addr, 1))
+	assert.Equal(t, uint64(5), p.PendingNonce(addr, 4))
+	assert.Equal(t, uint64(6), p.PendingNonce(addr, 6))
+
+	// an account at nonce 1 with a single pending transaction at nonce 5
+	gapped := NewTxPool(10)
+	gapKey := crypto.GeneratePrivateKey()
+	tx := core.NewNativeTransaction([]byte("gap"), 5)
+	assert.Nil(t, tx.Sign(gapKey))
+	gapped.Add(tx)
+	assert.Equal(t, uint64(1), gapped.PendingNonce(gapKey.Public().Address(), 1))
+}
+
 func TestTxPoolTransactions(t *testing.T) {
 	p := NewTxPool(10)
 	assert.Equal(t, 0, p.PendingCount())
 	txLen := 1000
 	for i := 0; i < txLen; i++ {
-		tx := core.NewNativeTransaction([]byte(fmt.Sprintf("%v", i)))
+		tx := core.NewNativeTransaction([]byte(fmt.Sprintf("%v", i)), 0)
 		tx.SetTimestamp(int64(i))
 		p.Add(tx)
 	}
@@ -1,7 +1,10 @@
 package serialize
 
 import (
+	"bytes"
 	"encoding/binary"
+	"errors"
+	"io"
 )
 
 func SerializeUint64(val uint64) []byte {
@@ -13,3 +16,129 @@ func SerializeUint64(val uint64) []byte {
 func DeSerializeUint64(buf []byte) uint64 {
 	return binary.LittleEndian.Uint64(buf)
 }
+
+// Writer writes values in the canonical encoding:
+//   - integers are fixed size, little endian
+//   - variable length values (bytes, string) are prefixed with their uint32 length
+//   - fixed size values (hash, address) are written as is
+type Writer struct {
+	buf bytes.Buffer
+}
+
+func NewWriter() *Writer {
+	return &Writer{}
+}
+
+func (w *Writer) WriteUint8(v uint8) {
+	w.buf.WriteByte(v)
+}
+
+func (w *Writer) WriteBool(v bool) {
+	if v {
+		w.WriteUint8(1)
+		return
+	}
+	w.WriteUint8(0)
+}
+
+func (w *Writer) WriteUint32(v uint32) {
+	w.buf.Write(binary.LittleEndian.AppendUint32(nil, v))
+}
+
+func (w *Writer) WriteUint64(v uint64) {
+	w.buf.Write(binary.LittleEndian.AppendUint64(nil, v))
+}
+
+func (w *Writer) WriteInt64(v int64) {
+	w.WriteUint64(uint64(v))
+}
+
+func (w *Writer) WriteBytes(b []byte) {
+	w.WriteUint32(uint32(len(b)))
+	w.buf.Write(b)
+}
+
+func (w *Writer) WriteString(s string) {
+	w.WriteBytes([]byte(s))
+}
+
+// WriteFixed writes b without length prefix, b must have the same length for every value of its type
+func (w *Writer) WriteFixed(b []byte) {
+	w.buf.Write(b)
+}
+
+func (w *Writer) Bytes() []byte {
+	return w.buf.Bytes()
+}
+
+// maxBytesLen limits length prefixed values, so a malformed input cannot allocate unbounded memory
+const maxBytesLen = 32 << 20
+
+var ErrBytesTooLong = errors.New("serialize: length prefixed value too long")
+
+// Reader reads values written by Writer. The first error is kept and every later read
+// returns zero value, so decoders could check Err once at the end.
+type Reader struct {
+	r   io.Reader
+	err error
+}
+
+func NewReader(r io.Reader) *Reader {
+	return &Reader{r: r}
+}
+
+func (r *Reader) Err() error {
+	return r.err
+}
+
+func (r *Reader) read(n int) []byte {
+	if r.err != nil {
+		return make([]byte, n)
+	}
+	b := make([]byte, n)
+	if _, err := io.ReadFull(r.r, b); err != nil {
+		r.err = err
+	}
+	return b
+}
+
+func (r *Reader) ReadUint8() uint8 {
+	return r.read(1)[0]
+}
+
+func (r *Reader) ReadBool() bool {
+	return r.ReadUint8() == 1
+}
+
+func (r *Reader) ReadUint32() uint32 {
+	return binary.LittleEndian.Uint32(r.read(4))
+}
+
+func (r *Reader) ReadUint64() uint64 {
+	return binary.LittleEndian.Uint64(r.read(8))
+}
+
+func (r *Reader) ReadInt64() int64 {
+	return int64(r.ReadUint64())
+}
+
+// ReadBytes reads length prefixed bytes, empty value is returned as nil
+func (r *Reader) ReadBytes() []byte {
+	n := r.ReadUint32()
+	if r.err != nil || n == 0 {
+		return nil
+	}
+	if n > maxBytesLen {
+		r.err = ErrBytesTooLong
+		return nil
+	}
+	return r.read(int(n))
+}
+
+func (r *Reader) ReadString() string {
+	return string(r.ReadBytes())
+}
+
+func (r *Reader) ReadFixed(n int) []byte {
+	return r.read(n)
+}
@@ -19,6 +19,7 @@ var NodeEndpoint = "http://localhost:8080/api/tx"
 type Wallet struct {
 	privKey      *crypto.PrivateKey
 	transactions []*core.Transaction // user's transaction
+	chainID      string              // chain id of the node, every transaction is signed for this chain
 	addr         types.Address
 	nonce        uint64
 } // wallet is a tcp node that just hold user-related information, user could init the wallet and attach key to the wallet
@@ -27,11 +28,16 @@ func NewWallet(privKey *crypto.PrivateKey) *Wallet {
 	w := &Wallet{
 		privKey: privKey,
 		addr:    privKey.Public().Address(),
-		nonce:   1,
 	}
 	if err := w.RegisterNewWallet(); err != nil {
 		panic(err)
 	}
+	if err := w.SyncChainID(); err != nil {
+		panic(err)
+	}
+	if err := w.SyncNonce(); err != nil {
+		panic(err)
+	}
 	fmt.Printf("created new wallet at addr: %s\n", w.addr.String())
 	return w
 }
@@ -51,11 +57,105 @@ func (w *Wallet) RegisterNewWallet() error {
 	return err
 }
 
+type ChainInfo struct {
+	ChainID     string `json:"chain_id"`
+	GenesisHash string `json:"genesis_hash"`
+	Height      uint32 `json:"height"`
+}
+
+// SyncChainID fetch chain id from the node, transactions of the wallet will be signed for that chain
+func (w *Wallet) SyncChainID() error {
+	req, err := http.NewRequest("GET", "http://localhost:8080/api/chain", nil)
+	if err != nil {
+		return err
+	}
+	client := http.Client{}
+	rsp, err := client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer rsp.Body.Close()
+	info := new(ChainInfo)
+	if err := json.NewDecoder(rsp.Body).Decode(info); err != nil {
+		return err
+	}
+	w.chainID = info.ChainID
+	return nil
+}
+
+type AccountNonce struct {
+	Nonce     uint64 `json:"nonce"`
+	NextNonce uint64 `json:"next_nonce"`
+}
+
+// SyncNonce fetch the next nonce of the wallet from the node, pending transactions in the mempool are counted
+func (w *Wallet) SyncNonce() error {
+	nonce, err := fetchNextNonce(w.addr)
+	if err != nil {
+		return err
+	}
+	w.nonce = nonce
+	return nil
+}
+
+func fetchNextNonce(addr types.Address) (uint64, error) {
+	req, err := http.NewRequest("GET", fmt.Sprintf("http://localhost:8080/api/account/nonce/%s", addr.String()), nil)
+	if err != nil {
+		return 0, err
+	}
+	client := http.Client{}
+	rsp, err := client.Do(req)
+	if err != nil {
+		return 0, err
+	}
+	defer rsp.Body.Close()
+	if rsp.StatusCode != http.StatusOK {
+		return 0, fmt.Errorf("cannot sync nonce, status: %d", rsp.StatusCode)
+	}
+	nonce := new(AccountNonce)
+	if err := json.NewDecoder(rsp.Body).Decode(nonce); err != nil {
+		return 0, err
+	}
+	return nonce.NextNonce, nil
+}
+
+// MaxFee returns the max fee for a transaction paying tip to the validator, twice the current base fee
+// is allowed so the transaction stays valid while the base fee raises for a few blocks.
+func (w *Wallet) MaxFee(tip uint64) (uint64, error) {
+	req, err := http.NewRequest("GET", "http://localhost:8080/api/fee", nil)
+	if err != nil {
+		return 0, err
+	}
+	client := http.Client{}
+	rsp, err := client.Do(req)
+	if err != nil {
+		return 0, err
+	}
+	defer rsp.Body.Close()
+	fee := struct {
+		BaseFee uint64 `json:"base_fee"`
+	}{}
+	if err := json.NewDecoder(rsp.Body).Decode(&fee); err != nil {
+		return 0, err
+	}
+	return 2*fee.BaseFee + tip, nil
+}
+
 // SendTransactionToNode will send transaction to endpoint with POST http request, transaction should be signed before send over network
 func (w *Wallet) SendTransactionToNode(endpoint string, tx *core.Transaction) error {
+	tx.ChainID = w.chainID
 	if err := tx.Sign(w.privKey); err != nil {
 		return err
 	}
+	if err := postTransaction(endpoint, tx); err != nil {
+		return err
+	}
+	w.nonce += 1
+	w.transactions = append(w.transactions, tx)
+	return nil
+}
+
+func postTransaction(endpoint string, tx *core.Transaction) error {
 	buf := &bytes.Buffer{}
 	if err := tx.Encode(core.NewGobTxEncoder(buf)); err != nil {
 		return err
@@ -71,8 +171,6 @@ func (w *Wallet) SendTransactionToNode(endpoint string, tx *core.Transaction) er
 		return err
 	}
 	if res.StatusCode == http.StatusOK {
-		w.nonce += 1
-		w.transactions = append(w.transactions, tx)
 		return nil
 	}
 	buf.Reset()
@@ -82,7 +180,7 @@ func (w *Wallet) SendTransactionToNode(endpoint string, tx *core.Transaction) er
 	return errors.New(buf.String())
 }
 
-func (w *Wallet) TransferTransaction(to types.Address, amount uint64, fee uint64) error {
+func (w *Wallet) TransferTransaction(to types.Address, amount uint64, tip uint64) error {
 	transferTx := core.TransferTx{
 		From:  w.addr,
 		To:    to,
@@ -92,11 +190,17 @@ func (w *Wallet) TransferTransaction(to types.Address, amount uint64, fee uint64
 		return err
 	}
 
+	maxFee, err := w.MaxFee(tip)
+	if err != nil {
+		return err
+	}
 	tx := &core.Transaction{
 		TxInner:   transferTx,
 		Data:      nil,
-		Fee:       fee,
+		MaxFee:    maxFee,
+		TipCap:    tip,
 		Nonce:     w.nonce,
+		ChainID:   w.chainID,
 		ValidFrom: time.Now().Add(time.Second * 10).UnixNano(),
 	}
 	if err := tx.Sign(w.privKey); err != nil {
@@ -106,11 +210,15 @@ func (w *Wallet) TransferTransaction(to types.Address, amount uint64, fee uint64
 	return w.SendTransactionToNode(NodeEndpoint, tx)
 }
 
-func (w *Wallet) NFTMintTransaction(nftType core.NFTAssetType, data []byte, collectionHash types.Hash, metadata map[string]any, fee uint64) error {
+func (w *Wallet) NFTMintTransaction(nftType core.NFTAssetType, data []byte, collectionHash types.Hash, royalty core.Royalty, metadata map[string]any, tip uint64) error {
 	asset := core.NFTAsset{
 		Type:       nftType,
 		Data:       data,
 		Collection: collectionHash,
+		Royalty:    royalty,
+	}
+	if err := asset.Validate(); err != nil {
+		return err
 	}
 	metaDataBuf := new(bytes.Buffer)
 	if err := gob.NewEncoder(metaDataBuf).Encode(metadata); err != nil {
@@ -124,11 +232,17 @@ func (w *Wallet) NFTMintTransaction(nftType core.NFTAssetType, data []byte, coll
 	if err := mintTx.Sign(w.privKey); err != nil {
 		return err
 	}
+	maxFee, err := w.MaxFee(tip)
+	if err != nil {
+		return err
+	}
 	tx := &core.Transaction{
 		TxInner: mintTx,
 		Data:    nil,
 		Nonce:   w.nonce,
-		Fee:     fee,
+		MaxFee:  maxFee,
+		TipCap:  tip,
+		ChainID: w.chainID,
 	}
 	if err := tx.Sign(w.privKey); err != nil {
 		return err
@@ -136,9 +250,18 @@ func (w *Wallet) NFTMintTransaction(nftType core.NFTAssetType, data []byte, coll
 	return w.SendTransactionToNode(NodeEndpoint, tx)
 }
 
-func (w *Wallet) CollectionMintTransaction(collectionType core.NFTCollectionType, metadata map[string]any, fee uint64) error {
+// CollectionMintTransaction creates a collection owned by the wallet, only the wallet and minters could mint into it.
+// Zero max supply is unlimited, the wallet could update the metadata of the nft of a mutable collection.
+func (w *Wallet) CollectionMintTransaction(collectionType core.NFTCollectionType, maxSupply uint64, minters []types.Address, mutable bool, royalty core.Royalty, metadata map[string]any, tip uint64) error {
 	collection := core.NFTCollection{
-		Type: collectionType,
+		Type:      collectionType,
+		MaxSupply: maxSupply,
+		Minters:   minters,
+		Mutable:   mutable,
+		Royalty:   royalty,
+	}
+	if err := collection.Validate(); err != nil {
+		return err
 	}
 	metaDataBuf := new(bytes.Buffer)
 	if err := gob.NewEncoder(metaDataBuf).Encode(metadata); err != nil {
@@ -152,11 +275,17 @@ func (w *Wallet) CollectionMintTransaction(collectionType core.NFTCollectionType
 	if err := mintTx.Sign(w.privKey); err != nil {
 		return err
 	}
+	maxFee, err := w.MaxFee(tip)
+	if err != nil {
+		return err
+	}
 	tx := &core.Transaction{
 		TxInner: mintTx,
 		Data:    nil,
 		Nonce:   w.nonce,
-		Fee:     fee,
+		MaxFee:  maxFee,
+		TipCap:  tip,
+		ChainID: w.chainID,
 	}
 	if err := tx.Sign(w.privKey); err != nil {
 		return err
@@ -164,12 +293,264 @@ func (w *Wallet) CollectionMintTransaction(collectionType core.NFTCollectionType
 	return w.SendTransactionToNode(NodeEndpoint, tx)
 }
 
-func (w *Wallet) DataTransaction(data []byte, fee uint64) error {
+// NFTTransferTransaction gives the nft owned by the wallet to the address, nft is the hash of its mint transaction
+func (w *Wallet) NFTTransferTransaction(nft types.Hash, to types.Address, tip uint64) error {
+	transferTx := core.NFTTransferTx{NFT: nft, To: to}
+	if err := transferTx.Validate(); err != nil {
+		return err
+	}
+	_, err := w.sendInner(transferTx, tip)
+	return err
+}
+
+// NFTBurnTransaction destroys the nft owned by the wallet
+func (w *Wallet) NFTBurnTransaction(nft types.Hash, tip uint64) error {
+	burnTx := core.NFTBurnTx{NFT: nft}
+	if err := burnTx.Validate(); err != nil {
+		return err
+	}
+	_, err := w.sendInner(burnTx, tip)
+	return err
+}
+
+// NFTMetadataUpdateTransaction replaces the metadata of an nft of a mutable collection owned by the wallet
+func (w *Wallet) NFTMetadataUpdateTransaction(nft types.Hash, metadata map[string]any, tip uint64) error {
+	metaDataBuf := new(bytes.Buffer)
+	if err := gob.NewEncoder(metaDataBuf).Encode(metadata); err != nil {
+		return err
+	}
+	updateTx := core.NFTMetadataUpdateTx{NFT: nft, Metadata: metaDataBuf.Bytes()}
+	if err := updateTx.Validate(); err != nil {
+		return err
+	}
+	_, err := w.sendInner(updateTx, tip)
+	return err
+}
+
+// CollectionFreezeTransaction makes the mutable collection owned by the wallet immutable for good
+func (w *Wallet) CollectionFreezeTransaction(collection types.Hash, tip uint64) error {
+	freezeTx := core.CollectionFreezeTx{Collection: collection}
+	if err := freezeTx.Validate(); err != nil {
+		return err
+	}
+	_, err := w.sendInner(freezeTx, tip)
+	return err
+}
+
+// SignNFTSale signs the sale of the nft owned by the wallet to buyer at price, transfers is the transfer count
+// of the nft shown by the node. The buyer sends the signed sale with NFTSaleTransaction.
+func (w *Wallet) SignNFTSale(nft types.Hash, buyer types.Address, price uint64, transfers uint64) (core.NFTSaleTx, error) {
+	saleTx := core.NFTSaleTx{NFT: nft, Buyer: buyer, Price: price, Transfers: transfers}
+	if err := saleTx.Sign(w.privKey); err != nil {
+		return saleTx, err
+	}
+	return saleTx, saleTx.Verify()
+}
+
+// NFTSaleTransaction buys the nft of the sale signed by its seller, the wallet pays the price and the royalty
+// of the nft is taken from it
+func (w *Wallet) NFTSaleTransaction(saleTx core.NFTSaleTx, tip uint64) error {
+	if err := saleTx.Verify(); err != nil {
+		return err
+	}
+	_, err := w.sendInner(saleTx, tip)
+	return err
+}
+
+// ListNFTTransaction lists the nft owned by the wallet at price, it replaces the previous listing of the nft
+func (w *Wallet) ListNFTTransaction(nft types.Hash, price uint64, tip uint64) error {
+	_, err := w.sendInner(core.NFTListTx{NFT: nft, Price: price}, tip)
+	return err
+}
+
+// CancelListingTransaction removes the listing of the nft made by the wallet
+func (w *Wallet) CancelListingTransaction(nft types.Hash, tip uint64) error {
+	_, err := w.sendInner(core.NFTCancelListingTx{NFT: nft}, tip)
+	return err
+}
+
+// BuyNFTTransaction buys the listed nft, price must be the price of the listing
+func (w *Wallet) BuyNFTTransaction(nft types.Hash, price uint64, tip uint64) error {
+	_, err := w.sendInner(core.NFTBuyTx{NFT: nft, Price: price}, tip)
+	return err
+}
+
+// OfferNFTTransaction offers price for the nft, zero price withdraws the offer of the wallet
+func (w *Wallet) OfferNFTTransaction(nft types.Hash, price uint64, tip uint64) error {
+	_, err := w.sendInner(core.NFTOfferTx{NFT: nft, Price: price}, tip)
+	return err
+}
+
+// AcceptOfferTransaction sells the nft owned by the wallet to the buyer at the price of its offer
+func (w *Wallet) AcceptOfferTransaction(nft types.Hash, buyer types.Address, price uint64, tip uint64) error {
+	_, err := w.sendInner(core.NFTAcceptOfferTx{NFT: nft, Buyer: buyer, Price: price}, tip)
+	return err
+}
+
+// CreateAuctionTransaction puts the nft owned by the wallet on auction until endHeight, the returned hash
+// of the transaction identifies the auction
+func (w *Wallet) CreateAuctionTransaction(nft types.Hash, reservePrice uint64, endHeight uint32, tip uint64) (types.Hash, error) {
+	tx, err := w.sendInner(core.AuctionCreateTx{NFT: nft, ReservePrice: reservePrice, EndHeight: endHeight}, tip)
+	if err != nil {
+		return types.Hash{}, err
+	}
+	return tx.Hash(core.TxHasher{}), nil
+}
+
+// BidAuctionTransaction bids amount on the auction, the amount stays locked until the wallet is outbid or the
+// auction is settled
+func (w *Wallet) BidAuctionTransaction(auction types.Hash, amount uint64, tip uint64) error {
+	_, err := w.sendInner(core.AuctionBidTx{Auction: auction, Amount: amount}, tip)
+	return err
+}
+
+// SettleAuctionTransaction settles the ended auction
+func (w *Wallet) SettleAuctionTransaction(auction types.Hash, tip uint64) error {
+	_, err := w.sendInner(core.AuctionSettleTx{Auction: auction}, tip)
+	return err
+}
+
+// CreateTokenTransaction creates a token with the initial supply credited to the wallet, the returned hash
+// of the transaction identifies the token. Zero mint authority makes the supply fixed.
+func (w *Wallet) CreateTokenTransaction(name, symbol string, decimals uint8, supply uint64, mintAuthority types.Address, tip uint64) (types.Hash, error) {
+	createTx := core.TokenCreateTx{
+		Name:          name,
+		Symbol:        symbol,
+		Decimals:      decimals,
+		Supply:        supply,
+		MintAuthority: mintAuthority,
+	}
+	if err := createTx.Validate(); err != nil {
+		return types.Hash{}, err
+	}
+	tx, err := w.sendInner(createTx, tip)
+	if err != nil {
+		return types.Hash{}, err
+	}
+	return tx.Hash(core.TxHasher{}), nil
+}
+
+func (w *Wallet) TokenTransferTransaction(token types.Hash, to types.Address, amount uint64, tip uint64) error {
+	_, err := w.sendInner(core.TokenTransferTx{Token: token, To: to, Amount: amount}, tip)
+	return err
+}
+
+func (w *Wallet) TokenMintTransaction(token types.Hash, to types.Address, amount uint64, tip uint64) error {
+	_, err := w.sendInner(core.TokenMintTx{Token: token, To: to, Amount: amount}, tip)
+	return err
+}
+
+func (w *Wallet) TokenBurnTransaction(token types.Hash, amount uint64, tip uint64) error {
+	_, err := w.sendInner(core.TokenBurnTx{Token: token, Amount: amount}, tip)
+	return err
+}
+
+// sendInner wraps the inner transaction into a transaction of the wallet and sends it
+func (w *Wallet) sendInner(inner any, tip uint64) (*core.Transaction, error) {
+	maxFee, err := w.MaxFee(tip)
+	if err != nil {
+		return nil, err
+	}
+	tx := &core.Transaction{
+		TxInner: inner,
+		Nonce:   w.nonce,
+		MaxFee:  maxFee,
+		TipCap:  tip,
+		ChainID: w.chainID,
+	}
+	return tx, w.SendTransactionToNode(NodeEndpoint, tx)
+}
+
+// BatchTransferTransaction sends every output in one transaction paying a single fee, either all or none are applied
+func (w *Wallet) BatchTransferTransaction(outputs []core.TransferOutput, tip uint64) error {
+	batchTx := core.BatchTransferTx{Outputs: outputs}
+	if err := batchTx.Validate(); err != nil {
+		return err
+	}
+	maxFee, err := w.MaxFee(tip)
+	if err != nil {
+		return err
+	}
+	tx := core.NewBatchTransferTransaction(outputs, w.nonce)
+	tx.MaxFee = maxFee
+	tx.TipCap = tip
+	tx.ChainID = w.chainID
+	return w.SendTransactionToNode(NodeEndpoint, tx)
+}
+
+// VestingTransferTransaction sends amount to the address locked until unlockHeight and unlockTime,
+// with vestingBlocks the amount is released linearly over that many blocks after unlock.
+func (w *Wallet) VestingTransferTransaction(to types.Address, amount uint64, unlockHeight uint32, unlockTime time.Time, vestingBlocks uint32, tip uint64) error {
+	vestingTx := core.VestingTransferTx{
+		To:            to,
+		Value:         amount,
+		UnlockHeight:  unlockHeight,
+		VestingBlocks: vestingBlocks,
+	}
+	if !unlockTime.IsZero() {
+		vestingTx.UnlockTime = unlockTime.UnixNano()
+	}
+	if err := vestingTx.Validate(); err != nil {
+		return err
+	}
+	maxFee, err := w.MaxFee(tip)
+	if err != nil {
+		return err
+	}
+	tx := core.NewVestingTransferTransaction(vestingTx, w.nonce)
+	tx.MaxFee = maxFee
+	tx.TipCap = tip
+	tx.ChainID = w.chainID
+	return w.SendTransactionToNode(NodeEndpoint, tx)
+}
+
+// ClaimVestingTransaction unlocks what has vested so far from the given vestings, every vesting of the wallet when none is given
+func (w *Wallet) ClaimVestingTransaction(tip uint64, vestings ...types.Hash) error {
+	maxFee, err := w.MaxFee(tip)
+	if err != nil {
+		return err
+	}
+	tx := core.NewVestingClaimTransaction(w.nonce, vestings...)
+	tx.MaxFee = maxFee
+	tx.TipCap = tip
+	tx.ChainID = w.chainID
+	return w.SendTransactionToNode(NodeEndpoint, tx)
+}
+
+// LockHTLCTransaction locks amount for the address under hashlock until timeoutHeight, see core.Hashlock.
+// The returned hash of the transaction identifies the htlc.
+func (w *Wallet) LockHTLCTransaction(to types.Address, amount uint64, hashlock types.Hash, timeoutHeight uint32, tip uint64) (types.Hash, error) {
+	tx, err := w.sendInner(core.HTLCLockTx{To: to, Value: amount, Hashlock: hashlock, TimeoutHeight: timeoutHeight}, tip)
+	if err != nil {
+		return types.Hash{}, err
+	}
+	return tx.Hash(core.TxHasher{}), nil
+}
+
+// ClaimHTLCTransaction reveals the preimage of the htlc hashlock to pay it to its recipient
+func (w *Wallet) ClaimHTLCTransaction(htlc types.Hash, preimage []byte, tip uint64) error {
+	_, err := w.sendInner(core.HTLCClaimTx{HTLC: htlc, Preimage: preimage}, tip)
+	return err
+}
+
+// RefundHTLCTransaction returns the timed out htlc to its sender
+func (w *Wallet) RefundHTLCTransaction(htlc types.Hash, tip uint64) error {
+	_, err := w.sendInner(core.HTLCRefundTx{HTLC: htlc}, tip)
+	return err
+}
+
+func (w *Wallet) DataTransaction(data []byte, tip uint64) error {
+	maxFee, err := w.MaxFee(tip)
+	if err != nil {
+		return err
+	}
 	tx := &core.Transaction{
 		TxInner: nil,
 		Data:    data,
 		Nonce:   w.nonce,
-		Fee:     fee,
+		MaxFee:  maxFee,
+		TipCap:  tip,
+		ChainID: w.chainID,
 	}
 	if err := tx.Sign(w.privKey); err != nil {
 		return err
@@ -177,6 +558,65 @@ func (w *Wallet) DataTransaction(data []byte, fee uint64) error {
 	return w.SendTransactionToNode(NodeEndpoint, tx)
 }
 
+// CreateMultisigAccount registers the multisig account of signers on the chain, the wallet pays the fee.
+// The address of the account is returned, funds could be sent to it before it is registered.
+func (w *Wallet) CreateMultisigAccount(threshold uint32, signers []*crypto.PublicKey, tip uint64) (types.Address, error) {
+	account := core.MultisigAccount{Threshold: threshold, Signers: signers}
+	if err := account.Validate(); err != nil {
+		return types.Address{}, err
+	}
+	maxFee, err := w.MaxFee(tip)
+	if err != nil {
+		return types.Address{}, err
+	}
+	tx := core.NewMultisigCreateTransaction(account, w.nonce)
+	tx.MaxFee = maxFee
+	tx.TipCap = tip
+	tx.ChainID = w.chainID
+	return account.Address(), w.SendTransactionToNode(NodeEndpoint, tx)
+}
+
+// MultisigTransferTransaction creates a transfer from the multisig account, it is signed by the wallet
+// and should be passed to the other co-signers with CoSignTransaction before it is sent with SendMultisigTransaction.
+func (w *Wallet) MultisigTransferTransaction(account core.MultisigAccount, to types.Address, amount uint64, tip uint64) (*core.Transaction, error) {
+	nonce, err := fetchNextNonce(account.Address())
+	if err != nil {
+		return nil, err
+	}
+	maxFee, err := w.MaxFee(tip)
+	if err != nil {
+		return nil, err
+	}
+	transferTx := core.TransferTx{
+		From:  account.Address(),
+		To:    to,
+		Value: amount,
+	}
+	tx := core.NewMultisigTransaction(account, transferTx, nonce)
+	tx.MaxFee = maxFee
+	tx.TipCap = tip
+	tx.ChainID = w.chainID
+	return tx, w.CoSignTransaction(tx)
+}
+
+// CoSignTransaction adds the signature of the wallet to the multisig transaction, the transaction
+// must not be changed after the first co-signer signed it.
+func (w *Wallet) CoSignTransaction(tx *core.Transaction) error {
+	return tx.SignMultisig(w.privKey)
+}
+
+// SendMultisigTransaction sends the multisig transaction as signed by its co-signers
+func (w *Wallet) SendMultisigTransaction(tx *core.Transaction) error {
+	if err := tx.Verify(); err != nil {
+		return err
+	}
+	if err := postTransaction(NodeEndpoint, tx); err != nil {
+		return err
+	}
+	w.transactions = append(w.transactions, tx)
+	return nil
+}
+
 func (w *Wallet) GetUserTransaction() []*core.Transaction {
 	return w.transactions
 }
@@ -184,6 +624,7 @@ func (w *Wallet) GetUserTransaction() []*core.Transaction {
 type UserState struct {
 	Addr    string `json:"addr"`
 	Balance uint64 `json:"balance"`
+	Locked  uint64 `json:"locked"`
 	Nonce   uint64 `json:"nonce"`
 }
 